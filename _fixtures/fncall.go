@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
 )
@@ -121,6 +123,50 @@ func noreturncall(n int) {
 	return
 }
 
+func variadicSum(v ...int) int {
+	r := 0
+	for _, x := range v {
+		r += x
+	}
+	return r
+}
+
+func variadicJoin(sep string, v ...string) string {
+	return strings.Join(v, sep)
+}
+
+func sumAStructs(v []astruct) int {
+	r := 0
+	for _, a := range v {
+		r += a.X
+	}
+	return r
+}
+
+func variadicApply(f func(int) int, v ...int) int {
+	r := 0
+	for _, x := range v {
+		r += f(x)
+	}
+	return r
+}
+
+func sumMap(m map[string]int) int {
+	r := 0
+	for _, x := range m {
+		r += x
+	}
+	return r
+}
+
+func applyInt(f func(int) int, x int) int {
+	return f(x)
+}
+
+func applyString(f func(int) string, x int) string {
+	return f(x)
+}
+
 type Base struct {
 	y int
 }
@@ -151,11 +197,19 @@ func main() {
 	intslice := []int{1, 2, 3}
 	stringslice := []string{"one", "two", "three"}
 	comma := ","
+	strintmap := map[string]int{"one": 1}
 	a := astruct{X: 3}
 	pa := &astruct{X: 6}
 	a2 := a2struct{Y: 7}
 	var pa2 *astruct
 	var str string = "old string value"
+	var anyval interface{}
+	var vable VRcvrable
+	var acopy astruct
+	var err error = errors.New("some error")
+	var nilerr error
+	var rdr io.Reader = strings.NewReader("hello")
+	buf := make([]byte, 5)
 
 	var vable_a VRcvrable = a
 	var vable_pa VRcvrable = pa
@@ -178,5 +232,5 @@ func main() {
 	d.Method()
 	d.Base.Method()
 	x.CallMe()
-	fmt.Println(one, two, zero, callpanic, callstacktrace, stringsJoin, intslice, stringslice, comma, a.VRcvr, a.PRcvr, pa, vable_a, vable_pa, pable_pa, fn2clos, fn2glob, fn2valmeth, fn2ptrmeth, fn2nil, ga, escapeArg, a2, square, intcallpanic, onetwothree, curriedAdd, getAStruct, getAStructPtr, getVRcvrableFromAStruct, getPRcvrableFromAStructPtr, getVRcvrableFromAStructPtr, pa2, noreturncall, str, d, x, x2.CallMe(5))
+	fmt.Println(one, two, zero, callpanic, callstacktrace, stringsJoin, intslice, stringslice, comma, a.VRcvr, a.PRcvr, pa, vable_a, vable_pa, pable_pa, fn2clos, fn2glob, fn2valmeth, fn2ptrmeth, fn2nil, ga, escapeArg, a2, square, intcallpanic, onetwothree, curriedAdd, getAStruct, getAStructPtr, getVRcvrableFromAStruct, getPRcvrableFromAStructPtr, getVRcvrableFromAStructPtr, pa2, noreturncall, str, d, x, x2.CallMe(5), variadicSum, variadicJoin, variadicApply, sumAStructs, sumMap, applyInt, applyString, strintmap, anyval, vable, acopy, err, nilerr, rdr, buf)
 }
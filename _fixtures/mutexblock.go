@@ -0,0 +1,25 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+func main() {
+	var mu sync.Mutex
+	mu.Lock()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			wg.Done()
+			mu.Lock()
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	time.Sleep(200 * time.Millisecond) // give the goroutines time to park on mu
+	runtime.Breakpoint()
+	mu.Unlock()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type astruct struct {
+	A, B int
+}
+
+func main() {
+	tm := time.Date(2020, time.September, 13, 12, 26, 40, 500, time.UTC)
+	tmfixed := time.Date(2020, time.September, 13, 14, 26, 40, 0, time.FixedZone("CEST", 2*60*60))
+	d := 1500 * time.Millisecond
+	ip4 := net.ParseIP("192.168.1.1").To4()
+	ip6 := net.ParseIP("2001:db8::1")
+	bi, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	bf := big.NewFloat(1.5)
+	var mu sync.Mutex
+	var lockedmu sync.Mutex
+	lockedmu.Lock()
+	var av atomic.Value
+	av.Store(astruct{1, 2})
+	var avnil atomic.Value
+	rv := reflect.ValueOf(astruct{3, 4})
+	rvint := reflect.ValueOf(5)
+	rvptr := reflect.ValueOf(&astruct{5, 6})
+	rvrv := reflect.ValueOf(reflect.ValueOf(7))
+	var rvzero reflect.Value
+	runtime.Breakpoint()
+	fmt.Println(tm, tmfixed, d, ip4, ip6, bi, bf, &mu, &lockedmu, &av, &avnil, rv, rvint, rvptr, rvrv, rvzero)
+}
@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+var n = 1
+
+func inner(a int) int {
+	a++
+	return a * 2
+}
+
+func outer(a int) int {
+	b := inner(a)
+	c := inner(b)
+	return b + c
+}
+
+func main() {
+	x := outer(n)
+	y := outer(x)
+	fmt.Println(x, y)
+}
@@ -229,6 +229,7 @@ func genDocs(bindings []binding) []byte {
 	fmt.Fprintf(&buf, "write_file(path, contents) | Writes string to a file\n")
 	fmt.Fprintf(&buf, "cur_scope() | Returns the current evaluation scope\n")
 	fmt.Fprintf(&buf, "default_load_config() | Returns the current default load configuration\n")
+	fmt.Fprintf(&buf, "add_pretty_printer(type, fn, regex=False) | Registers fn as the [pretty printer](#pretty-printers) of type\n")
 
 	return buf.Bytes()
 }
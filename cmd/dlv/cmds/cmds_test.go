@@ -2,6 +2,8 @@ package cmds
 
 import (
 	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
 )
 
 func TestParseRedirects(t *testing.T) {
@@ -57,3 +59,38 @@ func TestParseRedirects(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterDwarfProblems(t *testing.T) {
+	problems := []proc.DwarfProblem{
+		{Kind: proc.DwarfOptimizedOut, Severity: proc.DwarfWarning, Package: "main"},
+		{Kind: proc.DwarfBadType, Severity: proc.DwarfError, Package: "main"},
+		{Kind: proc.DwarfBadLocation, Severity: proc.DwarfError, Package: "example.com/a"},
+		{Kind: proc.DwarfBadLocation, Severity: proc.DwarfError, Package: "example.com/a/b"},
+		{Kind: proc.DwarfBadLocation, Severity: proc.DwarfError, Package: "example.com/ab"},
+	}
+
+	testCases := []struct {
+		severity proc.DwarfProblemSeverity
+		packages []string
+		tgt      []int
+	}{
+		{proc.DwarfWarning, nil, []int{0, 1, 2, 3, 4}},
+		{proc.DwarfError, nil, []int{1, 2, 3, 4}},
+		{proc.DwarfWarning, []string{"main"}, []int{0, 1}},
+		{proc.DwarfError, []string{"main"}, []int{1}},
+		{proc.DwarfWarning, []string{"example.com/a"}, []int{2}},
+		{proc.DwarfWarning, []string{"example.com/a/..."}, []int{2, 3}},
+		{proc.DwarfWarning, []string{"main", "example.com/ab"}, []int{0, 1, 4}},
+	}
+
+	for _, tc := range testCases {
+		out := filterDwarfProblems(append([]proc.DwarfProblem(nil), problems...), tc.severity, tc.packages)
+		ok := len(out) == len(tc.tgt)
+		for i := 0; ok && i < len(out); i++ {
+			ok = out[i] == problems[tc.tgt[i]]
+		}
+		if !ok {
+			t.Errorf("%s %v: got %v expected problems %v", tc.severity, tc.packages, out, tc.tgt)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"os/exec"
@@ -13,11 +14,13 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-delve/delve/pkg/config"
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/terminal"
 	"github.com/go-delve/delve/pkg/version"
 	"github.com/go-delve/delve/service"
@@ -49,6 +52,9 @@ var (
 	addr string
 	// initFile is the path to initialization file.
 	initFile string
+	// inferSubstitutePath is true if the terminal client should propose
+	// substitute-path rules for the source files it can not find.
+	inferSubstitutePath bool
 	// buildFlags is the flags passed during compiler invocation.
 	buildFlags string
 	// workingDir is the working directory for running the program.
@@ -75,11 +81,40 @@ var (
 	traceTestBinary bool
 	traceStackDepth int
 
+	profileDuration time.Duration
+	profileRate     int
+	profileOut      string
+
+	// coreSysroot is the directory where the shared objects mapped in a
+	// core file are looked up.
+	coreSysroot string
+
+	// inspectOnly is true if the executable is opened for static inspection,
+	// without running it.
+	inspectOnly bool
+
+	// symbolizeRewrite is true if the symbolize command should rewrite the
+	// pc=0x... tokens of its input instead of reading a list of addresses.
+	symbolizeRewrite bool
+
+	// dwarfcheckJSON is true if the dwarfcheck command should print its
+	// report as JSON.
+	dwarfcheckJSON bool
+	// dwarfcheckSeverity is the minimum severity of the problems reported
+	// by the dwarfcheck command.
+	dwarfcheckSeverity string
+	// dwarfcheckPackages restricts the problems reported by the dwarfcheck
+	// command to the listed packages.
+	dwarfcheckPackages []string
+
 	// redirect specifications for target process
 	redirects []string
 
 	allowNonTerminalInteractive bool
 
+	// noCache disables the on-disk cache of debug information.
+	noCache bool
+
 	conf *config.Config
 )
 
@@ -124,12 +159,14 @@ func New(docCall bool) *cobra.Command {
 	rootCommand.PersistentFlags().BoolVarP(&acceptMulti, "accept-multiclient", "", false, "Allows a headless server to accept multiple client connections.")
 	rootCommand.PersistentFlags().IntVar(&apiVersion, "api-version", 1, "Selects API version when headless. New clients should use v2. Can be reset via RPCServer.SetApiVersion. See Documentation/api/json-rpc/README.md.")
 	rootCommand.PersistentFlags().StringVar(&initFile, "init", "", "Init file, executed by the terminal client.")
+	rootCommand.PersistentFlags().BoolVar(&inferSubstitutePath, "infer-substitute-path", false, "Proposes substitute-path rules for source files that can not be found locally, using the local module root, GOROOT and module cache. The rules are added with 'config substitute-path -infer'.")
 	rootCommand.PersistentFlags().StringVar(&buildFlags, "build-flags", buildFlagsDefault, "Build flags, to be passed to the compiler.")
 	rootCommand.PersistentFlags().StringVar(&workingDir, "wd", "", "Working directory for running the program.")
 	rootCommand.PersistentFlags().BoolVarP(&checkGoVersion, "check-go-version", "", true, "Checks that the version of Go in use is compatible with Delve.")
 	rootCommand.PersistentFlags().BoolVarP(&checkLocalConnUser, "only-same-user", "", true, "Only connections from the same user that started this instance of Delve are allowed to connect.")
 	rootCommand.PersistentFlags().StringVar(&backend, "backend", "default", `Backend selection (see 'dlv help backend').`)
 	rootCommand.PersistentFlags().StringArrayVarP(&redirects, "redirect", "r", []string{}, "Specifies redirect rules for target process (see 'dlv help redirect')")
+	rootCommand.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Disables the on-disk cache of debug information enabled by the bininfo-cache configuration option.")
 	rootCommand.PersistentFlags().BoolVar(&allowNonTerminalInteractive, "allow-non-terminal-interactive", false, "Allows interactive sessions of Delve that don't have a terminal as stdin, stdout and stderr")
 
 	// 'attach' subcommand.
@@ -274,6 +311,36 @@ only see the output of the trace operations you can redirect stdout.`,
 	traceCommand.Flags().String("output", "debug", "Output path for the binary.")
 	rootCommand.AddCommand(traceCommand)
 
+	// 'profile' subcommand.
+	profileCommand := &cobra.Command{
+		Use:   "profile pid [executable]",
+		Short: "Collect a CPU profile of a running process.",
+		Long: `Collect a CPU profile of a running process.
+
+The profile sub command attaches to the specified process and samples it by
+periodically stopping it, recording the stack of every thread that is
+executing a goroutine, and resuming it. When the specified duration has
+elapsed Delve detaches from the process and writes the samples as a pprof
+CPU profile that can be read with 'go tool pprof'. Each sample is labeled
+with the pprof labels of the goroutine it was collected from.
+
+This is useful for programs that can not be rebuilt to include
+net/http/pprof. Stopping the process is expensive, the number of samples
+collected and the amount of time the process spent stopped are reported
+once profiling terminates.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("you must provide a PID")
+			}
+			return nil
+		},
+		Run: profileCmd,
+	}
+	profileCommand.Flags().DurationVar(&profileDuration, "duration", 30*time.Second, "Duration of the profile.")
+	profileCommand.Flags().IntVar(&profileRate, "rate", 100, "Number of samples per second.")
+	profileCommand.Flags().StringVarP(&profileOut, "out", "o", "cpu.pb.gz", "Output path for the profile.")
+	rootCommand.AddCommand(profileCommand)
+
 	coreCommand := &cobra.Command{
 		Use:   "core <executable> <core>",
 		Short: "Examine a core dump.",
@@ -283,7 +350,14 @@ The core command will open the specified core file and the associated
 executable and let you examine the state of the process when the
 core dump was taken.
 
-Currently supports linux/amd64 and linux/arm64 core files and windows/amd64 minidumps.`,
+Currently supports linux/amd64 and linux/arm64 core files and windows/amd64 minidumps.
+
+Shared libraries mapped by the process are loaded from the paths recorded
+in the core file, prefixed by the directory specified with --sysroot if
+any. Additional directories, searched by library file name, can be
+specified with the solib-search-path configuration option. If the core
+file contains the build-id of a library, files with a different build-id
+are ignored.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 2 {
 				return errors.New("you must provide a core file and an executable")
@@ -292,8 +366,90 @@ Currently supports linux/amd64 and linux/arm64 core files and windows/amd64 mini
 		},
 		Run: coreCmd,
 	}
+	coreCommand.Flags().StringVar(&coreSysroot, "sysroot", "", "Directory containing a copy of the root file system of the machine that produced the core file, used to find shared libraries.")
 	rootCommand.AddCommand(coreCommand)
 
+	inspectCommand := &cobra.Command{
+		Use:   "inspect <executable>",
+		Short: "Examine an executable without running it.",
+		Long: `Examine an executable without running it.
+
+The inspect command opens the specified executable, without starting a
+process, to examine its debug information: the commands that only need the
+executable image, like funcs, types, sources, list, disassemble and whatis,
+are available, breakpoints are resolved to their addresses but never
+actually set, and commands that need a running program return an error.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("you must provide an executable")
+			}
+			return nil
+		},
+		Run: inspectCmd,
+	}
+	rootCommand.AddCommand(inspectCommand)
+
+	symbolizeCommand := &cobra.Command{
+		Use:   "symbolize <executable> [address...]",
+		Short: "Converts addresses into function names and source lines.",
+		Long: `Converts addresses into function names and source lines.
+
+For each of the specified addresses, or each address read from standard
+input if none is specified, the symbolize command prints the function and
+source line it belongs to, and the chain of functions it was inlined into.
+Addresses are hexadecimal numbers, with or without the 0x prefix.
+
+With --rewrite standard input is copied to standard output annotating each
+'pc=0x...' token with its function and source line, so that the logs of a
+stripped executable can be read using its unstripped copy.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("you must provide an executable")
+			}
+			if symbolizeRewrite && len(args) > 1 {
+				return errors.New("addresses can not be specified with --rewrite")
+			}
+			return nil
+		},
+		Run: symbolizeCmd,
+	}
+	symbolizeCommand.Flags().BoolVar(&symbolizeRewrite, "rewrite", false, "Annotate the pc=0x... tokens read from standard input.")
+	rootCommand.AddCommand(symbolizeCommand)
+
+	// 'dwarfcheck' subcommand.
+	dwarfcheckCommand := &cobra.Command{
+		Use:   "dwarfcheck <executable>",
+		Short: "Reports problems with the debug information of an executable.",
+		Long: `Reports problems with the debug information of an executable.
+
+Walks all compile units, functions and variables of the executable and
+reports: compile units without a producer, functions without frame
+description entries, ranges of addresses inside functions without line
+information, variables that were optimized away, variables with invalid
+locations and variables whose type can not be read.
+
+Each problem is either a warning, for problems that are expected in
+optimized code (compile units without a producer, gaps in the line table
+and optimized away variables), or an error. Use --severity=error to only
+report errors and --package to only report problems in the specified
+packages (the flag can be repeated, a path ending in /... also matches
+its subpackages).
+
+The exit status is 1 if any error is reported. With --json the report is
+printed as a JSON array, one object per problem.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("you must provide an executable")
+			}
+			return nil
+		},
+		Run: dwarfcheckCmd,
+	}
+	dwarfcheckCommand.Flags().BoolVar(&dwarfcheckJSON, "json", false, "Print the report as JSON.")
+	dwarfcheckCommand.Flags().StringVar(&dwarfcheckSeverity, "severity", string(proc.DwarfWarning), "Minimum severity of the reported problems, warning or error.")
+	dwarfcheckCommand.Flags().StringArrayVar(&dwarfcheckPackages, "package", []string{}, "Only report problems in this package.")
+	rootCommand.AddCommand(dwarfcheckCommand)
+
 	// 'version' subcommand.
 	versionCommand := &cobra.Command{
 		Use:   "version",
@@ -386,6 +542,22 @@ File redirects can also be changed using the 'restart' command.
 `,
 	})
 
+	rootCommand.AddCommand(&cobra.Command{
+		Use:   "debuginfod",
+		Short: "Help about downloading debug information.",
+		Long: `When the debug information of the executable or of a shared library can not be found locally, Delve can download it from debuginfod servers, using the build-id of the file.
+
+The following environment variables are used:
+
+	DEBUGINFOD_URLS		Space separated list of server URLs
+	DEBUGINFOD_CACHE_PATH	Directory where downloaded files are saved (default $XDG_CACHE_HOME/debuginfod_client)
+	DEBUGINFOD_TIMEOUT	Timeout of each request in seconds (default 90)
+	DELVE_DEBUGINFOD_OFFLINE	If set, files are only looked up in the cache
+
+Source files that can not be found locally are also downloaded from the servers when they are listed.
+`,
+	})
+
 	rootCommand.DisableAutoGenTag = true
 
 	return rootCommand
@@ -408,6 +580,10 @@ func dapCmd(cmd *cobra.Command, args []string) {
 		if initFile != "" {
 			fmt.Fprint(os.Stderr, "Warning: init file ignored with dap\n")
 		}
+		if inferSubstitutePath {
+			fmt.Fprint(os.Stderr, "Error: --infer-substitute-path is not supported with dap\n")
+			return 1
+		}
 		if continueOnStart {
 			fmt.Fprintf(os.Stderr, "Warning: continue ignored with dap; specify via launch/attach request instead\n")
 		}
@@ -432,12 +608,14 @@ func dapCmd(cmd *cobra.Command, args []string) {
 		}
 		disconnectChan := make(chan struct{})
 		server := dap.NewServer(&service.Config{
-			Listener:       listener,
-			DisconnectChan: disconnectChan,
+			Listener:                 listener,
+			DisconnectChan:           disconnectChan,
+			DisableBuiltinFormatters: conf.DisableBuiltinFormatters,
 			Debugger: debugger.Config{
 				Backend:              backend,
 				Foreground:           headless && tty == "",
 				DebugInfoDirectories: conf.DebugInfoDirectories,
+				BinaryInfoCache:      binaryInfoCacheConfig(conf),
 				CheckGoVersion:       checkGoVersion,
 				TTY:                  tty,
 			},
@@ -601,6 +779,73 @@ func traceCmd(cmd *cobra.Command, args []string) {
 	os.Exit(status)
 }
 
+func profileCmd(cmd *cobra.Command, args []string) {
+	status := func() int {
+		err := logflags.Setup(log, logOutput, logDest)
+		defer logflags.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+
+		pid, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid pid: %s\n", args[0])
+			return 1
+		}
+
+		// Make a local in-memory connection that client and server use to communicate
+		listener, clientConn := service.ListenerPipe()
+		defer listener.Close()
+
+		server := rpccommon.NewServer(&service.Config{
+			Listener:    listener,
+			ProcessArgs: args[1:],
+			APIVersion:  2,
+			Debugger: debugger.Config{
+				AttachPid:            pid,
+				Backend:              backend,
+				DebugInfoDirectories: conf.DebugInfoDirectories,
+				BinaryInfoCache:      binaryInfoCacheConfig(conf),
+				CheckGoVersion:       checkGoVersion,
+			},
+		})
+		if err := server.Run(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		client := rpc2.NewClientFromConn(clientConn)
+
+		out, err := client.CPUProfile(profileDuration, profileRate)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			client.Detach(false)
+			return 1
+		}
+		if err := client.Detach(false); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		if err := ioutil.WriteFile(profileOut, out.Profile, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		fmt.Printf("CPU profile written to %s\n", profileOut)
+		fmt.Printf("%d samples collected in %d stops over %v", out.Samples, out.Stops, out.Elapsed)
+		if out.Elapsed > 0 {
+			fmt.Printf(" (%.1f stops/s)", float64(out.Stops)/out.Elapsed.Seconds())
+		}
+		fmt.Printf("\nprocess stopped for %v", out.Stopped)
+		if out.Elapsed > 0 {
+			fmt.Printf(" (%.1f%% of the time)", 100*out.Stopped.Seconds()/out.Elapsed.Seconds())
+		}
+		fmt.Println()
+		return 0
+	}()
+	os.Exit(status)
+}
+
 func isBreakpointExistsErr(err error) bool {
 	return strings.Contains(err.Error(), "Breakpoint exists")
 }
@@ -664,6 +909,11 @@ func coreCmd(cmd *cobra.Command, args []string) {
 	os.Exit(execute(0, []string{args[0]}, conf, args[1], debugger.ExecutingOther, args, buildFlags))
 }
 
+func inspectCmd(cmd *cobra.Command, args []string) {
+	inspectOnly = true
+	os.Exit(execute(0, []string{args[0]}, conf, "", debugger.ExecutingOther, args, buildFlags))
+}
+
 func connectCmd(cmd *cobra.Command, args []string) {
 	addr := args[0]
 	if addr == "" {
@@ -735,6 +985,7 @@ func connect(addr string, clientConn net.Conn, conf *config.Config, kind debugge
 	}
 	term := terminal.New(client, conf)
 	term.InitFile = initFile
+	term.InferSubstitutePath = inferSubstitutePath
 	status, err := term.Run()
 	if err != nil {
 		fmt.Println(err)
@@ -752,6 +1003,10 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 	if headless && (initFile != "") {
 		fmt.Fprint(os.Stderr, "Warning: init file ignored with --headless\n")
 	}
+	if headless && inferSubstitutePath {
+		fmt.Fprint(os.Stderr, "Error: --infer-substitute-path only works with the terminal client, not with --headless\n")
+		return 1
+	}
 	if continueOnStart {
 		if !headless {
 			fmt.Fprint(os.Stderr, "Error: --continue only works with --headless; use an init file\n")
@@ -834,14 +1089,18 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 				WorkingDir:           workingDir,
 				Backend:              backend,
 				CoreFile:             coreFile,
+				Inspect:              inspectOnly,
 				Foreground:           headless && tty == "",
 				Packages:             dlvArgs,
 				BuildFlags:           buildFlags,
 				ExecuteKind:          kind,
 				DebugInfoDirectories: conf.DebugInfoDirectories,
+				BinaryInfoCache:      binaryInfoCacheConfig(conf),
 				CheckGoVersion:       checkGoVersion,
 				TTY:                  tty,
 				Redirects:            redirects,
+				Sysroot:              coreSysroot,
+				SolibSearchPath:      conf.SolibSearchPath,
 			},
 		})
 	default:
@@ -904,3 +1163,18 @@ func parseRedirects(redirects []string) ([3]string, error) {
 	}
 	return r, nil
 }
+
+// binaryInfoCacheConfig returns the configuration of the on-disk cache of
+// debug information, the cache is enabled if it is enabled in the
+// configuration file and wasn't disabled with --no-cache.
+func binaryInfoCacheConfig(conf *config.Config) proc.BinaryInfoCacheConfig {
+	if noCache || !conf.BinaryInfoCache {
+		return proc.BinaryInfoCacheConfig{}
+	}
+	dir, err := proc.DefaultBinaryInfoCacheDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: debug information cache disabled: %v\n", err)
+		return proc.BinaryInfoCacheConfig{}
+	}
+	return proc.BinaryInfoCacheConfig{Dir: dir, MaxSize: int64(conf.GetBinaryInfoCacheSize()) << 20}
+}
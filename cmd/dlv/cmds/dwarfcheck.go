@@ -0,0 +1,90 @@
+package cmds
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/core"
+	"github.com/spf13/cobra"
+)
+
+func dwarfcheckCmd(cmd *cobra.Command, args []string) {
+	os.Exit(dwarfcheck(args[0], os.Stdout))
+}
+
+func dwarfcheck(exePath string, out io.Writer) int {
+	if dwarfcheckSeverity != string(proc.DwarfWarning) && dwarfcheckSeverity != string(proc.DwarfError) {
+		fmt.Fprintf(os.Stderr, "invalid severity %q, must be %s or %s\n", dwarfcheckSeverity, proc.DwarfWarning, proc.DwarfError)
+		return 1
+	}
+
+	t, err := core.OpenExecutable(exePath, conf.DebugInfoDirectories, binaryInfoCacheConfig(conf))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not open %s: %v\n", exePath, err)
+		return 1
+	}
+	defer t.Detach(false)
+
+	problems := filterDwarfProblems(t.BinInfo().CheckDwarf(), proc.DwarfProblemSeverity(dwarfcheckSeverity), dwarfcheckPackages)
+
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	if dwarfcheckJSON {
+		if problems == nil {
+			problems = []proc.DwarfProblem{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(problems); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else {
+		for i := range problems {
+			fmt.Fprintln(w, problems[i].String())
+		}
+	}
+
+	for i := range problems {
+		if problems[i].Severity == proc.DwarfError {
+			return 1
+		}
+	}
+	return 0
+}
+
+// filterDwarfProblems returns the problems with at least the specified
+// severity that belong to one of packages, or to any package if packages
+// is empty.
+func filterDwarfProblems(problems []proc.DwarfProblem, severity proc.DwarfProblemSeverity, packages []string) []proc.DwarfProblem {
+	r := problems[:0]
+	for _, problem := range problems {
+		if severity == proc.DwarfError && problem.Severity != proc.DwarfError {
+			continue
+		}
+		if len(packages) > 0 && !matchesPackage(problem.Package, packages) {
+			continue
+		}
+		r = append(r, problem)
+	}
+	return r
+}
+
+func matchesPackage(pkg string, packages []string) bool {
+	for _, pattern := range packages {
+		if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+			if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+				return true
+			}
+		} else if pkg == pattern {
+			return true
+		}
+	}
+	return false
+}
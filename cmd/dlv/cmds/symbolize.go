@@ -0,0 +1,129 @@
+package cmds
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/core"
+	"github.com/spf13/cobra"
+)
+
+var symbolizePCRegex = regexp.MustCompile(`\bpc=0x([0-9a-fA-F]+)`)
+
+func symbolizeCmd(cmd *cobra.Command, args []string) {
+	os.Exit(symbolize(args[0], args[1:], os.Stdin, os.Stdout))
+}
+
+func symbolize(exePath string, addrs []string, in io.Reader, out io.Writer) int {
+	t, err := core.OpenExecutable(exePath, conf.DebugInfoDirectories, binaryInfoCacheConfig(conf))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not open %s: %v\n", exePath, err)
+		return 1
+	}
+	defer t.Detach(false)
+	bi := t.BinInfo()
+
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	if symbolizeRewrite {
+		if err := symbolizeStream(w, in, bi); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	status := 0
+	symbolizeAddr := func(s string) {
+		pc, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid address %q\n", s)
+			status = 1
+			return
+		}
+		printInlineStack(w, pc, bi.PCToInlineStack(pc))
+	}
+
+	if len(addrs) > 0 {
+		for _, addr := range addrs {
+			symbolizeAddr(addr)
+		}
+		return status
+	}
+
+	scan := bufio.NewScanner(in)
+	scan.Split(bufio.ScanWords)
+	for scan.Scan() {
+		symbolizeAddr(scan.Text())
+	}
+	if err := scan.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return status
+}
+
+// printInlineStack prints the locations returned by PCToInlineStack for pc
+// in the same format used by Go tracebacks.
+func printInlineStack(w io.Writer, pc uint64, locs []proc.Location) {
+	if len(locs) == 0 {
+		fmt.Fprintf(w, "%#x ??\n", pc)
+		return
+	}
+	fmt.Fprintf(w, "%#x\n", pc)
+	for i, loc := range locs {
+		inlined := ""
+		if i < len(locs)-1 {
+			inlined = " (inlined)"
+		}
+		fmt.Fprintf(w, "\t%s%s\n\t\t%s:%d\n", loc.Fn.Name, inlined, loc.File, loc.Line)
+	}
+}
+
+// symbolizeStream copies in to out appending to each pc=0x... token the
+// function, file and line of the address, followed by the functions and
+// source lines it was inlined into.
+func symbolizeStream(out io.Writer, in io.Reader, bi *proc.BinaryInfo) error {
+	rd := bufio.NewReader(in)
+	for {
+		line, err := rd.ReadString('\n')
+		if len(line) > 0 {
+			line = symbolizePCRegex.ReplaceAllStringFunc(line, func(tok string) string {
+				pc, err := strconv.ParseUint(tok[len("pc=0x"):], 16, 64)
+				if err != nil {
+					return tok
+				}
+				locs := bi.PCToInlineStack(pc)
+				if len(locs) == 0 {
+					return tok
+				}
+				var buf strings.Builder
+				fmt.Fprintf(&buf, "%s (", tok)
+				for i, loc := range locs {
+					if i > 0 {
+						buf.WriteString(", inlined in ")
+					}
+					fmt.Fprintf(&buf, "%s at %s:%d", loc.Fn.Name, loc.File, loc.Line)
+				}
+				buf.WriteString(")")
+				return buf.String()
+			})
+			if _, err := io.WriteString(out, line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -3,6 +3,7 @@ package main_test
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
@@ -19,6 +20,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/core"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 	"github.com/go-delve/delve/pkg/terminal"
 	"github.com/go-delve/delve/service/dap/daptest"
@@ -628,6 +631,79 @@ func TestTrace(t *testing.T) {
 	cmd.Wait()
 }
 
+func TestSymbolize(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixture := protest.BuildFixture("testinlinenested", protest.EnableInlining)
+
+	// find an address inside main.inner inlined in main.outer inlined in main.main
+	p, err := core.OpenExecutable(fixture.Path, nil, proc.BinaryInfoCacheConfig{})
+	assertNoError(err, t, "OpenExecutable")
+	pcs, err := proc.FindFileLocation(p, fixture.Source, 8)
+	assertNoError(err, t, "FindFileLocation")
+	var pc uint64
+	var stack []proc.Location
+	for _, curpc := range pcs {
+		stack = p.BinInfo().PCToInlineStack(curpc)
+		if len(stack) == 3 {
+			pc = curpc
+			break
+		}
+	}
+	p.Detach(false)
+	if pc == 0 {
+		t.Fatalf("could not find an inlined call of main.inner in %#v", pcs)
+	}
+
+	expected := fmt.Sprintf("%#x\n\tmain.inner (inlined)\n\t\t%s:8\n\tmain.outer (inlined)\n\t\t%s:%d\n\tmain.main\n\t\t%s:%d\n", pc, fixture.Source, fixture.Source, stack[1].Line, fixture.Source, stack[2].Line)
+	out, err := exec.Command(dlvbin, "symbolize", fixture.Path, fmt.Sprintf("%#x", pc)).Output()
+	assertNoError(err, t, "symbolize")
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	cmd := exec.Command(dlvbin, "symbolize", "--rewrite", fixture.Path)
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("goroutine 1 pc=%#x sp=0x0\n", pc))
+	out, err = cmd.Output()
+	assertNoError(err, t, "symbolize --rewrite")
+	expected = fmt.Sprintf("goroutine 1 pc=%#x (main.inner at %s:8, inlined in main.outer at %s:%d, inlined in main.main at %s:%d) sp=0x0\n", pc, fixture.Source, fixture.Source, stack[1].Line, fixture.Source, stack[2].Line)
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestDwarfcheck(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixture := protest.BuildFixture("testinlinenested", 0)
+
+	// problems in the runtime and standard library depend on the toolchain,
+	// only check that the report can be parsed and that every problem has a
+	// severity.
+	out, err := exec.Command(dlvbin, "dwarfcheck", "--json", fixture.Path).Output()
+	if _, isexit := err.(*exec.ExitError); err != nil && !isexit {
+		t.Fatalf("dwarfcheck: %v", err)
+	}
+	var problems []proc.DwarfProblem
+	assertNoError(json.Unmarshal(out, &problems), t, "json.Unmarshal")
+	for _, problem := range problems {
+		if problem.Severity != proc.DwarfWarning && problem.Severity != proc.DwarfError {
+			t.Errorf("wrong severity for problem %s", problem.String())
+		}
+	}
+
+	// the main package has no errors
+	out, err = exec.Command(dlvbin, "dwarfcheck", "--json", "--severity=error", "--package=main", fixture.Path).Output()
+	assertNoError(err, t, "dwarfcheck --package=main")
+	problems = nil
+	assertNoError(json.Unmarshal(out, &problems), t, "json.Unmarshal")
+	for _, problem := range problems {
+		t.Errorf("unexpected problem %s", problem.String())
+	}
+}
+
 func TestTracePid(t *testing.T) {
 	if runtime.GOOS == "linux" {
 		bs, _ := ioutil.ReadFile("/proc/sys/kernel/yama/ptrace_scope")
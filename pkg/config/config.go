@@ -53,6 +53,10 @@ type Config struct {
 	// expression for its argument.
 	ShowLocationExpr bool `yaml:"show-location-expr"`
 
+	// DisableBuiltinFormatters disables the formatters used to display
+	// values of standard library types, like time.Time and sync.Mutex.
+	DisableBuiltinFormatters bool `yaml:"disable-builtin-formatters"`
+
 	// Source list line-number color (3/4 bit color codes as defined
 	// here: https://en.wikipedia.org/wiki/ANSI_escape_code#Colors)
 	SourceListLineColor int `yaml:"source-list-line-color"`
@@ -64,6 +68,17 @@ type Config struct {
 	// DebugFileDirectories is the list of directories Delve will use
 	// in order to resolve external debug info files.
 	DebugInfoDirectories []string `yaml:"debug-info-directories"`
+
+	// SolibSearchPath is the list of directories Delve will use in order
+	// to find the shared libraries mapped in core files.
+	SolibSearchPath []string `yaml:"solib-search-path"`
+
+	// BinaryInfoCache enables the on-disk cache of the debug information
+	// of executable files. Shared libraries and plugins are not cached.
+	BinaryInfoCache bool `yaml:"bininfo-cache"`
+	// BinaryInfoCacheSize is the maximum size, in megabytes, of the on-disk
+	// cache of debug information.
+	BinaryInfoCacheSize *int `yaml:"bininfo-cache-size,omitempty"`
 }
 
 func (c *Config) GetSourceListLineCount() int {
@@ -75,6 +90,16 @@ func (c *Config) GetSourceListLineCount() int {
 	return n
 }
 
+// GetBinaryInfoCacheSize returns the maximum size of the on-disk cache
+// of debug information, in megabytes.
+func (c *Config) GetBinaryInfoCacheSize() int {
+	n := 1024 // default value
+	if c.BinaryInfoCacheSize != nil && *c.BinaryInfoCacheSize >= 0 {
+		n = *c.BinaryInfoCacheSize
+	}
+	return n
+}
+
 // LoadConfig attempts to populate a Config object from the config.yml file.
 func LoadConfig() *Config {
 	err := createConfigPath()
@@ -245,11 +270,27 @@ substitute-path:
 # Uncomment the following line to make the whatis command also print the DWARF location expression of its argument.
 # show-location-expr: true
 
+# Uncomment the following line to display values of standard library types,
+# like time.Time and sync.Mutex, without using the built-in formatters.
+# disable-builtin-formatters: true
+
 # Allow user to specify output syntax flavor of assembly, one of this list "intel"(default), "gnu", "go".
 # disassemble-flavor: intel
 
 # List of directories to use when searching for separate debug info files.
 debug-info-directories: ["/usr/lib/debug/.build-id"]
+
+# List of directories to use when searching for the shared libraries of core files.
+# solib-search-path: []
+
+# Uncomment the following line to cache the debug information of executables
+# on disk, speeding up subsequent debugging sessions of the same executable.
+# Shared libraries and plugins are not cached.
+# It can be disabled for a single session with --no-cache.
+# bininfo-cache: true
+
+# Maximum size of the debug information cache, in megabytes.
+# bininfo-cache-size: 1024
 `)
 	return err
 }
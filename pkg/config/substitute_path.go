@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// InferSubstitutePath proposes substitute-path rules for the source files
+// in files, as recorded in the debug information of a program, that do not
+// exist locally.
+// Each file is matched with the directory in roots containing the longest
+// suffix of its path, the part of its path preceding the suffix is mapped
+// to that directory. A rule is only proposed if it maps most of the
+// missing files it applies to on a file that exists.
+// Rules with the same From as one of the rules in current are never
+// proposed, the existing rules take precedence.
+func InferSubstitutePath(files []string, roots []string, current SubstitutePathRules) SubstitutePathRules {
+	var missing []string
+	for _, file := range files {
+		if !strings.HasPrefix(file, "/") && !(len(file) > 2 && file[1] == ':' && file[2] == '/') {
+			// relative paths (<autogenerated>, -trimpath builds) can not be
+			// mapped to a directory
+			continue
+		}
+		if exists(file) {
+			continue
+		}
+		missing = append(missing, file)
+	}
+
+	votes := map[SubstitutePathRule]int{}
+	for _, file := range missing {
+		if rule, ok := matchSourceRoot(file, roots); ok {
+			votes[rule]++
+		}
+	}
+
+	var rules SubstitutePathRules
+	for rule := range votes {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if votes[rules[i]] != votes[rules[j]] {
+			return votes[rules[i]] > votes[rules[j]]
+		}
+		return rules[i].From < rules[j].From
+	})
+
+	r := SubstitutePathRules{}
+	seen := map[string]bool{}
+	for _, rule := range current {
+		seen[rule.From] = true
+	}
+	for _, rule := range rules {
+		if seen[rule.From] {
+			continue
+		}
+		hit, miss := 0, 0
+		for _, file := range missing {
+			if !strings.HasPrefix(file, rule.From+"/") {
+				continue
+			}
+			if exists(filepath.Join(rule.To, filepath.FromSlash(file[len(rule.From)+1:]))) {
+				hit++
+			} else {
+				miss++
+			}
+		}
+		if hit > miss {
+			seen[rule.From] = true
+			r = append(r, rule)
+		}
+	}
+	return r
+}
+
+// matchSourceRoot returns the rule mapping file to a file in one of the
+// directories in roots, using the longest suffix of file that exists in
+// any of them.
+func matchSourceRoot(file string, roots []string) (SubstitutePathRule, bool) {
+	parts := strings.Split(file, "/")
+	for i := 1; i < len(parts); i++ {
+		if i == 1 && parts[0] == "" {
+			// never map the root directory
+			continue
+		}
+		suffix := filepath.Join(parts[i:]...)
+		for _, root := range roots {
+			if exists(filepath.Join(root, suffix)) {
+				return SubstitutePathRule{From: strings.Join(parts[:i], "/"), To: root}, true
+			}
+		}
+	}
+	return SubstitutePathRule{}, false
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInferSubstitutePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infersubstitutepath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modroot := filepath.Join(dir, "proj")
+	goroot := filepath.Join(dir, "go")
+	for _, file := range []string{
+		filepath.Join(modroot, "go.mod"),
+		filepath.Join(modroot, "main.go"),
+		filepath.Join(modroot, "pkg", "foo", "foo.go"),
+		filepath.Join(goroot, "src", "runtime", "proc.go"),
+		filepath.Join(goroot, "src", "fmt", "print.go"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := []string{
+		"/home/runner/work/proj/proj/main.go",
+		"/home/runner/work/proj/proj/pkg/foo/foo.go",
+		"/opt/buildenv/go/src/runtime/proc.go",
+		"/opt/buildenv/go/src/fmt/print.go",
+		"/opt/buildenv/go/src/fmt/missing.go",
+		"/somewhere/else/main.go",
+		"/somewhere/else/other.go",
+		"/somewhere/else/another.go",
+		"<autogenerated>",
+	}
+
+	rules := InferSubstitutePath(files, []string{modroot, goroot}, nil)
+	tgt := SubstitutePathRules{
+		{From: "/home/runner/work/proj/proj", To: modroot},
+		{From: "/opt/buildenv/go", To: goroot},
+	}
+	if !reflect.DeepEqual(rules, tgt) {
+		t.Errorf("expected %#v got %#v", tgt, rules)
+	}
+
+	// a rule set by the user for the same directory is kept
+	rules = InferSubstitutePath(files, []string{modroot, goroot}, SubstitutePathRules{{From: "/opt/buildenv/go", To: "/usr/local/go"}})
+	tgt = SubstitutePathRules{
+		{From: "/home/runner/work/proj/proj", To: modroot},
+	}
+	if !reflect.DeepEqual(rules, tgt) {
+		t.Errorf("expected %#v got %#v", tgt, rules)
+	}
+}
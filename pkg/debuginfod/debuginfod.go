@@ -0,0 +1,190 @@
+// Package debuginfod implements a client for debuginfod servers, which
+// serve debug information and source files of ELF binaries by build-id.
+// See https://sourceware.org/elfutils/Debuginfod.html.
+package debuginfod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables used to configure the client returned by
+// NewClientFromEnv. DEBUGINFOD_URLS, DEBUGINFOD_CACHE_PATH and
+// DEBUGINFOD_TIMEOUT have the same meaning they have for elfutils.
+const (
+	envURLs      = "DEBUGINFOD_URLS"          // space separated list of server URLs
+	envCachePath = "DEBUGINFOD_CACHE_PATH"    // directory where downloaded files are cached
+	envTimeout   = "DEBUGINFOD_TIMEOUT"       // timeout of each request, in seconds
+	envOffline   = "DELVE_DEBUGINFOD_OFFLINE" // if set only the cache is used
+)
+
+// DefaultTimeout is the timeout of each request when DEBUGINFOD_TIMEOUT is
+// not set.
+const DefaultTimeout = 90 * time.Second
+
+// ErrNotFound is returned when no server has the requested file.
+var ErrNotFound = errors.New("not found on debuginfod servers")
+
+// Client downloads files from debuginfod servers and caches them.
+type Client struct {
+	// URLs are the base URLs of the servers, queried in order.
+	URLs []string
+	// CacheDir is the directory where downloaded files are stored.
+	CacheDir string
+	// Timeout is the timeout of each request.
+	Timeout time.Duration
+	// Offline disables all requests, only files already in the cache are
+	// returned.
+	Offline bool
+
+	mu       sync.Mutex
+	notFound map[string]bool // files that no server has
+}
+
+// NewClientFromEnv returns a client configured through environment
+// variables, or nil if no server is configured and offline mode is off.
+func NewClientFromEnv() *Client {
+	c := &Client{
+		URLs:    strings.Fields(os.Getenv(envURLs)),
+		Timeout: DefaultTimeout,
+		Offline: os.Getenv(envOffline) != "",
+	}
+	if len(c.URLs) == 0 && !c.Offline {
+		return nil
+	}
+	if s := os.Getenv(envTimeout); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			c.Timeout = time.Duration(n) * time.Second
+		}
+	}
+	c.CacheDir = os.Getenv(envCachePath)
+	if c.CacheDir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			cacheDir = os.TempDir()
+		}
+		c.CacheDir = filepath.Join(cacheDir, "debuginfod_client")
+	}
+	return c
+}
+
+// DebugInfo returns the path of a local copy of the separate debug info
+// file of the binary with the specified build-id.
+func (c *Client) DebugInfo(buildID string) (string, error) {
+	return c.fetch(buildID, "debuginfo", "debuginfo")
+}
+
+// Source returns the path of a local copy of the source file at path, used
+// to compile the binary with the specified build-id.
+func (c *Client) Source(buildID, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("source path %q is not absolute", path)
+	}
+	// Slashes are escaped the same way elfutils does in its cache.
+	return c.fetch(buildID, "source"+escapePath(path), "source-"+strings.Replace(path, "/", "#", -1))
+}
+
+func escapePath(path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i := range parts {
+		parts[i] = url.PathEscape(parts[i])
+	}
+	return strings.Join(parts, "/")
+}
+
+// fetch returns the path of the cached copy of /buildid/<buildID>/<kind>,
+// downloading it if necessary.
+func (c *Client) fetch(buildID, kind, cacheName string) (string, error) {
+	if buildID == "" || strings.ContainsAny(buildID, "/\\.") {
+		return "", fmt.Errorf("invalid build-id %q", buildID)
+	}
+	cachePath := filepath.Join(c.CacheDir, buildID, cacheName)
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	}
+	if c.Offline {
+		return "", ErrNotFound
+	}
+
+	key := buildID + "/" + kind
+	c.mu.Lock()
+	notFound := c.notFound[key]
+	c.mu.Unlock()
+	if notFound {
+		return "", ErrNotFound
+	}
+
+	var errs []string
+	for _, server := range c.URLs {
+		err := c.download(strings.TrimSuffix(server, "/")+"/buildid/"+buildID+"/"+kind, cachePath)
+		if err == nil {
+			return cachePath, nil
+		}
+		if err != ErrNotFound {
+			errs = append(errs, fmt.Sprintf("%s: %v", server, err))
+		}
+	}
+	if len(errs) > 0 {
+		return "", fmt.Errorf("could not download %s of %s: %s", kind, buildID, strings.Join(errs, "; "))
+	}
+	c.mu.Lock()
+	if c.notFound == nil {
+		c.notFound = make(map[string]bool)
+	}
+	c.notFound[key] = true
+	c.mu.Unlock()
+	return "", ErrNotFound
+}
+
+// download saves the contents of url to path. The file is written to a
+// temporary file first so that concurrent clients never see partial files.
+func (c *Client) download(url, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".download")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package debuginfod
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testBuildID = "0123456789abcdef"
+
+func newTestServer(requests *int32) *httptest.Server {
+	files := map[string]string{
+		"/buildid/" + testBuildID + "/debuginfo":                  "DEBUGINFO",
+		"/buildid/" + testBuildID + "/source/src/pkg/main.go":     "package main",
+		"/buildid/" + testBuildID + "/source/src/pkg/a%20b.go":    "package main // spaces",
+		"/buildid/" + testBuildID + "/source/src/pkg/sleeping.go": "",
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if strings.HasSuffix(r.URL.Path, "sleeping.go") {
+			time.Sleep(500 * time.Millisecond)
+		}
+		contents, ok := files[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(contents))
+	}))
+}
+
+func newTestClient(t *testing.T, urls ...string) *Client {
+	cacheDir, err := ioutil.TempDir("", "debuginfod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{URLs: urls, CacheDir: cacheDir, Timeout: 5 * time.Second}
+}
+
+func assertContents(t *testing.T, path, tgt string) {
+	t.Helper()
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != tgt {
+		t.Errorf("wrong contents of %s: %q, expected %q", path, buf, tgt)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	var requests int32
+	srv := newTestServer(&requests)
+	defer srv.Close()
+	emptySrv := httptest.NewServer(http.NotFoundHandler())
+	defer emptySrv.Close()
+
+	c := newTestClient(t, emptySrv.URL, srv.URL+"/")
+	defer os.RemoveAll(c.CacheDir)
+
+	path, err := c.DebugInfo(testBuildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContents(t, path, "DEBUGINFO")
+
+	path, err = c.Source(testBuildID, "/src/pkg/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContents(t, path, "package main")
+
+	path, err = c.Source(testBuildID, "/src/pkg/a b.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContents(t, path, "package main // spaces")
+
+	if _, err := c.Source(testBuildID, "/src/pkg/missing.go"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound got %v", err)
+	}
+
+	// Files are served from the cache and missing files are remembered.
+	n := atomic.LoadInt32(&requests)
+	if _, err := c.DebugInfo(testBuildID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Source(testBuildID, "/src/pkg/missing.go"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound got %v", err)
+	}
+	if m := atomic.LoadInt32(&requests); m != n {
+		t.Errorf("unexpected requests to the server: %d", m-n)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	var requests int32
+	srv := newTestServer(&requests)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	defer os.RemoveAll(c.CacheDir)
+	if _, err := c.DebugInfo(testBuildID); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Offline = true
+	path, err := c.DebugInfo(testBuildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContents(t, path, "DEBUGINFO")
+	if _, err := c.Source(testBuildID, "/src/pkg/main.go"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound got %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("wrong number of requests %d", n)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var requests int32
+	srv := newTestServer(&requests)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	defer os.RemoveAll(c.CacheDir)
+	c.Timeout = 50 * time.Millisecond
+	_, err := c.Source(testBuildID, "/src/pkg/sleeping.go")
+	if err == nil || err == ErrNotFound {
+		t.Fatalf("expected timeout error got %v", err)
+	}
+	t.Logf("%v", err)
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	for _, name := range []string{envURLs, envCachePath, envTimeout, envOffline} {
+		defer os.Setenv(name, os.Getenv(name))
+	}
+	os.Setenv(envURLs, "")
+	os.Setenv(envOffline, "")
+	if c := NewClientFromEnv(); c != nil {
+		t.Errorf("expected nil client, got %#v", c)
+	}
+
+	os.Setenv(envURLs, "http://a.example  https://b.example/")
+	os.Setenv(envCachePath, "/tmp/cache")
+	os.Setenv(envTimeout, "3")
+	c := NewClientFromEnv()
+	if c == nil {
+		t.Fatal("expected client")
+	}
+	if len(c.URLs) != 2 || c.URLs[0] != "http://a.example" || c.URLs[1] != "https://b.example/" {
+		t.Errorf("wrong URLs %q", c.URLs)
+	}
+	if c.CacheDir != "/tmp/cache" || c.Timeout != 3*time.Second || c.Offline {
+		t.Errorf("wrong configuration %#v", c)
+	}
+}
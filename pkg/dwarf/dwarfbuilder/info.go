@@ -234,6 +234,9 @@ func (b *Builder) makeAbbrevTable() []byte {
 		util.EncodeULEB128(&abbrev, 0)
 	}
 
+	// end of abbreviations
+	abbrev.WriteByte(0)
+
 	return abbrev.Bytes()
 }
 
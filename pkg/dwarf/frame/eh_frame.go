@@ -0,0 +1,137 @@
+package frame
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+// ptrEnc is a pointer encoding (DW_EH_PE_*) used by .eh_frame and
+// .eh_frame_hdr. The low 4 bits describe the format of the value, the high
+// 4 bits how it should be applied.
+type ptrEnc uint8
+
+const (
+	ptrEncAbs      ptrEnc = 0x00 // pointer-sized unsigned integer
+	ptrEncOmit     ptrEnc = 0xff // omitted
+	ptrEncUleb     ptrEnc = 0x01 // ULEB128
+	ptrEncUdata2   ptrEnc = 0x02 // 2 bytes
+	ptrEncUdata4   ptrEnc = 0x03 // 4 bytes
+	ptrEncUdata8   ptrEnc = 0x04 // 8 bytes
+	ptrEncSigned   ptrEnc = 0x08 // pointer-sized signed integer
+	ptrEncSleb     ptrEnc = 0x09 // SLEB128
+	ptrEncSdata2   ptrEnc = 0x0a // 2 bytes, signed
+	ptrEncSdata4   ptrEnc = 0x0b // 4 bytes, signed
+	ptrEncSdata8   ptrEnc = 0x0c // 8 bytes, signed
+	ptrEncPCRel    ptrEnc = 0x10 // relative to the address of the encoded value
+	ptrEncDataRel  ptrEnc = 0x30 // relative to the start of .eh_frame_hdr
+	ptrEncIndirect ptrEnc = 0x80 // the value is the address of the real pointer
+)
+
+// supported returns true if readEncodedPtr can read pointers encoded with
+// ptrEnc.
+func (ptrEnc ptrEnc) supported() bool {
+	if ptrEnc == ptrEncOmit {
+		return true
+	}
+	switch ptrEnc & 0x0f {
+	case ptrEncAbs, ptrEncSigned, ptrEncUleb, ptrEncUdata2, ptrEncSdata2, ptrEncUdata4, ptrEncSdata4, ptrEncUdata8, ptrEncSdata8, ptrEncSleb:
+	default:
+		return false
+	}
+	switch ptrEnc & 0x70 {
+	case ptrEncAbs, ptrEncPCRel:
+	default:
+		return false
+	}
+	return ptrEnc&ptrEncIndirect == 0
+}
+
+// readEncodedPtr reads a pointer with encoding ptrEnc from buf, addr is
+// the address of the encoded value and is used to resolve PC relative
+// pointers.
+func readEncodedPtr(addr uint64, buf *bytes.Buffer, ptrEnc ptrEnc, ptrSize int) (uint64, error) {
+	if ptrEnc == ptrEncOmit {
+		return 0, nil
+	}
+
+	var ptr uint64
+	var err error
+	switch ptrEnc & 0x0f {
+	case ptrEncAbs, ptrEncSigned:
+		ptr, err = util.ReadUintRaw(buf, binary.LittleEndian, ptrSize)
+	case ptrEncUleb:
+		ptr, _ = util.DecodeULEB128(buf)
+	case ptrEncUdata2:
+		var n uint16
+		err = binary.Read(buf, binary.LittleEndian, &n)
+		ptr = uint64(n)
+	case ptrEncSdata2:
+		var n int16
+		err = binary.Read(buf, binary.LittleEndian, &n)
+		ptr = uint64(n)
+	case ptrEncUdata4:
+		var n uint32
+		err = binary.Read(buf, binary.LittleEndian, &n)
+		ptr = uint64(n)
+	case ptrEncSdata4:
+		var n int32
+		err = binary.Read(buf, binary.LittleEndian, &n)
+		ptr = uint64(n)
+	case ptrEncUdata8, ptrEncSdata8:
+		err = binary.Read(buf, binary.LittleEndian, &ptr)
+	case ptrEncSleb:
+		n, _ := util.DecodeSLEB128(buf)
+		ptr = uint64(n)
+	default:
+		return 0, fmt.Errorf("unsupported pointer encoding %#x", uint8(ptrEnc))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch ptrEnc & 0x70 {
+	case ptrEncAbs:
+		// nothing to do
+	case ptrEncPCRel:
+		ptr += addr
+	default:
+		return 0, fmt.Errorf("unsupported pointer encoding %#x", uint8(ptrEnc))
+	}
+
+	if ptrEnc&ptrEncIndirect != 0 {
+		return 0, fmt.Errorf("unsupported pointer encoding %#x", uint8(ptrEnc))
+	}
+
+	return ptr, nil
+}
+
+// EhFrameHdrAddr parses the .eh_frame_hdr section data, loaded at addr,
+// and returns the address of the .eh_frame section it refers to.
+// This can be used to find .eh_frame through the PT_GNU_EH_FRAME segment
+// when section headers are missing.
+func EhFrameHdrAddr(data []byte, addr uint64, ptrSize int) (uint64, error) {
+	if len(data) < 4 {
+		return 0, errors.New("malformed .eh_frame_hdr")
+	}
+	if data[0] != 1 {
+		return 0, fmt.Errorf("unsupported .eh_frame_hdr version %d", data[0])
+	}
+	ehFramePtrEnc := ptrEnc(data[1])
+	buf := bytes.NewBuffer(data[4:])
+	var ehFrameAddr uint64
+	var err error
+	if ehFramePtrEnc&0x70 == ptrEncDataRel {
+		ehFrameAddr, err = readEncodedPtr(0, buf, ehFramePtrEnc&0x0f, ptrSize)
+		ehFrameAddr += addr
+	} else {
+		ehFrameAddr, err = readEncodedPtr(addr+4, buf, ehFramePtrEnc, ptrSize)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading .eh_frame_hdr: %v", err)
+	}
+	return ehFrameAddr, nil
+}
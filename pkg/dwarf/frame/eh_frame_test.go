@@ -0,0 +1,113 @@
+package frame
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+)
+
+// testdata/eh_frame and testdata/eh_frame_hdr were extracted from a shared
+// object, built with gcc, where they were loaded at these addresses.
+const (
+	testEhFrameAddr    = 0x2028
+	testEhFrameHdrAddr = 0x2000
+)
+
+func TestParseEhFrame(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/eh_frame")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const staticBase = 0x7f0000000000
+	fdes, err := ParseEHFrame(data, binary.LittleEndian, staticBase, 8, testEhFrameAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tgt := []struct{ begin, end uint64 }{
+		{0x1000, 0x1020},
+		{0x1020, 0x1044},
+		{0x1044, 0x1050},
+	}
+	if len(fdes) != len(tgt) {
+		t.Fatalf("wrong number of FDEs %d", len(fdes))
+	}
+	for i, fde := range fdes {
+		if fde.Begin() != tgt[i].begin+staticBase || fde.End() != tgt[i].end+staticBase {
+			t.Errorf("FDE %d: got %#x-%#x expected %#x-%#x", i, fde.Begin(), fde.End(), tgt[i].begin+staticBase, tgt[i].end+staticBase)
+		}
+		if fde.CIE.Augmentation != "zR" || fde.CIE.ReturnAddressRegister != 16 || fde.CIE.DataAlignmentFactor != -8 {
+			t.Errorf("FDE %d: wrong CIE %#v", i, fde.CIE)
+		}
+	}
+
+	fde, err := fdes.FDEForPC(staticBase + 0x1030)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := fde.EstablishFrame(staticBase + 0x1030)
+	if ctx.CFA.Reg != 7 || ctx.CFA.Offset != 152 {
+		t.Errorf("wrong CFA rule %#v", ctx.CFA)
+	}
+	if rule := ctx.Regs[16]; rule.Rule != RuleOffset || rule.Offset != -8 {
+		t.Errorf("wrong return address rule %#v", rule)
+	}
+}
+
+func TestEhFrameHdrAddr(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/eh_frame_hdr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := EhFrameHdrAddr(data, testEhFrameHdrAddr, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != testEhFrameAddr {
+		t.Errorf("wrong .eh_frame address %#x", addr)
+	}
+}
+
+func TestParseEhFrameSkipsBadCIE(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/eh_frame")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Prepend two CIEs that can not be used, one with an unknown
+	// augmentation and one with an unsupported pointer encoding, each
+	// followed by an FDE.
+	var prefix []byte
+	for _, aug := range [][]byte{{'z', 'X', 0, 1, 0x78, 16, 0}, {'z', 'R', 0, 1, 0x78, 16, 1, 0x50}} {
+		cie := append([]byte{0, 0, 0, 0, 1}, aug...)
+		for len(cie)%4 != 0 {
+			cie = append(cie, 0)
+		}
+		cieOff := len(prefix)
+		prefix = append(prefix, le32(uint32(len(cie)))...)
+		prefix = append(prefix, cie...)
+		fdeCIEPtrOff := len(prefix) + 4
+		prefix = append(prefix, le32(12)...)
+		prefix = append(prefix, le32(uint32(fdeCIEPtrOff-cieOff))...)
+		prefix = append(prefix, 0, 0x10, 0, 0, 0x20, 0, 0, 0)
+	}
+
+	const staticBase = 0x7f0000000000
+	fdes, err := ParseEHFrame(append(prefix, data...), binary.LittleEndian, staticBase, 8, testEhFrameAddr-uint64(len(prefix)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fdes) != 3 {
+		t.Fatalf("wrong number of FDEs %d", len(fdes))
+	}
+	if fdes[0].Begin() != 0x1000+staticBase {
+		t.Errorf("wrong first FDE %#x-%#x", fdes[0].Begin(), fdes[0].End())
+	}
+}
+
+func le32(n uint32) []byte {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, n)
+	return buf
+}
@@ -18,6 +18,10 @@ type CommonInformationEntry struct {
 	ReturnAddressRegister uint64
 	InitialInstructions   []byte
 	staticBase            uint64
+
+	// ptrEncAddr is the encoding used for the address field of the FDEs
+	// of this CIE, only meaningful for .eh_frame.
+	ptrEncAddr ptrEnc
 }
 
 // Represents a Frame Descriptor Entry in the
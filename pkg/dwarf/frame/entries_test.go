@@ -67,7 +67,7 @@ func BenchmarkFDEForPC(b *testing.B) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	fdes := Parse(data, binary.BigEndian, 0, ptrSizeByRuntimeArch())
+	fdes, _ := Parse(data, binary.BigEndian, 0, ptrSizeByRuntimeArch())
 
 	for i := 0; i < b.N; i++ {
 		// bench worst case, exhaustive search
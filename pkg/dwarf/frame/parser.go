@@ -1,11 +1,13 @@
 // Package frame contains data structures and
 // related functions for parsing and searching
-// through Dwarf .debug_frame data.
+// through Dwarf .debug_frame and .eh_frame data.
 package frame
 
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"sort"
 
 	"github.com/go-delve/delve/pkg/dwarf/util"
 )
@@ -13,65 +15,136 @@ import (
 type parsefunc func(*parseContext) parsefunc
 
 type parseContext struct {
-	staticBase uint64
+	staticBase  uint64
+	ehFrame     bool   // parsing .eh_frame instead of .debug_frame
+	ehFrameAddr uint64 // address of the .eh_frame section
 
+	data    []byte
 	buf     *bytes.Buffer
 	entries FrameDescriptionEntries
+	ciemap  map[int]*CommonInformationEntry
+	badcie  map[*CommonInformationEntry]bool // CIEs that can't be used, their FDEs are skipped
 	common  *CommonInformationEntry
 	frame   *FrameDescriptionEntry
 	length  uint32
 	ptrSize int
+	err     error
 }
 
-// Parse takes in data (a byte slice) and returns a slice of
-// commonInformationEntry structures. Each commonInformationEntry
-// has a slice of frameDescriptionEntry structures.
-func Parse(data []byte, order binary.ByteOrder, staticBase uint64, ptrSize int) FrameDescriptionEntries {
-	var (
-		buf  = bytes.NewBuffer(data)
-		pctx = &parseContext{buf: buf, entries: newFrameIndex(), staticBase: staticBase, ptrSize: ptrSize}
-	)
+// Parse takes in data (a byte slice) and returns FrameDescriptionEntries.
+// Each FrameDescriptionEntry has a pointer to CommonInformationEntry.
+func Parse(data []byte, order binary.ByteOrder, staticBase uint64, ptrSize int) (FrameDescriptionEntries, error) {
+	return parse(&parseContext{data: data, staticBase: staticBase, ptrSize: ptrSize}, order)
+}
+
+// ParseEHFrame is like Parse but data is the contents of an .eh_frame
+// section loaded at ehFrameAddr (before relocation).
+// CIEs using an augmentation or a pointer encoding that isn't supported
+// are skipped, together with their FDEs.
+func ParseEHFrame(data []byte, order binary.ByteOrder, staticBase uint64, ptrSize int, ehFrameAddr uint64) (FrameDescriptionEntries, error) {
+	return parse(&parseContext{data: data, staticBase: staticBase, ptrSize: ptrSize, ehFrame: true, ehFrameAddr: ehFrameAddr}, order)
+}
 
-	for fn := parselength; buf.Len() != 0; {
+func parse(pctx *parseContext, order binary.ByteOrder) (FrameDescriptionEntries, error) {
+	pctx.buf = bytes.NewBuffer(pctx.data)
+	pctx.entries = newFrameIndex()
+	pctx.ciemap = map[int]*CommonInformationEntry{}
+	pctx.badcie = map[*CommonInformationEntry]bool{}
+
+	for fn := parselength; pctx.buf.Len() != 0 && pctx.err == nil; {
 		fn = fn(pctx)
 	}
+	if pctx.err != nil {
+		return nil, pctx.err
+	}
 
 	for i := range pctx.entries {
 		pctx.entries[i].order = order
 	}
 
-	return pctx.entries
+	sort.Slice(pctx.entries, func(i, j int) bool {
+		return pctx.entries[i].Begin() < pctx.entries[j].Begin()
+	})
+
+	return pctx.entries, nil
 }
 
-func cieEntry(data []byte) bool {
+func (ctx *parseContext) parsingEHFrame() bool {
+	return ctx.ehFrame
+}
+
+// offset returns the offset of the parsing cursor from the start of the
+// section.
+func (ctx *parseContext) offset() int {
+	return len(ctx.data) - ctx.buf.Len()
+}
+
+func (ctx *parseContext) cieEntry(data []byte) bool {
+	if ctx.parsingEHFrame() {
+		return bytes.Equal(data, []byte{0x00, 0x00, 0x00, 0x00})
+	}
 	return bytes.Equal(data, []byte{0xff, 0xff, 0xff, 0xff})
 }
 
 func parselength(ctx *parseContext) parsefunc {
+	start := ctx.offset()
 	binary.Read(ctx.buf, binary.LittleEndian, &ctx.length)
 
 	if ctx.length == 0 {
+		if ctx.parsingEHFrame() {
+			// ZERO terminator of .eh_frame
+			ctx.buf.Next(ctx.buf.Len())
+		}
 		// ZERO terminator
 		return parselength
 	}
+	if ctx.length == 0xffffffff {
+		ctx.err = fmt.Errorf("64bit frame entry at %#x not supported", start)
+		return parselength
+	}
 
+	cieptrOff := ctx.offset()
 	var data = ctx.buf.Next(4)
 
 	ctx.length -= 4 // take off the length of the CIE id / CIE pointer.
 
-	if cieEntry(data) {
-		ctx.common = &CommonInformationEntry{Length: ctx.length, staticBase: ctx.staticBase}
+	if ctx.cieEntry(data) {
+		ctx.common = &CommonInformationEntry{Length: ctx.length, staticBase: ctx.staticBase, CIE_id: binary.LittleEndian.Uint32(data)}
+		ctx.ciemap[start] = ctx.common
 		return parseCIE
 	}
 
-	ctx.frame = &FrameDescriptionEntry{Length: ctx.length, CIE: ctx.common}
+	cieptr := int(binary.LittleEndian.Uint32(data))
+	common := ctx.common
+	if ctx.parsingEHFrame() {
+		// In .eh_frame the CIE pointer is relative to the CIE pointer field.
+		common = ctx.ciemap[cieptrOff-cieptr]
+		if common == nil {
+			ctx.err = fmt.Errorf("unknown CIE at %#x for FDE at %#x", cieptrOff-cieptr, start)
+			return parselength
+		}
+		if ctx.badcie[common] {
+			ctx.buf.Next(int(ctx.length))
+			ctx.length = 0
+			return parselength
+		}
+	} else if cie := ctx.ciemap[cieptr]; cie != nil {
+		common = cie
+	}
+
+	ctx.frame = &FrameDescriptionEntry{Length: ctx.length, CIE: common}
 	return parseFDE
 }
 
 func parseFDE(ctx *parseContext) parsefunc {
 	var num uint64
+	off := ctx.offset()
 	r := ctx.buf.Next(int(ctx.length))
 
+	if ctx.parsingEHFrame() {
+		return parseEHFrameFDE(ctx, off, r)
+	}
+
 	reader := bytes.NewReader(r)
 	num, _ = util.ReadUintRaw(reader, binary.LittleEndian, ctx.ptrSize)
 	ctx.frame.begin = num + ctx.staticBase
@@ -91,6 +164,45 @@ func parseFDE(ctx *parseContext) parsefunc {
 	return parselength
 }
 
+// parseEHFrameFDE parses the body r of an FDE contained in .eh_frame,
+// starting at offset off of the section. Addresses in .eh_frame are
+// encoded as specified by the augmentation of the CIE.
+func parseEHFrameFDE(ctx *parseContext, off int, r []byte) parsefunc {
+	buf := bytes.NewBuffer(r)
+	cie := ctx.frame.CIE
+	ctx.length = 0
+
+	begin, err := readEncodedPtr(ctx.ehFrameAddr+uint64(off), buf, cie.ptrEncAddr, ctx.ptrSize)
+	if err != nil {
+		ctx.err = fmt.Errorf("reading FDE at %#x: %v", off, err)
+		return parselength
+	}
+	// The address range uses the same format as the initial location but
+	// it is never relative.
+	size, err := readEncodedPtr(0, buf, cie.ptrEncAddr&0x0f, ctx.ptrSize)
+	if err != nil {
+		ctx.err = fmt.Errorf("reading FDE at %#x: %v", off, err)
+		return parselength
+	}
+
+	if len(cie.Augmentation) > 0 && cie.Augmentation[0] == 'z' {
+		n, _ := util.DecodeULEB128(buf)
+		buf.Next(int(n))
+	}
+
+	if size == 0 {
+		// Entries for code discarded by the linker.
+		return parselength
+	}
+
+	ctx.frame.begin = begin + ctx.staticBase
+	ctx.frame.size = size
+	ctx.frame.Instructions = buf.Bytes()
+	ctx.entries = append(ctx.entries, ctx.frame)
+
+	return parselength
+}
+
 func parseCIE(ctx *parseContext) parsefunc {
 	data := ctx.buf.Next(int(ctx.length))
 	buf := bytes.NewBuffer(data)
@@ -107,7 +219,24 @@ func parseCIE(ctx *parseContext) parsefunc {
 	ctx.common.DataAlignmentFactor, _ = util.DecodeSLEB128(buf)
 
 	// parse return address register
-	ctx.common.ReturnAddressRegister, _ = util.DecodeULEB128(buf)
+	if ctx.parsingEHFrame() && ctx.common.Version == 1 {
+		b, _ := buf.ReadByte()
+		ctx.common.ReturnAddressRegister = uint64(b)
+	} else {
+		ctx.common.ReturnAddressRegister, _ = util.DecodeULEB128(buf)
+	}
+
+	ctx.common.ptrEncAddr = ptrEncAbs
+
+	if ctx.parsingEHFrame() {
+		if err := parseAugmentation(ctx.common, buf, ctx.ptrSize); err != nil {
+			// Only the FDEs of this CIE are unusable, skip them and keep
+			// parsing the rest of the section.
+			ctx.badcie[ctx.common] = true
+			ctx.length = 0
+			return parselength
+		}
+	}
 
 	// parse initial instructions
 	// The rest of this entry consists of the instructions
@@ -119,6 +248,47 @@ func parseCIE(ctx *parseContext) parsefunc {
 	return parselength
 }
 
+// parseAugmentation parses the augmentation data of a CIE contained in
+// .eh_frame, see:
+// https://refspecs.linuxfoundation.org/LSB_5.0.0/LSB-Core-generic/LSB-Core-generic/ehframechpt.html
+func parseAugmentation(cie *CommonInformationEntry, buf *bytes.Buffer, ptrSize int) error {
+	aug := cie.Augmentation
+	if aug == "" {
+		return nil
+	}
+	if aug[0] != 'z' {
+		return fmt.Errorf("unsupported augmentation string %q", aug)
+	}
+	n, _ := util.DecodeULEB128(buf)
+	augdata := bytes.NewBuffer(buf.Next(int(n)))
+	for _, ch := range aug[1:] {
+		switch ch {
+		case 'L':
+			// Encoding of the LSDA pointer, the LSDA is only needed to handle
+			// exceptions.
+			augdata.ReadByte()
+		case 'R':
+			b, _ := augdata.ReadByte()
+			cie.ptrEncAddr = ptrEnc(b)
+			if !cie.ptrEncAddr.supported() {
+				return fmt.Errorf("unsupported pointer encoding %#x", b)
+			}
+		case 'P':
+			// Personality routine, only needed to handle exceptions.
+			b, _ := augdata.ReadByte()
+			if _, err := readEncodedPtr(0, augdata, ptrEnc(b)&^ptrEncIndirect, ptrSize); err != nil {
+				return fmt.Errorf("reading personality routine: %v", err)
+			}
+		case 'S', 'B':
+			// Signal frame and AArch64 pointer authentication key, they don't
+			// have any augmentation data.
+		default:
+			return fmt.Errorf("unsupported augmentation string %q", aug)
+		}
+	}
+	return nil
+}
+
 // DwarfEndian determines the endianness of the DWARF by using the version number field in the debug_info section
 // Trick borrowed from "debug/dwarf".New()
 func DwarfEndian(infoSec []byte) binary.ByteOrder {
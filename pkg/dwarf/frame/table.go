@@ -62,6 +62,13 @@ const (
 	DW_CFA_restore            = (0x3 << 6) // High 2 bits: 0x3, low 6: register
 )
 
+// GNU extensions, used in .eh_frame.
+const (
+	DW_CFA_GNU_window_save              = 0x2d // No ops (DW_CFA_AARCH64_negate_ra_state on arm64)
+	DW_CFA_GNU_args_size                = 0x2e // op1: ULEB128 size
+	DW_CFA_GNU_negative_offset_extended = 0x2f // op1: ULEB128 register, op2: ULEB128 offset
+)
+
 // Rules defined for register values.
 type Rule byte
 
@@ -111,6 +118,10 @@ var fnlookup = map[byte]instruction{
 	DW_CFA_val_expression:     valexpression,
 	DW_CFA_lo_user:            louser,
 	DW_CFA_hi_user:            hiuser,
+
+	DW_CFA_GNU_window_save:              gnuwindowsave,
+	DW_CFA_GNU_args_size:                gnuargsize,
+	DW_CFA_GNU_negative_offset_extended: gnunegativeoffsetextended,
 }
 
 func executeCIEInstructions(cie *CommonInformationEntry) *FrameContext {
@@ -422,3 +433,23 @@ func louser(frame *FrameContext) {
 func hiuser(frame *FrameContext) {
 	frame.buf.Next(1)
 }
+
+func gnuwindowsave(frame *FrameContext) {
+	// Only meaningful for SPARC register windows and for return address
+	// signing on arm64, neither affects unwinding.
+}
+
+func gnuargsize(frame *FrameContext) {
+	// The size of the arguments pushed on the stack is only needed to
+	// handle exceptions.
+	util.DecodeULEB128(frame.buf)
+}
+
+func gnunegativeoffsetextended(frame *FrameContext) {
+	var (
+		reg, _    = util.DecodeULEB128(frame.buf)
+		offset, _ = util.DecodeULEB128(frame.buf)
+	)
+
+	frame.Regs[reg] = DWRule{Offset: -int64(offset) * frame.dataAlignment, Rule: RuleOffset}
+}
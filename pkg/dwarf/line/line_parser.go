@@ -29,6 +29,10 @@ type DebugLineInfo struct {
 	Instructions []byte
 	Lookup       map[string]*FileEntry
 
+	// InstructionsOffset is the offset of Instructions from the start of
+	// the debug_line segment.
+	InstructionsOffset int
+
 	Logf func(string, ...interface{})
 
 	// stateMachineCache[pc] is a state machine stopped at pc
@@ -50,6 +54,7 @@ type FileEntry struct {
 	DirIdx      uint64
 	LastModTime uint64
 	Length      uint64
+	MD5         []byte // MD5 checksum of the file, only available in DWARFv5
 }
 
 type DebugLines []*DebugLineInfo
@@ -72,17 +77,24 @@ func ParseAll(data []byte, logfn func(string, ...interface{}), staticBase uint64
 // Parse parses a single debug_line segment from buf. Compdir is the
 // DW_AT_comp_dir attribute of the associated compile unit.
 func Parse(compdir string, buf *bytes.Buffer, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) *DebugLineInfo {
-	dbl := new(DebugLineInfo)
-	dbl.Logf = logfn
-	dbl.staticBase = staticBase
-	dbl.ptrSize = ptrSize
-	dbl.Lookup = make(map[string]*FileEntry)
-	dbl.IncludeDirs = append(dbl.IncludeDirs, compdir)
+	dbl := newDebugLineInfo(logfn, staticBase, normalizeBackslash, ptrSize)
+	parseFileTable(dbl, compdir, buf)
+	return dbl
+}
 
-	dbl.stateMachineCache = make(map[uint64]*StateMachine)
-	dbl.lastMachineCache = make(map[uint64]*StateMachine)
-	dbl.normalizeBackslash = normalizeBackslash
+// ParseFileTable parses the directory and file tables of the debug_line
+// segment at the start of data. The returned values can be passed to
+// ParseCached to parse the rest of the segment later.
+func ParseFileTable(compdir string, data []byte, logfn func(string, ...interface{}), normalizeBackslash bool, ptrSize int) (includeDirs []string, fileNames []*FileEntry, instructionsOffset int) {
+	dbl := &DebugLineInfo{Logf: logfn, Lookup: make(map[string]*FileEntry), normalizeBackslash: normalizeBackslash, ptrSize: ptrSize}
+	parseFileTable(dbl, compdir, bytes.NewBuffer(data))
+	return dbl.IncludeDirs, dbl.FileNames, dbl.InstructionsOffset
+}
+
+func parseFileTable(dbl *DebugLineInfo, compdir string, buf *bytes.Buffer) {
+	dbl.IncludeDirs = append(dbl.IncludeDirs, compdir)
 
+	size := buf.Len()
 	parseDebugLinePrologue(dbl, buf)
 	if dbl.Prologue.Version >= 5 {
 		parseIncludeDirs5(dbl, buf)
@@ -96,11 +108,45 @@ func Parse(compdir string, buf *bytes.Buffer, logfn func(string, ...interface{})
 	//   - dbl.Prologue.UnitLength is the length of the entire unit, not including the 4 bytes to represent that length.
 	//   - dbl.Prologue.Length is the length of the prologue not including unit length, version or prologue length itself.
 	//   - So you have UnitLength - PrologueLength - (version_length_bytes(2) + prologue_length_bytes(4)).
+	dbl.InstructionsOffset = size - buf.Len()
 	dbl.Instructions = buf.Next(int(dbl.Prologue.UnitLength - dbl.Prologue.Length - 6))
+}
+
+// ParseCached is like Parse but uses includeDirs, fileNames and
+// instructionsOffset, the IncludeDirs, FileNames and InstructionsOffset
+// fields of the DebugLineInfo returned by a previous call to Parse for the
+// same segment, instead of parsing the directory and file tables.
+func ParseCached(data []byte, includeDirs []string, fileNames []*FileEntry, instructionsOffset int, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) *DebugLineInfo {
+	dbl := newDebugLineInfo(logfn, staticBase, normalizeBackslash, ptrSize)
+	dbl.IncludeDirs = includeDirs
+	dbl.FileNames = fileNames
+	for _, entry := range fileNames {
+		dbl.Lookup[entry.Path] = entry
+	}
+
+	parseDebugLinePrologue(dbl, bytes.NewBuffer(data))
+
+	if instructionsOffset > len(data) {
+		instructionsOffset = len(data)
+	}
+	dbl.InstructionsOffset = instructionsOffset
+	dbl.Instructions = bytes.NewBuffer(data[instructionsOffset:]).Next(int(dbl.Prologue.UnitLength - dbl.Prologue.Length - 6))
 
 	return dbl
 }
 
+func newDebugLineInfo(logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) *DebugLineInfo {
+	dbl := new(DebugLineInfo)
+	dbl.Logf = logfn
+	dbl.staticBase = staticBase
+	dbl.ptrSize = ptrSize
+	dbl.Lookup = make(map[string]*FileEntry)
+	dbl.stateMachineCache = make(map[uint64]*StateMachine)
+	dbl.lastMachineCache = make(map[uint64]*StateMachine)
+	dbl.normalizeBackslash = normalizeBackslash
+	return dbl
+}
+
 func parseDebugLinePrologue(dbl *DebugLineInfo, buf *bytes.Buffer) {
 	p := new(DebugLinePrologue)
 
@@ -211,11 +257,11 @@ func parseFileEntries5(info *DebugLineInfo, buf *bytes.Buffer) {
 	info.FileNames = make([]*FileEntry, 0, fileCount)
 	for i := 0; i < int(fileCount); i++ {
 		fileEntryFormReader.reset()
-		for fileEntryFormReader.next(buf) {
-			entry := new(FileEntry)
-			var path string
-			var diridx int = -1
+		entry := new(FileEntry)
+		var path string
+		var diridx int = -1
 
+		for fileEntryFormReader.next(buf) {
 			switch fileEntryFormReader.contentType {
 			case _DW_LNCT_path:
 				if fileEntryFormReader.formCode != _DW_FORM_string {
@@ -231,15 +277,15 @@ func parseFileEntries5(info *DebugLineInfo, buf *bytes.Buffer) {
 			case _DW_LNCT_size:
 				entry.Length = fileEntryFormReader.u64
 			case _DW_LNCT_MD5:
-				// not implemented
+				entry.MD5 = append([]byte(nil), fileEntryFormReader.block...)
 			}
+		}
 
-			if diridx >= 0 && !filepath.IsAbs(path) && diridx < len(info.IncludeDirs) {
-				path = filepath.Join(info.IncludeDirs[diridx], path)
-			}
-			entry.Path = path
-			info.FileNames = append(info.FileNames, entry)
-			info.Lookup[entry.Path] = entry
+		if diridx >= 0 && !filepath.IsAbs(path) && diridx < len(info.IncludeDirs) {
+			path = filepath.Join(info.IncludeDirs[diridx], path)
 		}
+		entry.Path = path
+		info.FileNames = append(info.FileNames, entry)
+		info.Lookup[entry.Path] = entry
 	}
 }
@@ -1,6 +1,7 @@
 package line
 
 import (
+	"bytes"
 	"compress/zlib"
 	"debug/elf"
 	"debug/macho"
@@ -11,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -374,3 +376,36 @@ func TestDebugLineDwarf4(t *testing.T) {
 	}
 
 }
+
+func TestParseCached(t *testing.T) {
+	// Tests that ParseFileTable and ParseCached find the same file table,
+	// prologue and instructions found by Parse.
+	p, err := filepath.Abs("../../../_fixtures/debug_line_c_data")
+	if err != nil {
+		t.Fatal("Could not find test data", p, err)
+	}
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatal("Could not read test data", err)
+	}
+
+	buf := bytes.NewBuffer(data)
+	for buf.Len() > 0 {
+		off := len(data) - buf.Len()
+		dbl := Parse("", buf, nil, 0, true, ptrSizeByRuntimeArch())
+		cached := ParseCached(data[off:], dbl.IncludeDirs, dbl.FileNames, dbl.InstructionsOffset, nil, 0, true, ptrSizeByRuntimeArch())
+		if !reflect.DeepEqual(dbl.Prologue, cached.Prologue) {
+			t.Errorf("prologue mismatch at %#x: %#v %#v", off, dbl.Prologue, cached.Prologue)
+		}
+		if !bytes.Equal(dbl.Instructions, cached.Instructions) {
+			t.Errorf("instructions mismatch at %#x", off)
+		}
+		if !reflect.DeepEqual(dbl.Lookup, cached.Lookup) {
+			t.Errorf("file lookup mismatch at %#x", off)
+		}
+		includeDirs, fileNames, instructionsOffset := ParseFileTable("", data[off:], nil, true, ptrSizeByRuntimeArch())
+		if !reflect.DeepEqual(dbl.IncludeDirs, includeDirs) || !reflect.DeepEqual(dbl.FileNames, fileNames) || dbl.InstructionsOffset != instructionsOffset {
+			t.Errorf("file table mismatch at %#x", off)
+		}
+	}
+}
@@ -405,6 +405,66 @@ func (lineInfo *DebugLineInfo) FirstStmtForLine(start, end uint64) (pc uint64, f
 	}
 }
 
+// Gaps returns the address ranges inside the half open interval [start,
+// end) that are not described by the line table or that are described by
+// rows with line number 0, adjacent ranges are merged.
+func (lineInfo *DebugLineInfo) Gaps(start, end uint64) [][2]uint64 {
+	if lineInfo == nil {
+		return [][2]uint64{{start, end}}
+	}
+
+	var gaps [][2]uint64
+	addGap := func(a, b uint64) {
+		if a < start {
+			a = start
+		}
+		if b > end {
+			b = end
+		}
+		if a >= b {
+			return
+		}
+		if n := len(gaps); n > 0 && gaps[n-1][1] == a {
+			gaps[n-1][1] = b
+			return
+		}
+		gaps = append(gaps, [2]uint64{a, b})
+	}
+
+	sm := lineInfo.stateMachineForEntry(start)
+	if !sm.valid {
+		return [][2]uint64{{start, end}}
+	}
+
+	// pos is the first address that hasn't been checked yet, the row
+	// preceding the current one, if any, describes [pos, sm.address).
+	pos := start
+	havePrev := sm.lastAddress != ^uint64(0) && sm.lastAddress <= start
+	prevLine := sm.lastLine
+	for pos < end {
+		if sm.valid {
+			if !havePrev || prevLine == 0 {
+				addGap(pos, sm.address)
+			}
+			if sm.address > pos {
+				pos = sm.address
+			}
+			if sm.endSeq {
+				break
+			}
+			havePrev, prevLine = true, sm.line
+		}
+		if err := sm.next(); err != nil {
+			if lineInfo.Logf != nil {
+				lineInfo.Logf("Gaps error: %v", err)
+			}
+			break
+		}
+	}
+	addGap(pos, end)
+	return gaps
+}
+
 func (lineInfo *DebugLineInfo) FirstFile() string {
 	sm := newStateMachine(lineInfo, lineInfo.Instructions, lineInfo.ptrSize)
 	for {
@@ -268,3 +268,86 @@ func TestMultipleSequences(t *testing.T) {
 		}
 	}
 }
+
+func TestGaps(t *testing.T) {
+	// Check that Gaps reports the addresses described by rows with line
+	// number 0 and the addresses past the end of the sequence.
+
+	instr := bytes.NewBuffer(nil)
+	ptrSize := ptrSizeByRuntimeArch()
+
+	write_DW_LNE_set_address := func(addr uint64) {
+		instr.WriteByte(0)
+		util.EncodeULEB128(instr, 9) // 1 + ptr_size
+		instr.WriteByte(DW_LINE_set_address)
+		util.WriteUint(instr, binary.LittleEndian, ptrSize, addr)
+	}
+
+	write_DW_LNS_copy := func() {
+		instr.WriteByte(DW_LNS_copy)
+	}
+
+	write_DW_LNS_advance_pc := func(off uint64) {
+		instr.WriteByte(DW_LNS_advance_pc)
+		util.EncodeULEB128(instr, off)
+	}
+
+	write_DW_LNS_advance_line := func(off int64) {
+		instr.WriteByte(DW_LNS_advance_line)
+		util.EncodeSLEB128(instr, off)
+	}
+
+	write_DW_LNE_end_sequence := func() {
+		instr.WriteByte(0)
+		util.EncodeULEB128(instr, 1)
+		instr.WriteByte(DW_LINE_end_sequence)
+	}
+
+	write_DW_LNE_set_address(0x400000)
+	write_DW_LNS_copy() // thefile.go:1 0x400000
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNS_advance_line(-1)
+	write_DW_LNS_copy() // thefile.go:0 0x400002
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNS_advance_line(3)
+	write_DW_LNS_copy() // thefile.go:3 0x400004
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNS_advance_line(-3)
+	write_DW_LNS_copy() // thefile.go:0 0x400006
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNE_end_sequence() // ends the byte before 0x400008
+
+	lines := &DebugLineInfo{
+		Prologue: &DebugLinePrologue{
+			UnitLength:     1,
+			Version:        2,
+			MinInstrLength: 1,
+			InitialIsStmt:  1,
+			LineBase:       -3,
+			LineRange:      12,
+			OpcodeBase:     13,
+			StdOpLengths:   []uint8{0, 1, 1, 1, 1, 0, 0, 0, 1, 0, 0, 1},
+		},
+		IncludeDirs:       []string{},
+		FileNames:         []*FileEntry{&FileEntry{Path: "thefile.go"}},
+		Instructions:      instr.Bytes(),
+		ptrSize:           ptrSize,
+		stateMachineCache: make(map[uint64]*StateMachine),
+		lastMachineCache:  make(map[uint64]*StateMachine),
+	}
+
+	for _, testCase := range []struct {
+		start, end uint64
+		tgt        [][2]uint64
+	}{
+		{0x400000, 0x400008, [][2]uint64{{0x400002, 0x400004}, {0x400006, 0x400008}}},
+		{0x400000, 0x40000a, [][2]uint64{{0x400002, 0x400004}, {0x400006, 0x40000a}}},
+		{0x400004, 0x400006, nil},
+		{0x400003, 0x400006, [][2]uint64{{0x400003, 0x400004}}},
+	} {
+		out := lines.Gaps(testCase.start, testCase.end)
+		if fmt.Sprintf("%#x", out) != fmt.Sprintf("%#x", testCase.tgt) {
+			t.Errorf("Gaps(%#x, %#x): expected: %#x got: %#x", testCase.start, testCase.end, testCase.tgt, out)
+		}
+	}
+}
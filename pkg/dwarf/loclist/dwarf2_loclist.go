@@ -2,6 +2,7 @@ package loclist
 
 import (
 	"encoding/binary"
+	"fmt"
 
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 )
@@ -9,6 +10,7 @@ import (
 // Reader represents a loclist reader.
 type Reader interface {
 	Find(off int, staticBase, base, pc uint64, debugAddr *godwarf.DebugAddr) (*Entry, error)
+	Entries(off int, staticBase, base uint64, debugAddr *godwarf.DebugAddr) ([]Entry, error)
 	Empty() bool
 }
 
@@ -72,6 +74,25 @@ func (rdr *Dwarf2Reader) Find(off int, staticBase, base, pc uint64, debugAddr *g
 	return nil, nil
 }
 
+// Entries returns all the entries of the loclist starting at off, with
+// their address ranges relocated the same way Find does.
+func (rdr *Dwarf2Reader) Entries(off int, staticBase, base uint64, debugAddr *godwarf.DebugAddr) ([]Entry, error) {
+	if off < 0 || off >= len(rdr.data) {
+		return nil, fmt.Errorf("loclist offset %#x out of bounds", off)
+	}
+	rdr.Seek(off)
+	var r []Entry
+	var e Entry
+	for rdr.Next(&e) {
+		if e.BaseAddressSelection() {
+			base = e.HighPC + staticBase
+			continue
+		}
+		r = append(r, Entry{e.LowPC + base, e.HighPC + base, e.Instr})
+	}
+	return r, nil
+}
+
 func (rdr *Dwarf2Reader) read(sz int) []byte {
 	r := rdr.data[rdr.cur : rdr.cur+sz]
 	rdr.cur += sz
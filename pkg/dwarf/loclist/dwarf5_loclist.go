@@ -14,6 +14,7 @@ import (
 type Dwarf5Reader struct {
 	byteOrder binary.ByteOrder
 	ptrSz     int
+	dwarf64   bool
 	data      []byte
 }
 
@@ -25,6 +26,7 @@ func NewDwarf5Reader(data []byte) *Dwarf5Reader {
 
 	_, dwarf64, _, byteOrder := util.ReadDwarfLengthVersion(data)
 	r.byteOrder = byteOrder
+	r.dwarf64 = dwarf64
 
 	data = data[6:]
 	if dwarf64 {
@@ -46,6 +48,26 @@ func (rdr *Dwarf5Reader) Empty() bool {
 	return rdr == nil
 }
 
+// OffsetOfIndex returns the offset of the location list with index idx in
+// the offset table starting at loclistsBase, the value of the
+// DW_AT_loclists_base attribute of the compile unit. It is used to resolve
+// attributes of form DW_FORM_loclistx.
+func (rdr *Dwarf5Reader) OffsetOfIndex(loclistsBase, idx uint64) (int64, error) {
+	sz := uint64(4)
+	if rdr.dwarf64 {
+		sz = 8
+	}
+	off := loclistsBase + idx*sz
+	if off+sz > uint64(len(rdr.data)) {
+		return 0, fmt.Errorf("location list index %d out of bounds", idx)
+	}
+	n, err := util.ReadUintRaw(bytes.NewReader(rdr.data[off:]), rdr.byteOrder, int(sz))
+	if err != nil {
+		return 0, err
+	}
+	return int64(loclistsBase + n), nil
+}
+
 // Find returns the loclist entry for the specified PC address, inside the
 // loclist stating at off. Base is the base address of the compile unit and
 // staticBase is the static base at which the image is loaded.
@@ -73,6 +95,35 @@ func (rdr *Dwarf5Reader) Find(off int, staticBase, base, pc uint64, debugAddr *g
 	return nil, nil
 }
 
+// Entries returns all the entries of the loclist starting at off, with
+// their address ranges relocated the same way Find does. The default
+// location, if present, is returned as an entry covering the whole address
+// space.
+func (rdr *Dwarf5Reader) Entries(off int, staticBase, base uint64, debugAddr *godwarf.DebugAddr) ([]Entry, error) {
+	if off < 0 || off >= len(rdr.data) {
+		return nil, fmt.Errorf("loclist offset %#x out of bounds", off)
+	}
+	it := &loclistsIterator{rdr: rdr, debugAddr: debugAddr, buf: bytes.NewBuffer(rdr.data), base: base, staticBase: staticBase}
+	it.buf.Next(off)
+
+	var r []Entry
+	for it.next() {
+		if it.onRange {
+			r = append(r, Entry{it.start, it.end, it.instr})
+		}
+	}
+
+	if it.err != nil {
+		return nil, it.err
+	}
+
+	if it.defaultInstr != nil {
+		r = append(r, Entry{0, ^uint64(0), it.defaultInstr})
+	}
+
+	return r, nil
+}
+
 type loclistsIterator struct {
 	rdr        *Dwarf5Reader
 	debugAddr  *godwarf.DebugAddr
@@ -116,4 +116,41 @@ func TestLoclist5(t *testing.T) {
 			t.Errorf("output mismatch for %#x,\nexpected %#v,\ngot     %#v", tc.pc, tc.tgt, e)
 		}
 	}
+	for _, badoff := range []int{-1, buf.Len(), buf.Len() + 10} {
+		if _, err := ll.Entries(badoff, 0x0, 0x01000000, nil); err == nil {
+			t.Errorf("no error returned for out of bounds offset %d", badoff)
+		}
+	}
+}
+
+func TestLoclist5OffsetOfIndex(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	p32 := func(n uint32) { binary.Write(buf, binary.LittleEndian, n) }
+	p16 := func(n uint16) { binary.Write(buf, binary.LittleEndian, n) }
+	p8 := func(n uint8) { binary.Write(buf, binary.LittleEndian, n) }
+
+	p32(0x0) // length (use 0 because it is ignored)
+	p16(0x5) // version
+	p8(8)    // address size
+	p8(0)    // segment selector size
+	p32(2)   // offset_entry_count
+
+	base := uint64(buf.Len())
+	p32(0x8)  // offset of list 0, relative to base
+	p32(0x10) // offset of list 1, relative to base
+
+	rdr := NewDwarf5Reader(buf.Bytes())
+	for idx, tgt := range []int64{int64(base) + 0x8, int64(base) + 0x10} {
+		off, err := rdr.OffsetOfIndex(base, uint64(idx))
+		if err != nil {
+			t.Fatalf("OffsetOfIndex(%d): %v", idx, err)
+		}
+		if off != tgt {
+			t.Errorf("OffsetOfIndex(%d) = %#x, expected %#x", idx, off, tgt)
+		}
+	}
+	if _, err := rdr.OffsetOfIndex(base, 2); err == nil {
+		t.Errorf("expected error for out of bounds index")
+	}
 }
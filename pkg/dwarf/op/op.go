@@ -19,11 +19,12 @@ type Opcode byte
 type stackfn func(Opcode, *context) error
 
 type context struct {
-	buf     *bytes.Buffer
-	stack   []int64
-	pieces  []Piece
-	reg     bool
-	ptrSize int
+	buf        *bytes.Buffer
+	stack      []int64
+	pieces     []Piece
+	reg        bool
+	stackValue bool
+	ptrSize    int
 
 	DwarfRegisters
 }
@@ -34,8 +35,15 @@ type Piece struct {
 	Addr       int64
 	RegNum     uint64
 	IsRegister bool
+	Value      int64 // value of the piece if IsValue is set
+	IsValue    bool  // the piece doesn't exist in memory, its value was computed by the expression (DW_OP_stack_value)
 }
 
+// ErrEntryValueUnavailable is returned by ExecuteStackProgram when the
+// expression uses DW_OP_entry_value and the value of registers on entry to
+// the current function can not be determined.
+var ErrEntryValueUnavailable = errors.New("entry value not available")
+
 // ExecuteStackProgram executes a DWARF location expression and returns
 // either an address (int64), or a slice of Pieces for location expressions
 // that don't evaluate to an address (such as register and composite expressions).
@@ -71,6 +79,13 @@ func ExecuteStackProgram(regs DwarfRegisters, instructions []byte, ptrSize int)
 		}
 	}
 
+	if ctxt.stackValue {
+		if len(ctxt.stack) == 0 {
+			return 0, nil, errors.New("empty OP stack")
+		}
+		ctxt.pieces = append(ctxt.pieces, Piece{Value: ctxt.stack[len(ctxt.stack)-1], IsValue: true})
+	}
+
 	if ctxt.pieces != nil {
 		if len(ctxt.pieces) == 1 && ctxt.pieces[0].IsRegister {
 			return int64(regs.Uint64Val(ctxt.pieces[0].RegNum)), ctxt.pieces, nil
@@ -206,8 +221,54 @@ func piece(opcode Opcode, ctxt *context) error {
 		return errors.New("empty OP stack")
 	}
 
+	if ctxt.stackValue {
+		ctxt.stackValue = false
+		ctxt.pieces = append(ctxt.pieces, Piece{Size: int(sz), Value: ctxt.stack[len(ctxt.stack)-1], IsValue: true})
+		ctxt.stack = ctxt.stack[:0]
+		return nil
+	}
+
 	addr := ctxt.stack[len(ctxt.stack)-1]
 	ctxt.pieces = append(ctxt.pieces, Piece{Size: int(sz), Addr: addr})
 	ctxt.stack = ctxt.stack[:0]
 	return nil
 }
+
+func literal(opcode Opcode, ctxt *context) error {
+	ctxt.stack = append(ctxt.stack, int64(opcode-DW_OP_lit0))
+	return nil
+}
+
+func bregister(opcode Opcode, ctxt *context) error {
+	var regnum uint64
+	if opcode == DW_OP_bregx {
+		regnum, _ = util.DecodeULEB128(ctxt.buf)
+	} else {
+		regnum = uint64(opcode - DW_OP_breg0)
+	}
+	offset, _ := util.DecodeSLEB128(ctxt.buf)
+	if ctxt.Reg(regnum) == nil {
+		return fmt.Errorf("register %d not available", regnum)
+	}
+	ctxt.stack = append(ctxt.stack, int64(ctxt.Uint64Val(regnum))+offset)
+	return nil
+}
+
+func stackvalue(opcode Opcode, ctxt *context) error {
+	ctxt.stackValue = true
+	return nil
+}
+
+func entryvalue(opcode Opcode, ctxt *context) error {
+	sz, _ := util.DecodeULEB128(ctxt.buf)
+	expr := ctxt.buf.Next(int(sz))
+	if ctxt.EntryValue == nil {
+		return ErrEntryValueUnavailable
+	}
+	val, err := ctxt.EntryValue(expr)
+	if err != nil {
+		return err
+	}
+	ctxt.stack = append(ctxt.stack, val)
+	return nil
+}
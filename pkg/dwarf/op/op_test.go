@@ -23,3 +23,26 @@ func TestExecuteStackProgram(t *testing.T) {
 		t.Fatalf("actual %d != expected %d", actual, expected)
 	}
 }
+
+func TestEntryValue(t *testing.T) {
+	instructions := []byte{byte(DW_OP_entry_value), 1, byte(DW_OP_reg5), byte(DW_OP_stack_value)}
+
+	_, _, err := ExecuteStackProgram(DwarfRegisters{}, instructions, ptrSizeByRuntimeArch())
+	if err != ErrEntryValueUnavailable {
+		t.Fatalf("expected ErrEntryValueUnavailable, got %v", err)
+	}
+
+	regs := DwarfRegisters{EntryValue: func(expr []byte) (int64, error) {
+		if len(expr) != 1 || Opcode(expr[0]) != DW_OP_reg5 {
+			t.Fatalf("unexpected entry value expression %v", expr)
+		}
+		return 42, nil
+	}}
+	_, pieces, err := ExecuteStackProgram(regs, instructions, ptrSizeByRuntimeArch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) != 1 || !pieces[0].IsValue || pieces[0].Value != 42 {
+		t.Fatalf("unexpected pieces %#v", pieces)
+	}
+}
@@ -157,6 +157,8 @@ const (
 	DW_OP_bit_piece           Opcode = 0x9d
 	DW_OP_implicit_value      Opcode = 0x9e
 	DW_OP_stack_value         Opcode = 0x9f
+	DW_OP_entry_value         Opcode = 0xa3
+	DW_OP_GNU_entry_value     Opcode = 0xf3
 )
 
 var opcodeName = map[Opcode]string{
@@ -314,6 +316,8 @@ var opcodeName = map[Opcode]string{
 	DW_OP_bit_piece:           "DW_OP_bit_piece",
 	DW_OP_implicit_value:      "DW_OP_implicit_value",
 	DW_OP_stack_value:         "DW_OP_stack_value",
+	DW_OP_entry_value:         "DW_OP_entry_value",
+	DW_OP_GNU_entry_value:     "DW_OP_GNU_entry_value",
 }
 var opcodeArgs = map[Opcode]string{
 	DW_OP_addr:                "8",
@@ -470,46 +474,116 @@ var opcodeArgs = map[Opcode]string{
 	DW_OP_bit_piece:           "uu",
 	DW_OP_implicit_value:      "B",
 	DW_OP_stack_value:         "",
+	DW_OP_entry_value:         "B",
+	DW_OP_GNU_entry_value:     "B",
 }
 var oplut = map[Opcode]stackfn{
-	DW_OP_addr:           addr,
-	DW_OP_consts:         consts,
-	DW_OP_plus:           plus,
-	DW_OP_plus_uconst:    plusuconsts,
-	DW_OP_reg0:           register,
-	DW_OP_reg1:           register,
-	DW_OP_reg2:           register,
-	DW_OP_reg3:           register,
-	DW_OP_reg4:           register,
-	DW_OP_reg5:           register,
-	DW_OP_reg6:           register,
-	DW_OP_reg7:           register,
-	DW_OP_reg8:           register,
-	DW_OP_reg9:           register,
-	DW_OP_reg10:          register,
-	DW_OP_reg11:          register,
-	DW_OP_reg12:          register,
-	DW_OP_reg13:          register,
-	DW_OP_reg14:          register,
-	DW_OP_reg15:          register,
-	DW_OP_reg16:          register,
-	DW_OP_reg17:          register,
-	DW_OP_reg18:          register,
-	DW_OP_reg19:          register,
-	DW_OP_reg20:          register,
-	DW_OP_reg21:          register,
-	DW_OP_reg22:          register,
-	DW_OP_reg23:          register,
-	DW_OP_reg24:          register,
-	DW_OP_reg25:          register,
-	DW_OP_reg26:          register,
-	DW_OP_reg27:          register,
-	DW_OP_reg28:          register,
-	DW_OP_reg29:          register,
-	DW_OP_reg30:          register,
-	DW_OP_reg31:          register,
-	DW_OP_regx:           register,
-	DW_OP_fbreg:          framebase,
-	DW_OP_piece:          piece,
-	DW_OP_call_frame_cfa: callframecfa,
+	DW_OP_addr:            addr,
+	DW_OP_consts:          consts,
+	DW_OP_plus:            plus,
+	DW_OP_plus_uconst:     plusuconsts,
+	DW_OP_lit0:            literal,
+	DW_OP_lit1:            literal,
+	DW_OP_lit2:            literal,
+	DW_OP_lit3:            literal,
+	DW_OP_lit4:            literal,
+	DW_OP_lit5:            literal,
+	DW_OP_lit6:            literal,
+	DW_OP_lit7:            literal,
+	DW_OP_lit8:            literal,
+	DW_OP_lit9:            literal,
+	DW_OP_lit10:           literal,
+	DW_OP_lit11:           literal,
+	DW_OP_lit12:           literal,
+	DW_OP_lit13:           literal,
+	DW_OP_lit14:           literal,
+	DW_OP_lit15:           literal,
+	DW_OP_lit16:           literal,
+	DW_OP_lit17:           literal,
+	DW_OP_lit18:           literal,
+	DW_OP_lit19:           literal,
+	DW_OP_lit20:           literal,
+	DW_OP_lit21:           literal,
+	DW_OP_lit22:           literal,
+	DW_OP_lit23:           literal,
+	DW_OP_lit24:           literal,
+	DW_OP_lit25:           literal,
+	DW_OP_lit26:           literal,
+	DW_OP_lit27:           literal,
+	DW_OP_lit28:           literal,
+	DW_OP_lit29:           literal,
+	DW_OP_lit30:           literal,
+	DW_OP_lit31:           literal,
+	DW_OP_reg0:            register,
+	DW_OP_reg1:            register,
+	DW_OP_reg2:            register,
+	DW_OP_reg3:            register,
+	DW_OP_reg4:            register,
+	DW_OP_reg5:            register,
+	DW_OP_reg6:            register,
+	DW_OP_reg7:            register,
+	DW_OP_reg8:            register,
+	DW_OP_reg9:            register,
+	DW_OP_reg10:           register,
+	DW_OP_reg11:           register,
+	DW_OP_reg12:           register,
+	DW_OP_reg13:           register,
+	DW_OP_reg14:           register,
+	DW_OP_reg15:           register,
+	DW_OP_reg16:           register,
+	DW_OP_reg17:           register,
+	DW_OP_reg18:           register,
+	DW_OP_reg19:           register,
+	DW_OP_reg20:           register,
+	DW_OP_reg21:           register,
+	DW_OP_reg22:           register,
+	DW_OP_reg23:           register,
+	DW_OP_reg24:           register,
+	DW_OP_reg25:           register,
+	DW_OP_reg26:           register,
+	DW_OP_reg27:           register,
+	DW_OP_reg28:           register,
+	DW_OP_reg29:           register,
+	DW_OP_reg30:           register,
+	DW_OP_reg31:           register,
+	DW_OP_breg0:           bregister,
+	DW_OP_breg1:           bregister,
+	DW_OP_breg2:           bregister,
+	DW_OP_breg3:           bregister,
+	DW_OP_breg4:           bregister,
+	DW_OP_breg5:           bregister,
+	DW_OP_breg6:           bregister,
+	DW_OP_breg7:           bregister,
+	DW_OP_breg8:           bregister,
+	DW_OP_breg9:           bregister,
+	DW_OP_breg10:          bregister,
+	DW_OP_breg11:          bregister,
+	DW_OP_breg12:          bregister,
+	DW_OP_breg13:          bregister,
+	DW_OP_breg14:          bregister,
+	DW_OP_breg15:          bregister,
+	DW_OP_breg16:          bregister,
+	DW_OP_breg17:          bregister,
+	DW_OP_breg18:          bregister,
+	DW_OP_breg19:          bregister,
+	DW_OP_breg20:          bregister,
+	DW_OP_breg21:          bregister,
+	DW_OP_breg22:          bregister,
+	DW_OP_breg23:          bregister,
+	DW_OP_breg24:          bregister,
+	DW_OP_breg25:          bregister,
+	DW_OP_breg26:          bregister,
+	DW_OP_breg27:          bregister,
+	DW_OP_breg28:          bregister,
+	DW_OP_breg29:          bregister,
+	DW_OP_breg30:          bregister,
+	DW_OP_breg31:          bregister,
+	DW_OP_regx:            register,
+	DW_OP_fbreg:           framebase,
+	DW_OP_bregx:           bregister,
+	DW_OP_piece:           piece,
+	DW_OP_call_frame_cfa:  callframecfa,
+	DW_OP_stack_value:     stackvalue,
+	DW_OP_entry_value:     entryvalue,
+	DW_OP_GNU_entry_value: entryvalue,
 }
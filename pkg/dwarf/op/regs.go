@@ -22,6 +22,11 @@ type DwarfRegisters struct {
 
 	FloatLoadError   error // error produced when loading floating point registers
 	loadMoreCallback func()
+
+	// EntryValue, if set, returns the value that the DWARF expression expr,
+	// which describes a register, had on entry to the current function. It
+	// is used to evaluate DW_OP_entry_value.
+	EntryValue func(expr []byte) (int64, error)
 }
 
 type DwarfRegister struct {
@@ -2,6 +2,8 @@ package util
 
 import (
 	"bytes"
+	"debug/dwarf"
+	"reflect"
 	"testing"
 )
 
@@ -71,3 +73,27 @@ func TestParseString(t *testing.T) {
 		t.Fatalf("String was not parsed correctly %#v", str)
 	}
 }
+
+func TestReadUnitVersions(t *testing.T) {
+	data := []byte{
+		// DWARFv4 compile unit
+		0x08, 0x00, 0x00, 0x00, // unit_length
+		0x04, 0x00, // version
+		0x00, 0x00, 0x00, 0x00, // debug_abbrev_offset
+		0x08, // address_size
+		0x00, // first entry
+
+		// DWARFv5 compile unit
+		0x09, 0x00, 0x00, 0x00, // unit_length
+		0x05, 0x00, // version
+		_DW_UT_compile,         // unit_type
+		0x08,                   // address_size
+		0x00, 0x00, 0x00, 0x00, // debug_abbrev_offset
+		0x00, // first entry
+	}
+	tgt := map[dwarf.Offset]uint8{11: 4, 24: 5}
+	out := ReadUnitVersions(data)
+	if !reflect.DeepEqual(out, tgt) {
+		t.Fatalf("wrong unit versions: %v (expected %v)", out, tgt)
+	}
+}
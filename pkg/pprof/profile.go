@@ -0,0 +1,279 @@
+// Package pprof writes profiles in the format read by 'go tool pprof'.
+//
+// The format is described by profile.proto in
+// github.com/google/pprof/proto. Only the subset of the format that Delve
+// needs is implemented and the protocol buffer encoding is done by hand to
+// avoid pulling in protobuf dependencies.
+package pprof
+
+import (
+	"compress/gzip"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// Profile is an in-memory representation of profile.proto.
+type Profile struct {
+	SampleType        []ValueType
+	DefaultSampleType string
+	Sample            []*Sample
+	Mapping           []*Mapping
+	Location          []*Location
+	Function          []*Function
+	Comments          []string
+
+	TimeNanos     int64
+	DurationNanos int64
+	PeriodType    ValueType
+	Period        int64
+}
+
+// ValueType describes the semantics and measurement units of a value.
+type ValueType struct {
+	Type string // for example "cpu" or "goroutine"
+	Unit string // for example "nanoseconds" or "count"
+}
+
+// Sample is a set of values associated with a call stack.
+type Sample struct {
+	// Location is the call stack of the sample, with the leaf frame first.
+	Location []*Location
+	// Value has one entry for each element of Profile.SampleType.
+	Value    []int64
+	Label    map[string][]string
+	NumLabel map[string][]int64
+}
+
+// Mapping describes an address range of the target that is backed by an
+// executable file.
+type Mapping struct {
+	ID              uint64
+	Start, Limit    uint64
+	Offset          uint64
+	File            string
+	BuildID         string
+	HasFunctions    bool
+	HasFilenames    bool
+	HasLineNumbers  bool
+	HasInlineFrames bool
+}
+
+// Location describes a single instruction address.
+type Location struct {
+	ID      uint64
+	Mapping *Mapping
+	Address uint64
+	// Line lists the source lines for this instruction, if it is part of an
+	// inlined call the innermost function is listed first.
+	Line []Line
+}
+
+// Line is a source line corresponding to a Location.
+type Line struct {
+	Function *Function
+	Line     int64
+}
+
+// Function describes a function in the target.
+type Function struct {
+	ID         uint64
+	Name       string
+	SystemName string
+	Filename   string
+	StartLine  int64
+}
+
+// Field numbers of the messages defined in profile.proto.
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileMapping           = 3
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileTimeNanos         = 9
+	profileDurationNanos     = 10
+	profilePeriodType        = 11
+	profilePeriod            = 12
+	profileComment           = 13
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+	sampleLabel      = 3
+
+	labelKey = 1
+	labelStr = 2
+	labelNum = 3
+
+	mappingID              = 1
+	mappingStart           = 2
+	mappingLimit           = 3
+	mappingOffset          = 4
+	mappingFilename        = 5
+	mappingBuildID         = 6
+	mappingHasFunctions    = 7
+	mappingHasFilenames    = 8
+	mappingHasLineNumbers  = 9
+	mappingHasInlineFrames = 10
+
+	locationID        = 1
+	locationMappingID = 2
+	locationAddress   = 3
+	locationLine      = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+	functionStartLine  = 5
+)
+
+// Write writes p to w as a gzip compressed protocol buffer.
+func (p *Profile) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(p.Marshal()); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// Marshal returns the uncompressed protocol buffer encoding of p.
+func (p *Profile) Marshal() []byte {
+	st := &stringTable{index: map[string]int64{"": 0}, strs: []string{""}}
+	var b buffer
+
+	for _, vt := range p.SampleType {
+		b.message(profileSampleType, func(b *buffer) { vt.encode(b, st) })
+	}
+
+	for _, s := range p.Sample {
+		b.message(profileSample, func(b *buffer) {
+			ids := make([]uint64, len(s.Location))
+			for i := range s.Location {
+				ids[i] = s.Location[i].ID
+			}
+			b.packedUint64s(sampleLocationID, ids)
+			b.packedInt64s(sampleValue, s.Value)
+			for _, k := range sortedKeys(s.Label) {
+				for _, v := range s.Label[k] {
+					b.message(sampleLabel, func(b *buffer) {
+						b.int64Opt(labelKey, st.get(k))
+						b.int64Opt(labelStr, st.get(v))
+					})
+				}
+			}
+			for _, k := range sortedKeys(s.NumLabel) {
+				for _, v := range s.NumLabel[k] {
+					b.message(sampleLabel, func(b *buffer) {
+						b.int64Opt(labelKey, st.get(k))
+						b.int64Opt(labelNum, v)
+					})
+				}
+			}
+		})
+	}
+
+	for _, m := range p.Mapping {
+		b.message(profileMapping, func(b *buffer) {
+			b.uint64Opt(mappingID, m.ID)
+			b.uint64Opt(mappingStart, m.Start)
+			b.uint64Opt(mappingLimit, m.Limit)
+			b.uint64Opt(mappingOffset, m.Offset)
+			b.int64Opt(mappingFilename, st.get(m.File))
+			b.int64Opt(mappingBuildID, st.get(m.BuildID))
+			b.boolOpt(mappingHasFunctions, m.HasFunctions)
+			b.boolOpt(mappingHasFilenames, m.HasFilenames)
+			b.boolOpt(mappingHasLineNumbers, m.HasLineNumbers)
+			b.boolOpt(mappingHasInlineFrames, m.HasInlineFrames)
+		})
+	}
+
+	for _, l := range p.Location {
+		b.message(profileLocation, func(b *buffer) {
+			b.uint64Opt(locationID, l.ID)
+			if l.Mapping != nil {
+				b.uint64Opt(locationMappingID, l.Mapping.ID)
+			}
+			b.uint64Opt(locationAddress, l.Address)
+			for _, ln := range l.Line {
+				b.message(locationLine, func(b *buffer) {
+					if ln.Function != nil {
+						b.uint64Opt(lineFunctionID, ln.Function.ID)
+					}
+					b.int64Opt(lineLine, ln.Line)
+				})
+			}
+		})
+	}
+
+	for _, f := range p.Function {
+		b.message(profileFunction, func(b *buffer) {
+			b.uint64Opt(functionID, f.ID)
+			b.int64Opt(functionName, st.get(f.Name))
+			b.int64Opt(functionSystemName, st.get(f.SystemName))
+			b.int64Opt(functionFilename, st.get(f.Filename))
+			b.int64Opt(functionStartLine, f.StartLine)
+		})
+	}
+
+	b.int64Opt(profileTimeNanos, p.TimeNanos)
+	b.int64Opt(profileDurationNanos, p.DurationNanos)
+	if p.PeriodType.Type != "" || p.PeriodType.Unit != "" {
+		b.message(profilePeriodType, func(b *buffer) { p.PeriodType.encode(b, st) })
+	}
+	b.int64Opt(profilePeriod, p.Period)
+	for _, c := range p.Comments {
+		b.int64(profileComment, st.get(c))
+	}
+	b.int64Opt(profileDefaultSampleType, st.get(p.DefaultSampleType))
+
+	// The string table must be encoded last, after every string used by the
+	// profile has been added to it, its first entry is always "".
+	for _, s := range st.strs {
+		b.string(profileStringTable, s)
+	}
+
+	return b.data
+}
+
+func (vt ValueType) encode(b *buffer, st *stringTable) {
+	b.int64Opt(valueTypeType, st.get(vt.Type))
+	b.int64Opt(valueTypeUnit, st.get(vt.Unit))
+}
+
+// sortedKeys returns the sorted keys of m, a map with string keys, like
+// Sample.Label and Sample.NumLabel.
+func sortedKeys(m interface{}) []string {
+	mv := reflect.ValueOf(m)
+	keys := make([]string, 0, mv.Len())
+	for _, k := range mv.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// stringTable maps strings to their index in Profile.string_table.
+type stringTable struct {
+	index map[string]int64
+	strs  []string
+}
+
+func (st *stringTable) get(s string) int64 {
+	if i, ok := st.index[s]; ok {
+		return i
+	}
+	i := int64(len(st.strs))
+	st.index[s] = i
+	st.strs = append(st.strs, s)
+	return i
+}
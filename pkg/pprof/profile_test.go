@@ -0,0 +1,169 @@
+package pprof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+)
+
+type field struct {
+	num  int
+	wire int
+	x    uint64
+	b    []byte
+}
+
+func decodeVarint(t *testing.T, buf []byte) (uint64, []byte) {
+	var x uint64
+	for shift := uint(0); ; shift += 7 {
+		if len(buf) == 0 {
+			t.Fatal("truncated varint")
+		}
+		c := buf[0]
+		buf = buf[1:]
+		x |= uint64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return x, buf
+		}
+	}
+}
+
+func decodeFields(t *testing.T, buf []byte) []field {
+	var r []field
+	for len(buf) > 0 {
+		var k uint64
+		k, buf = decodeVarint(t, buf)
+		f := field{num: int(k >> 3), wire: int(k & 7)}
+		switch f.wire {
+		case wireVarint:
+			f.x, buf = decodeVarint(t, buf)
+		case wireBytes:
+			var n uint64
+			n, buf = decodeVarint(t, buf)
+			f.b, buf = buf[:n], buf[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", f.wire)
+		}
+		r = append(r, f)
+	}
+	return r
+}
+
+func TestProfileEncoding(t *testing.T) {
+	fn := &Function{ID: 1, Name: "main.main", SystemName: "main.main", Filename: "/tmp/main.go", StartLine: 3}
+	loc := &Location{ID: 1, Address: 0x401000, Line: []Line{{Function: fn, Line: 10}}}
+	p := &Profile{
+		SampleType: []ValueType{{Type: "goroutine", Unit: "count"}},
+		Sample: []*Sample{
+			{Location: []*Location{loc}, Value: []int64{1}, Label: map[string][]string{"waitreason": {"chan receive"}}},
+		},
+		Location: []*Location{loc},
+		Function: []*Function{fn},
+	}
+
+	var out bytes.Buffer
+	if err := p.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var strs []string
+	var samples, locs, fns [][]byte
+	for _, f := range decodeFields(t, buf) {
+		switch f.num {
+		case profileStringTable:
+			strs = append(strs, string(f.b))
+		case profileSample:
+			samples = append(samples, f.b)
+		case profileLocation:
+			locs = append(locs, f.b)
+		case profileFunction:
+			fns = append(fns, f.b)
+		}
+	}
+
+	if len(strs) == 0 || strs[0] != "" {
+		t.Fatalf("string table must start with the empty string: %q", strs)
+	}
+	if len(samples) != 1 || len(locs) != 1 || len(fns) != 1 {
+		t.Fatalf("wrong number of messages: %d samples %d locations %d functions", len(samples), len(locs), len(fns))
+	}
+
+	str := func(i uint64) string {
+		if int(i) >= len(strs) {
+			t.Fatalf("string index %d out of range", i)
+		}
+		return strs[i]
+	}
+
+	var gotLabel [2]string
+	for _, f := range decodeFields(t, samples[0]) {
+		switch f.num {
+		case sampleLocationID:
+			if x, _ := decodeVarint(t, f.b); x != loc.ID {
+				t.Errorf("wrong location id %d", x)
+			}
+		case sampleValue:
+			if x, _ := decodeVarint(t, f.b); x != 1 {
+				t.Errorf("wrong value %d", x)
+			}
+		case sampleLabel:
+			for _, lf := range decodeFields(t, f.b) {
+				switch lf.num {
+				case labelKey:
+					gotLabel[0] = str(lf.x)
+				case labelStr:
+					gotLabel[1] = str(lf.x)
+				}
+			}
+		}
+	}
+	if gotLabel != [2]string{"waitreason", "chan receive"} {
+		t.Errorf("wrong label %q", gotLabel)
+	}
+
+	for _, f := range decodeFields(t, fns[0]) {
+		switch f.num {
+		case functionName:
+			if str(f.x) != fn.Name {
+				t.Errorf("wrong function name %q", str(f.x))
+			}
+		case functionFilename:
+			if str(f.x) != fn.Filename {
+				t.Errorf("wrong file name %q", str(f.x))
+			}
+		case functionStartLine:
+			if int64(f.x) != fn.StartLine {
+				t.Errorf("wrong start line %d", f.x)
+			}
+		}
+	}
+
+	var lineFound bool
+	for _, f := range decodeFields(t, locs[0]) {
+		switch f.num {
+		case locationAddress:
+			if f.x != loc.Address {
+				t.Errorf("wrong address %#x", f.x)
+			}
+		case locationLine:
+			lineFound = true
+			for _, lf := range decodeFields(t, f.b) {
+				if lf.num == lineLine && lf.x != 10 {
+					t.Errorf("wrong line %d", lf.x)
+				}
+			}
+		}
+	}
+	if !lineFound {
+		t.Errorf("line missing from location")
+	}
+}
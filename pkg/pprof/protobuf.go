@@ -0,0 +1,90 @@
+package pprof
+
+// Wire types of the protocol buffer encoding, see:
+// https://developers.google.com/protocol-buffers/docs/encoding
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// buffer is a minimal protocol buffer encoder.
+type buffer struct {
+	data []byte
+}
+
+func (b *buffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *buffer) key(field, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *buffer) uint64(field int, x uint64) {
+	b.key(field, wireVarint)
+	b.varint(x)
+}
+
+func (b *buffer) uint64Opt(field int, x uint64) {
+	if x != 0 {
+		b.uint64(field, x)
+	}
+}
+
+func (b *buffer) int64(field int, x int64) {
+	b.uint64(field, uint64(x))
+}
+
+func (b *buffer) int64Opt(field int, x int64) {
+	if x != 0 {
+		b.int64(field, x)
+	}
+}
+
+func (b *buffer) boolOpt(field int, x bool) {
+	if x {
+		b.uint64(field, 1)
+	}
+}
+
+func (b *buffer) string(field int, s string) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(s)))
+	b.data = append(b.data, s...)
+}
+
+func (b *buffer) packedUint64s(field int, xs []uint64) {
+	if len(xs) == 0 {
+		return
+	}
+	b.message(field, func(b *buffer) {
+		for _, x := range xs {
+			b.varint(x)
+		}
+	})
+}
+
+func (b *buffer) packedInt64s(field int, xs []int64) {
+	if len(xs) == 0 {
+		return
+	}
+	b.message(field, func(b *buffer) {
+		for _, x := range xs {
+			b.varint(uint64(x))
+		}
+	})
+}
+
+// message encodes a length delimited field, the contents of the field are
+// written by the encode function.
+func (b *buffer) message(field int, encode func(*buffer)) {
+	var sub buffer
+	encode(&sub)
+	b.key(field, wireBytes)
+	b.varint(uint64(len(sub.data)))
+	b.data = append(b.data, sub.data...)
+}
@@ -12,15 +12,19 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"hash/crc32"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-delve/delve/pkg/debuginfod"
 	"github.com/go-delve/delve/pkg/dwarf/frame"
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/line"
@@ -35,9 +39,10 @@ import (
 )
 
 const (
-	dwarfGoLanguage    = 22   // DW_LANG_Go (from DWARF v5, section 7.12, page 231)
-	dwarfAttrAddrBase  = 0x74 // debug/dwarf.AttrAddrBase in Go 1.14, defined here for compatibility with Go < 1.14
-	dwarfTreeCacheSize = 512  // size of the dwarfTree cache of each image
+	dwarfGoLanguage       = 22   // DW_LANG_Go (from DWARF v5, section 7.12, page 231)
+	dwarfAttrAddrBase     = 0x74 // debug/dwarf.AttrAddrBase in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfAttrLoclistsBase = 0x8c // debug/dwarf.AttrLoclistsBase in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfTreeCacheSize    = 512  // size of the dwarfTree cache of each image
 )
 
 // BinaryInfo holds information on the binaries being executed (this
@@ -51,6 +56,12 @@ type BinaryInfo struct {
 
 	debugInfoDirectories []string
 
+	// debuginfod is used to download debug info and source files that can't
+	// be found locally, it is created by debuginfodClient the first time it
+	// is needed and it is nil if no debuginfod server is configured.
+	debuginfod     *debuginfod.Client
+	debuginfodOnce sync.Once
+
 	// Functions is a list of all DW_TAG_subprogram entries in debug_info, sorted by entry point
 	Functions []Function
 	// Sources is a list of all source files found in debug_line.
@@ -98,11 +109,20 @@ type BinaryInfo struct {
 	// consts[off] lists all the constants with the type defined at offset off.
 	consts constantsMap
 
+	// waitReasons is a copy of runtime.waitReasonStrings read from the
+	// target, see (*BinaryInfo).waitReasonStrings.
+	waitReasons   []string
+	waitReasonsMu sync.Mutex
+
 	// inlinedCallLines maps a file:line pair, corresponding to the header line
 	// of a function to a list of PC addresses where an inlined call to that
 	// function starts.
 	inlinedCallLines map[fileLine][]uint64
 
+	// cacheConfig is the configuration of the on-disk cache of debug
+	// information.
+	cacheConfig BinaryInfoCacheConfig
+
 	logger *logrus.Entry
 }
 
@@ -189,7 +209,7 @@ func FindFunctionLocation(p Process, funcName string, lineOffset int) ([]uint64,
 		}
 		return r, nil
 	}
-	filename, lineno := origfn.cu.lineInfo.PCToLine(origfn.Entry, origfn.Entry)
+	filename, lineno := origfn.cu.lineInfo().PCToLine(origfn.Entry, origfn.Entry)
 	return bi.LineToPC(filename, lineno+lineOffset)
 }
 
@@ -198,12 +218,12 @@ func FindFunctionLocation(p Process, funcName string, lineOffset int) ([]uint64,
 // If sameline is set FirstPCAfterPrologue will always return an
 // address associated with the same line as fn.Entry.
 func FirstPCAfterPrologue(p Process, fn *Function, sameline bool) (uint64, error) {
-	pc, _, line, ok := fn.cu.lineInfo.PrologueEndPC(fn.Entry, fn.End)
+	pc, _, line, ok := fn.cu.lineInfo().PrologueEndPC(fn.Entry, fn.End)
 	if ok {
 		if !sameline {
 			return pc, nil
 		}
-		_, entryLine := fn.cu.lineInfo.PCToLine(fn.Entry, fn.Entry)
+		_, entryLine := fn.cu.lineInfo().PCToLine(fn.Entry, fn.Entry)
 		if entryLine == line {
 			return pc, nil
 		}
@@ -218,7 +238,7 @@ func FirstPCAfterPrologue(p Process, fn *Function, sameline bool) (uint64, error
 		// Look for the first instruction with the stmt flag set, so that setting a
 		// breakpoint with file:line and with the function name always result on
 		// the same instruction being selected.
-		if pc2, _, _, ok := fn.cu.lineInfo.FirstStmtForLine(fn.Entry, fn.End); ok {
+		if pc2, _, _, ok := fn.cu.lineInfo().FirstStmtForLine(fn.Entry, fn.End); ok {
 			return pc2, nil
 		}
 	}
@@ -274,17 +294,45 @@ type compileUnit struct {
 	lowPC   uint64
 	ranges  [][2]uint64
 
-	entry     *dwarf.Entry        // debug_info entry describing this compile unit
-	isgo      bool                // true if this is the go compile unit
-	lineInfo  *line.DebugLineInfo // debug_line segment associated with this compile unit
-	optimized bool                // this compile unit is optimized
-	producer  string              // producer attribute
+	entry     *dwarf.Entry // debug_info entry describing this compile unit
+	isgo      bool         // true if this is the go compile unit
+	lineTable *cuLineTable // debug_line segment associated with this compile unit, nil if it doesn't have one
+	optimized bool         // this compile unit is optimized
+	producer  string       // producer attribute
 
 	offset dwarf.Offset // offset of the entry describing the compile unit
 
 	image *Image // parent image of this compilation unit.
 }
 
+// cuLineTable is the debug_line segment of a compile unit. The directory
+// and file tables are read when the compile unit is loaded, the line
+// number program is only parsed the first time it is used.
+type cuLineTable struct {
+	data               []byte // debug_line section, starting at the segment
+	includeDirs        []string
+	fileNames          []*line.FileEntry
+	instructionsOffset int
+	normalizeBackslash bool
+	ptrSize            int
+
+	once sync.Once
+	info *line.DebugLineInfo
+}
+
+// lineInfo returns the line table of cu, parsing it on first use, or nil
+// if cu doesn't have one.
+func (cu *compileUnit) lineInfo() *line.DebugLineInfo {
+	lt := cu.lineTable
+	if lt == nil {
+		return nil
+	}
+	lt.once.Do(func() {
+		lt.info = line.ParseCached(lt.data, lt.includeDirs, lt.fileNames, lt.instructionsOffset, lineInfoLogf(), cu.image.StaticBase, lt.normalizeBackslash, lt.ptrSize)
+	})
+	return lt.info
+}
+
 type fileLine struct {
 	file string
 	line int
@@ -313,6 +361,14 @@ type Function struct {
 	InlinedCalls []InlinedCall
 }
 
+// imageIndex returns the index of the image containing fn.
+func (fn *Function) imageIndex() int {
+	if fn.cu == nil || fn.cu.image == nil {
+		return 0
+	}
+	return fn.cu.image.index
+}
+
 // PackageName returns the package part of the symbol name,
 // or the empty string if there is none.
 // Borrowed from $GOROOT/debug/gosym/symtab.go
@@ -364,7 +420,7 @@ func (fn *Function) Optimized() bool {
 
 // PrologueEndPC returns the PC just after the function prologue
 func (fn *Function) PrologueEndPC() uint64 {
-	pc, _, _, ok := fn.cu.lineInfo.PrologueEndPC(fn.Entry, fn.End)
+	pc, _, _, ok := fn.cu.lineInfo().PrologueEndPC(fn.Entry, fn.End)
 	if !ok {
 		return fn.Entry
 	}
@@ -477,6 +533,31 @@ func (bi *BinaryInfo) LastModified() time.Time {
 	return bi.lastModified
 }
 
+// SourceFileEntry returns the line table entry for file that records its
+// size or checksum. If no compile unit records them the first entry
+// found is returned, nil if file is not referenced by any line table.
+func (bi *BinaryInfo) SourceFileEntry(file string) *line.FileEntry {
+	var r *line.FileEntry
+	for _, image := range bi.Images {
+		for _, cu := range image.compileUnits {
+			if cu.lineTable == nil {
+				continue
+			}
+			entry := cu.lineInfo().Lookup[file]
+			if entry == nil {
+				continue
+			}
+			if entry.MD5 != nil || entry.Length != 0 {
+				return entry
+			}
+			if r == nil {
+				r = entry
+			}
+		}
+	}
+	return r
+}
+
 // DwarfReader returns a reader for the dwarf data
 func (so *Image) DwarfReader() *reader.Reader {
 	return reader.New(so.dwarf)
@@ -497,7 +578,7 @@ func (bi *BinaryInfo) PCToLine(pc uint64) (string, int, *Function) {
 	if fn == nil {
 		return "", 0, nil
 	}
-	f, ln := fn.cu.lineInfo.PCToLine(fn.Entry, pc)
+	f, ln := fn.cu.lineInfo().PCToLine(fn.Entry, pc)
 	return f, ln, fn
 }
 
@@ -523,11 +604,11 @@ func (bi *BinaryInfo) LineToPC(filename string, lineno int) (pcs []uint64, err e
 pcsearch:
 	for _, image := range bi.Images {
 		for _, cu := range image.compileUnits {
-			if cu.lineInfo == nil || cu.lineInfo.Lookup[filename] == nil {
+			if cu.lineTable == nil || cu.lineInfo().Lookup[filename] == nil {
 				continue
 			}
 			fileFound = true
-			pc = cu.lineInfo.LineToPC(filename, lineno)
+			pc = cu.lineInfo().LineToPC(filename, lineno)
 			if pc != 0 {
 				break pcsearch
 			}
@@ -570,7 +651,7 @@ func appendLineToPCIn(pcs []uint64, filename string, lineno int, cu *compileUnit
 	if containingFn != nil {
 		entry = containingFn.Entry
 	}
-	pc := cu.lineInfo.LineToPCIn(filename, lineno, entry, lowPC, highPC)
+	pc := cu.lineInfo().LineToPCIn(filename, lineno, entry, lowPC, highPC)
 	if pc != 0 {
 		return append(pcs, pc)
 	}
@@ -585,8 +666,8 @@ func (bi *BinaryInfo) AllPCsForFileLines(filename string, linenos []int) map[int
 	}
 	for _, image := range bi.Images {
 		for _, cu := range image.compileUnits {
-			if cu.lineInfo != nil && cu.lineInfo.Lookup[filename] != nil {
-				cu.lineInfo.AllPCsForFileLines(filename, r)
+			if cu.lineTable != nil && cu.lineInfo().Lookup[filename] != nil {
+				cu.lineInfo().AllPCsForFileLines(filename, r)
 			}
 		}
 	}
@@ -630,6 +711,42 @@ func (bi *BinaryInfo) PCToInlineFunc(pc uint64) *Function {
 	return bi.LookupFunc[fnname]
 }
 
+// PCToInlineStack returns the source locations of the given PC address,
+// starting with the innermost inlined call containing it and ending with
+// the function where all inlined calls were inlined.
+// The Fn field of the locations for inlined calls is the inlined function,
+// if it can be found.
+// Returns nil if pc does not belong to any function.
+func (bi *BinaryInfo) PCToInlineStack(pc uint64) []Location {
+	file, line, fn := bi.PCToLine(pc)
+	if fn == nil {
+		return nil
+	}
+	var r []Location
+	dwarfTree, err := fn.cu.image.getDwarfTree(fn.offset)
+	if err == nil && fn.cu.lineTable != nil {
+		for _, entry := range reader.InlineStack(dwarfTree, pc) {
+			fnname, okname := entry.Val(dwarf.AttrName).(string)
+			fileidx, okfileidx := entry.Val(dwarf.AttrCallFile).(int64)
+			callline, okline := entry.Val(dwarf.AttrCallLine).(int64)
+			if !okname || !okfileidx || !okline {
+				break
+			}
+			if fileidx-1 < 0 || fileidx-1 >= int64(len(fn.cu.lineTable.fileNames)) {
+				break
+			}
+			inlfn := bi.LookupFunc[fnname]
+			if inlfn == nil {
+				inlfn = &Function{Name: fnname, offset: entry.Offset, cu: fn.cu}
+			}
+			r = append(r, Location{PC: pc, File: file, Line: line, Fn: inlfn})
+			file = fn.cu.lineTable.fileNames[fileidx-1].Path
+			line = int(callline)
+		}
+	}
+	return append(r, Location{PC: pc, File: file, Line: line, Fn: fn})
+}
+
 // PCToImage returns the image containing the given PC address.
 func (bi *BinaryInfo) PCToImage(pc uint64) *Image {
 	fn := bi.PCToFunc(pc)
@@ -640,8 +757,14 @@ func (bi *BinaryInfo) PCToImage(pc uint64) *Image {
 type Image struct {
 	Path       string
 	StaticBase uint64
+	BuildID    string // GNU build-id of the image, as a hex string
+	PluginPath string // package path of the main package of a Go plugin, empty for other images
 	addr       uint64
 
+	// textStart and textEnd are the bounds of the text section of the
+	// image, they are used to match it with its runtime.moduledata.
+	textStart, textEnd uint64
+
 	index int // index of this object in BinaryInfo.SharedObjects
 
 	closer         io.Closer
@@ -653,6 +776,11 @@ type Image struct {
 	loclist5    *loclist.Dwarf5Reader
 	debugAddr   *godwarf.DebugAddrSection
 
+	// ehFrameEntries are the frame descriptor entries read from .eh_frame,
+	// they are used for functions that aren't described by .debug_frame,
+	// for example C functions in shared libraries without debug info.
+	ehFrameEntries frame.FrameDescriptionEntries
+
 	typeCache map[dwarf.Offset]godwarf.Type
 
 	compileUnits []*compileUnit // compileUnits is sorted by increasing DWARF offset
@@ -669,14 +797,6 @@ type Image struct {
 	loadErr   error
 }
 
-func (image *Image) registerRuntimeTypeToDIE(entry *dwarf.Entry, ardr *reader.Reader) {
-	if off, ok := entry.Val(godwarf.AttrGoRuntimeType).(uint64); ok {
-		if _, ok := image.runtimeTypeToDIE[off]; !ok {
-			image.runtimeTypeToDIE[off+image.StaticBase] = runtimeTypeDIE{entry.Offset, -1}
-		}
-	}
-}
-
 // AddImage adds the specified image to bi, loading data asynchronously.
 // Addr is the relocated entry point for the executable and staticBase (i.e.
 // the relocation offset) for all other images.
@@ -706,17 +826,22 @@ func (bi *BinaryInfo) AddImage(path string, addr uint64) error {
 	return err
 }
 
-// moduleDataToImage finds the image corresponding to the given module data object.
-func (bi *BinaryInfo) moduleDataToImage(md *moduleData) *Image {
-	return bi.funcToImage(bi.PCToFunc(uint64(md.text)))
+// textToImage returns the image whose text section contains the address
+// text, or nil if there is none.
+func (bi *BinaryInfo) textToImage(text uint64) *Image {
+	for _, image := range bi.Images {
+		if text >= image.textStart && text < image.textEnd {
+			return image
+		}
+	}
+	return nil
 }
 
 // imageToModuleData finds the module data in mds corresponding to the given image.
 func (bi *BinaryInfo) imageToModuleData(image *Image, mds []moduleData) *moduleData {
-	for _, md := range mds {
-		im2 := bi.moduleDataToImage(&md)
-		if im2.index == image.index {
-			return &md
+	for i := range mds {
+		if mds[i].image == image {
+			return &mds[i]
 		}
 	}
 	return nil
@@ -808,7 +933,7 @@ func (bi *BinaryInfo) LoadImageFromData(dwdata *dwarf.Data, debugFrameBytes, deb
 	image.dwarfTreeCache, _ = simplelru.NewLRU(dwarfTreeCacheSize, nil)
 
 	if debugFrameBytes != nil {
-		bi.frameEntries = frame.Parse(debugFrameBytes, frame.DwarfEndian(debugFrameBytes), 0, bi.Arch.PtrSize())
+		bi.frameEntries, _ = frame.Parse(debugFrameBytes, frame.DwarfEndian(debugFrameBytes), 0, bi.Arch.PtrSize())
 	}
 
 	image.loclist2 = loclist.NewDwarf2Reader(debugLocBytes, bi.Arch.PtrSize())
@@ -822,7 +947,7 @@ func (bi *BinaryInfo) locationExpr(entry godwarf.Entry, attr dwarf.Attr, pc uint
 	//TODO(aarzilli): handle DW_FORM_loclistx attribute form new in DWARFv5
 	a := entry.Val(attr)
 	if a == nil {
-		return nil, nil, fmt.Errorf("no location attribute %s", attr)
+		return nil, nil, &optimizedOutError{fmt.Sprintf("no location attribute %s", attr)}
 	}
 	if instr, ok := a.([]byte); ok {
 		return instr, &locationExpr{isBlock: true, instr: instr}, nil
@@ -833,7 +958,7 @@ func (bi *BinaryInfo) locationExpr(entry godwarf.Entry, attr dwarf.Attr, pc uint
 	}
 	instr := bi.loclistEntry(off, pc)
 	if instr == nil {
-		return nil, nil, fmt.Errorf("could not find loclist entry at %#x for address %#x", off, pc)
+		return nil, nil, &optimizedOutError{fmt.Sprintf("could not find loclist entry at %#x for address %#x", off, pc)}
 	}
 	return instr, &locationExpr{pc: pc, off: off, instr: instr}, nil
 }
@@ -1052,7 +1177,14 @@ func (bi *BinaryInfo) openSeparateDebugInfo(image *Image, exe *elf.File, debugIn
 		}
 	}
 	if debugFilePath == "" {
-		return nil, nil, ErrNoDebugInfoFound
+		var err error
+		debugFilePath, err = bi.findDebugLinkFile(image, exe, debugInfoDirectories)
+		if err != nil {
+			debugFilePath = bi.fetchDebugInfo(image)
+			if debugFilePath == "" {
+				return nil, nil, err
+			}
+		}
 	}
 	sepFile, err := os.OpenFile(debugFilePath, 0, os.ModePerm)
 	if err != nil {
@@ -1073,6 +1205,158 @@ func (bi *BinaryInfo) openSeparateDebugInfo(image *Image, exe *elf.File, debugIn
 	return sepFile, elfFile, nil
 }
 
+// debuginfodClient returns the client used to download files from the
+// debuginfod servers, or nil if no server is configured.
+func (bi *BinaryInfo) debuginfodClient() *debuginfod.Client {
+	bi.debuginfodOnce.Do(func() {
+		bi.debuginfod = debuginfod.NewClientFromEnv()
+	})
+	return bi.debuginfod
+}
+
+// fetchDebugInfo downloads the separate debug info file of image from the
+// debuginfod servers and returns its path, or the empty string if it
+// could not be found.
+func (bi *BinaryInfo) fetchDebugInfo(image *Image) string {
+	if image.BuildID == "" {
+		return ""
+	}
+	client := bi.debuginfodClient()
+	if client == nil {
+		return ""
+	}
+	path, err := client.DebugInfo(image.BuildID)
+	if err != nil {
+		if err != debuginfod.ErrNotFound {
+			bi.logger.Warnf("debuginfod: %v", err)
+		}
+		return ""
+	}
+	return path
+}
+
+// SourceFetcher returns a function that downloads source files from the
+// debuginfod servers, using the build-ids of the images currently loaded,
+// and returns the path of the local copy.
+// The returned function doesn't access bi, it can be called without
+// holding the target lock.
+func (bi *BinaryInfo) SourceFetcher() (func(path string) (string, error), error) {
+	client := bi.debuginfodClient()
+	if client == nil {
+		return nil, errors.New("no debuginfod server configured")
+	}
+	var buildIDs []string
+	for _, image := range bi.Images {
+		if image.BuildID != "" {
+			buildIDs = append(buildIDs, image.BuildID)
+		}
+	}
+	return func(path string) (string, error) {
+		var lastErr error = debuginfod.ErrNotFound
+		for _, buildID := range buildIDs {
+			local, err := client.Source(buildID, path)
+			if err == nil {
+				return local, nil
+			}
+			if err != debuginfod.ErrNotFound {
+				lastErr = err
+			}
+		}
+		return "", lastErr
+	}, nil
+}
+
+// findDebugLinkFile returns the path of the separate debug info file named
+// by the .gnu_debuglink section of exe. Like GDB it is looked up in the
+// directory containing the executable, in its .debug subdirectory and,
+// for each debug info directory, under the path of the directory
+// containing the executable. Files whose CRC doesn't match the one
+// recorded in .gnu_debuglink are skipped.
+func (bi *BinaryInfo) findDebugLinkFile(image *Image, exe *elf.File, debugInfoDirectories []string) (string, error) {
+	name, crc, err := parseDebugLink(exe)
+	if err != nil {
+		return "", ErrNoDebugInfoFound
+	}
+
+	exePath := image.Path
+	if path, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = path
+	}
+	exeDir := filepath.Dir(exePath)
+
+	candidates := []string{
+		filepath.Join(exeDir, name),
+		filepath.Join(exeDir, ".debug", name),
+	}
+	for _, dir := range debugInfoDirectories {
+		if filepath.Base(dir) == ".build-id" {
+			// The global debug directory is the parent of the build-id
+			// directory (i.e. /usr/lib/debug for /usr/lib/debug/.build-id).
+			dir = filepath.Dir(dir)
+		}
+		candidates = append(candidates, filepath.Join(dir, exeDir, name))
+	}
+
+	var mismatch string
+	for _, path := range candidates {
+		if path == exePath {
+			continue
+		}
+		sum, err := fileCRC32(path)
+		if err != nil {
+			continue
+		}
+		if sum != crc {
+			bi.logger.Warnf("separate debug info file %q does not match %q: CRC mismatch", path, image.Path)
+			mismatch = path
+			continue
+		}
+		return path, nil
+	}
+	if mismatch != "" {
+		return "", fmt.Errorf("%v: separate debug info file %q does not match the executable (CRC mismatch)", ErrNoDebugInfoFound, mismatch)
+	}
+	return "", ErrNoDebugInfoFound
+}
+
+// fileCRC32 returns the CRC-32 (IEEE) checksum of the file at path.
+func fileCRC32(path string) (uint32, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer fh.Close()
+	h := crc32.NewIEEE()
+	if _, err := io.Copy(h, fh); err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
+
+// parseDebugLink returns the file name and CRC stored in the
+// .gnu_debuglink section of exe.
+func parseDebugLink(exe *elf.File) (string, uint32, error) {
+	sec := exe.Section(".gnu_debuglink")
+	if sec == nil {
+		return "", 0, errors.New("no .gnu_debuglink section")
+	}
+	data, err := sec.Data()
+	if err != nil {
+		return "", 0, err
+	}
+	// The section contains a NUL terminated file name, padded to a multiple
+	// of 4 bytes, followed by the CRC32 of the debug file.
+	n := bytes.IndexByte(data, 0)
+	if n <= 0 {
+		return "", 0, errors.New("malformed .gnu_debuglink section")
+	}
+	crcOff := (n + 4) &^ 3
+	if crcOff+4 > len(data) {
+		return "", 0, errors.New("malformed .gnu_debuglink section")
+	}
+	return string(data[:n]), exe.ByteOrder.Uint32(data[crcOff:]), nil
+}
+
 func parseBuildID(exe *elf.File) (string, string, error) {
 	buildid := exe.Section(".note.gnu.build-id")
 	if buildid == nil {
@@ -1116,6 +1400,9 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 	if !supportedLinuxArch[elfFile.Machine] {
 		return &ErrUnsupportedArch{os: "linux", cpuArch: elfFile.Machine}
 	}
+	if desc1, desc2, err := parseBuildID(elfFile); err == nil {
+		image.BuildID = desc1 + desc2
+	}
 
 	if image.index == 0 {
 		// adding executable file:
@@ -1136,6 +1423,15 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 		image.StaticBase = addr
 	}
 
+	if textsec := elfFile.Section(".text"); textsec != nil {
+		image.textStart = textsec.Addr + image.StaticBase
+		image.textEnd = image.textStart + textsec.Size
+	}
+
+	// .eh_frame is read before debug info so that we can unwind the stack
+	// through shared objects that don't have any.
+	bi.parseEhFrameElf(image, elfFile)
+
 	dwarfFile := elfFile
 
 	var debugInfoBytes []byte
@@ -1212,7 +1508,86 @@ func (bi *BinaryInfo) parseDebugFrameElf(image *Image, exe *elf.File, debugInfoB
 		return
 	}
 
-	bi.frameEntries = bi.frameEntries.Append(frame.Parse(debugFrameData, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize()))
+	fe, err := frame.Parse(debugFrameData, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize())
+	if err != nil {
+		image.setLoadError("could not parse .debug_frame section: %v", err)
+		return
+	}
+	bi.frameEntries = bi.frameEntries.Append(fe)
+}
+
+// _PT_GNU_EH_FRAME is the segment containing .eh_frame_hdr.
+const _PT_GNU_EH_FRAME elf.ProgType = 0x6474e550
+
+// parseEhFrameElf reads the .eh_frame section of exe. If the section
+// headers are missing the section is found through the .eh_frame_hdr
+// section referenced by the PT_GNU_EH_FRAME segment.
+func (bi *BinaryInfo) parseEhFrameElf(image *Image, exe *elf.File) {
+	var ehFrameData []byte
+	var ehFrameAddr uint64
+	if sec := exe.Section(".eh_frame"); sec != nil {
+		data, err := sec.Data()
+		if err != nil {
+			bi.logger.Warnf("could not read .eh_frame section of %s: %v", image.Path, err)
+			return
+		}
+		ehFrameData, ehFrameAddr = data, sec.Addr
+	} else {
+		for _, prog := range exe.Progs {
+			if prog.Type != _PT_GNU_EH_FRAME {
+				continue
+			}
+			hdr := make([]byte, prog.Filesz)
+			if _, err := prog.ReadAt(hdr, 0); err != nil {
+				bi.logger.Warnf("could not read .eh_frame_hdr section of %s: %v", image.Path, err)
+				return
+			}
+			addr, err := frame.EhFrameHdrAddr(hdr, prog.Vaddr, bi.Arch.PtrSize())
+			if err != nil {
+				bi.logger.Warnf("could not read .eh_frame_hdr section of %s: %v", image.Path, err)
+				return
+			}
+			ehFrameData, ehFrameAddr = elfSegmentData(exe, addr), addr
+			break
+		}
+	}
+	if ehFrameData == nil {
+		return
+	}
+
+	fe, err := frame.ParseEHFrame(ehFrameData, binary.LittleEndian, image.StaticBase, bi.Arch.PtrSize(), ehFrameAddr)
+	if err != nil {
+		bi.logger.Warnf("could not parse .eh_frame section of %s: %v", image.Path, err)
+		return
+	}
+	image.ehFrameEntries = fe
+}
+
+// ehFrameFDEForPC returns the frame descriptor entry read from the
+// .eh_frame section of any image that describes pc.
+func (bi *BinaryInfo) ehFrameFDEForPC(pc uint64) (*frame.FrameDescriptionEntry, error) {
+	for _, image := range bi.Images {
+		if fde, err := image.ehFrameEntries.FDEForPC(pc); err == nil {
+			return fde, nil
+		}
+	}
+	return nil, &frame.ErrNoFDEForPC{PC: pc}
+}
+
+// elfSegmentData returns the contents of the loadable segment of exe
+// containing addr, starting at addr.
+func elfSegmentData(exe *elf.File, addr uint64) []byte {
+	for _, prog := range exe.Progs {
+		if prog.Type != elf.PT_LOAD || addr < prog.Vaddr || addr >= prog.Vaddr+prog.Filesz {
+			continue
+		}
+		data := make([]byte, prog.Vaddr+prog.Filesz-addr)
+		if _, err := prog.ReadAt(data, int64(addr-prog.Vaddr)); err != nil {
+			return nil
+		}
+		return data
+	}
+	return nil
 }
 
 func (bi *BinaryInfo) setGStructOffsetElf(image *Image, exe *elf.File, wg *sync.WaitGroup) {
@@ -1296,6 +1671,11 @@ func loadBinaryInfoPE(bi *BinaryInfo, image *Image, path string, entryPoint uint
 		}
 	}
 
+	if textsec := peFile.Section(".text"); textsec != nil {
+		image.textStart = opth.ImageBase + uint64(textsec.VirtualAddress) + image.StaticBase
+		image.textEnd = image.textStart + uint64(textsec.VirtualSize)
+	}
+
 	image.dwarfReader = image.dwarf.Reader()
 
 	debugLineBytes, err := godwarf.GetDebugSectionPE(peFile, "line")
@@ -1343,7 +1723,12 @@ func (bi *BinaryInfo) parseDebugFramePE(image *Image, exe *pe.File, debugInfoByt
 		return
 	}
 
-	bi.frameEntries = bi.frameEntries.Append(frame.Parse(debugFrameBytes, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize()))
+	fe, err := frame.Parse(debugFrameBytes, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize())
+	if err != nil {
+		image.setLoadError("could not parse .debug_frame section: %v", err)
+		return
+	}
+	bi.frameEntries = bi.frameEntries.Append(fe)
 }
 
 // Borrowed from https://golang.org/src/cmd/internal/objfile/pe.go
@@ -1375,6 +1760,10 @@ func loadBinaryInfoMacho(bi *BinaryInfo, image *Image, path string, entryPoint u
 	if !supportedDarwinArch[exe.Cpu] {
 		return &ErrUnsupportedArch{os: "darwin", cpuArch: exe.Cpu}
 	}
+	if textsec := exe.Section("__text"); textsec != nil {
+		image.textStart = textsec.Addr + image.StaticBase
+		image.textEnd = image.textStart + textsec.Size
+	}
 	image.dwarf, err = exe.DWARF()
 	if err != nil {
 		return err
@@ -1424,7 +1813,12 @@ func (bi *BinaryInfo) parseDebugFrameMacho(image *Image, exe *macho.File, debugI
 		return
 	}
 
-	bi.frameEntries = bi.frameEntries.Append(frame.Parse(debugFrameBytes, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize()))
+	fe, err := frame.Parse(debugFrameBytes, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize())
+	if err != nil {
+		image.setLoadError("could not parse .debug_frame section: %v", err)
+		return
+	}
+	bi.frameEntries = bi.frameEntries.Append(fe)
 }
 
 // Do not call this function directly it isn't able to deal correctly with package paths
@@ -1534,6 +1928,22 @@ func (bi *BinaryInfo) registerTypeToPackageMap(entry *dwarf.Entry) {
 	bi.PackageMap[name] = []string{path}
 }
 
+// addPackageMapPaths adds paths to the package paths of package name.
+func (bi *BinaryInfo) addPackageMapPaths(name string, paths []string) {
+	for _, path := range paths {
+		found := false
+		for _, path2 := range bi.PackageMap[name] {
+			if path == path2 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			bi.PackageMap[name] = append(bi.PackageMap[name], path)
+		}
+	}
+}
+
 func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugInfoBytes, debugLineBytes []byte, wg *sync.WaitGroup, cont func()) {
 	if wg != nil {
 		defer wg.Done()
@@ -1554,99 +1964,295 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugInfoBytes, debugLineB
 
 	image.runtimeTypeToDIE = make(map[uint64]runtimeTypeDIE)
 
-	ctxt := newLoadDebugInfoMapsContext(bi, image, util.ReadUnitVersions(debugInfoBytes))
-
-	reader := image.DwarfReader()
+	cacheKey := bi.binaryInfoCacheKey(image)
+	if cacheKey == "" || !bi.loadBinaryInfoCache(image, cacheKey, debugLineBytes) {
+		bi.loadDebugInfoMapsDwarf(image, debugInfoBytes, debugLineBytes)
+		if cacheKey != "" {
+			bi.saveBinaryInfoCache(image, cacheKey)
+		}
+	}
 
-	for entry, err := reader.Next(); entry != nil; entry, err = reader.Next() {
-		if err != nil {
-			image.setLoadError("error reading debug_info: %v", err)
-			break
+	bi.LookupFunc = make(map[string]*Function)
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		// Plugins contain their own copy of the runtime and of every package
+		// they import, the copy in the executable is the one in use.
+		if fn2 := bi.LookupFunc[fn.Name]; fn2 != nil && fn2.imageIndex() <= fn.imageIndex() {
+			continue
 		}
-		switch entry.Tag {
-		case dwarf.TagCompileUnit:
-			cu := &compileUnit{}
-			cu.image = image
-			cu.entry = entry
-			cu.offset = entry.Offset
-			cu.Version = ctxt.offsetToVersion[cu.offset]
-			if lang, _ := entry.Val(dwarf.AttrLanguage).(int64); lang == dwarfGoLanguage {
-				cu.isgo = true
-			}
-			cu.name, _ = entry.Val(dwarf.AttrName).(string)
-			compdir, _ := entry.Val(dwarf.AttrCompDir).(string)
-			if compdir != "" {
-				cu.name = filepath.Join(compdir, cu.name)
-			}
-			cu.ranges, _ = image.dwarf.Ranges(entry)
-			for i := range cu.ranges {
-				cu.ranges[i][0] += image.StaticBase
-				cu.ranges[i][1] += image.StaticBase
-			}
-			if len(cu.ranges) >= 1 {
-				cu.lowPC = cu.ranges[0][0]
-			}
-			lineInfoOffset, hasLineInfo := entry.Val(dwarf.AttrStmtList).(int64)
-			if hasLineInfo && lineInfoOffset >= 0 && lineInfoOffset < int64(len(debugLineBytes)) {
-				var logfn func(string, ...interface{})
-				if logflags.DebugLineErrors() {
-					logger := logrus.New().WithFields(logrus.Fields{"layer": "dwarf-line"})
-					logger.Logger.Level = logrus.DebugLevel
-					logfn = func(fmt string, args ...interface{}) {
-						logger.Printf(fmt, args)
-					}
-				}
-				cu.lineInfo = line.Parse(compdir, bytes.NewBuffer(debugLineBytes[lineInfoOffset:]), logfn, image.StaticBase, bi.GOOS == "windows", bi.Arch.PtrSize())
-			}
-			cu.producer, _ = entry.Val(dwarf.AttrProducer).(string)
-			if cu.isgo && cu.producer != "" {
-				semicolon := strings.Index(cu.producer, ";")
-				if semicolon < 0 {
-					cu.optimized = goversion.ProducerAfterOrEqual(cu.producer, 1, 10)
-				} else {
-					cu.optimized = !strings.Contains(cu.producer[semicolon:], "-N") || !strings.Contains(cu.producer[semicolon:], "-l")
-					cu.producer = cu.producer[:semicolon]
-				}
-			}
-			gopkg, _ := entry.Val(godwarf.AttrGoPackageName).(string)
-			if cu.isgo && gopkg != "" {
-				bi.PackageMap[gopkg] = append(bi.PackageMap[gopkg], escapePackagePath(strings.Replace(cu.name, "\\", "/", -1)))
+		bi.LookupFunc[fn.Name] = fn
+	}
+
+	if image.index > 0 {
+		for _, cu := range image.compileUnits {
+			if gopkg, _ := cu.entry.Val(godwarf.AttrGoPackageName).(string); cu.isgo && gopkg == "main" && cu.name != "main" {
+				image.PluginPath = cu.name
+				break
 			}
-			image.compileUnits = append(image.compileUnits, cu)
-			if entry.Children {
-				bi.loadDebugInfoMapsCompileUnit(ctxt, image, reader, cu)
+		}
+	}
+
+	for _, cu := range image.compileUnits {
+		if cu.lineTable != nil {
+			for _, fileEntry := range cu.lineTable.fileNames {
+				bi.Sources = append(bi.Sources, fileEntry.Path)
 			}
+		}
+	}
+	sort.Strings(bi.Sources)
+	bi.Sources = uniq(bi.Sources)
 
-		case dwarf.TagPartialUnit:
-			reader.SkipChildren()
+	if cont != nil {
+		cont()
+	}
+}
 
-		default:
-			// ignore unknown tags
+// loadDebugInfoMapsWorkers is the number of goroutines used to load the
+// compile units of an image.
+var loadDebugInfoMapsWorkers = runtime.GOMAXPROCS(0)
+
+// loadDebugInfoMapsUnit is the result of loading a single compile unit.
+type loadDebugInfoMapsUnit struct {
+	cu      *compileUnit
+	gopkg   string      // value of the AttrGoPackageName attribute of the compile unit
+	partial *BinaryInfo // maps filled by reading the compile unit
+	ctxt    *loadDebugInfoMapsContext
+}
+
+// loadDebugInfoMapsDwarf fills the maps of bi by reading the debug_info
+// section of image.
+// Compile units are read in parallel, each one into a separate set of
+// maps, which are then merged into bi in the order they appear in
+// debug_info.
+// Variables and types are only indexed here, their DWARF entries are
+// read the first time they are needed. Only the file table of each line
+// table is read here, for the list of source files and the inlined calls,
+// the line programs are parsed the first time they are used.
+func (bi *BinaryInfo) loadDebugInfoMapsDwarf(image *Image, debugInfoBytes, debugLineBytes []byte) {
+	ctxt := newLoadDebugInfoMapsContext(bi, image, util.ReadUnitVersions(debugInfoBytes))
+
+	unitOffsets := make([]dwarf.Offset, 0, len(ctxt.offsetToVersion))
+	for off := range ctxt.offsetToVersion {
+		unitOffsets = append(unitOffsets, off)
+	}
+	if debugInfoBytes == nil {
+		// Only used by LoadImageFromData, find the compile units by walking debug_info.
+		reader := image.DwarfReader()
+		for entry, err := reader.Next(); entry != nil && err == nil; entry, err = reader.Next() {
+			unitOffsets = append(unitOffsets, entry.Offset)
 			reader.SkipChildren()
 		}
 	}
+	sort.Slice(unitOffsets, func(i, j int) bool { return unitOffsets[i] < unitOffsets[j] })
+
+	units := make([]*loadDebugInfoMapsUnit, len(unitOffsets))
+	next := int32(-1)
+	var wg sync.WaitGroup
+	for w := 0; w < loadDebugInfoMapsWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt32(&next, 1))
+				if i >= len(unitOffsets) {
+					return
+				}
+				units[i] = bi.loadDebugInfoMapsUnit(ctxt, image, unitOffsets[i], debugLineBytes)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var pending []pendingAbstractOrigin
+	for _, unit := range units {
+		if unit == nil {
+			continue
+		}
+		cu := unit.cu
+		if unit.gopkg != "" {
+			bi.addPackageMapPaths(unit.gopkg, []string{escapePackagePath(strings.Replace(cu.name, "\\", "/", -1))})
+		}
+		image.compileUnits = append(image.compileUnits, cu)
+		bi.mergeLoadDebugInfoMapsUnit(ctxt, image, unit)
+		pending = append(pending, unit.ctxt.pendingAbstractOrigins...)
+	}
+
+	bi.resolvePendingAbstractOrigins(ctxt, pending)
 
 	sort.Sort(compileUnitsByOffset(image.compileUnits))
 	sort.Sort(functionsDebugInfoByEntry(bi.Functions))
 	sort.Sort(packageVarsByAddr(bi.packageVars))
+}
 
-	bi.LookupFunc = make(map[string]*Function)
-	for i := range bi.Functions {
-		bi.LookupFunc[bi.Functions[i].Name] = &bi.Functions[i]
+// loadDebugInfoMapsUnit reads the compile unit at offset off.
+func (bi *BinaryInfo) loadDebugInfoMapsUnit(imageCtxt *loadDebugInfoMapsContext, image *Image, off dwarf.Offset, debugLineBytes []byte) *loadDebugInfoMapsUnit {
+	reader := image.DwarfReader()
+	reader.Seek(off)
+	entry, err := reader.Next()
+	if err != nil {
+		image.setLoadError("error reading debug_info: %v", err)
+		return nil
+	}
+	if entry == nil || entry.Tag != dwarf.TagCompileUnit {
+		// partial units are loaded when they are imported, other units are ignored
+		return nil
 	}
 
-	for _, cu := range image.compileUnits {
-		if cu.lineInfo != nil {
-			for _, fileEntry := range cu.lineInfo.FileNames {
-				bi.Sources = append(bi.Sources, fileEntry.Path)
+	unit := &loadDebugInfoMapsUnit{
+		partial: &BinaryInfo{
+			GOOS:             bi.GOOS,
+			Arch:             bi.Arch,
+			logger:           bi.logger,
+			types:            make(map[string]dwarfRef),
+			consts:           make(map[dwarfRef]*constantType),
+			PackageMap:       make(map[string][]string),
+			inlinedCallLines: make(map[fileLine][]uint64),
+		},
+		ctxt: imageCtxt.newUnitContext(image),
+	}
+
+	cu := &compileUnit{}
+	cu.image = image
+	cu.entry = entry
+	cu.offset = entry.Offset
+	cu.Version = imageCtxt.offsetToVersion[cu.offset]
+	if lang, _ := entry.Val(dwarf.AttrLanguage).(int64); lang == dwarfGoLanguage {
+		cu.isgo = true
+	}
+	cu.name, _ = entry.Val(dwarf.AttrName).(string)
+	compdir, _ := entry.Val(dwarf.AttrCompDir).(string)
+	if compdir != "" {
+		cu.name = filepath.Join(compdir, cu.name)
+	}
+	cu.ranges, _ = image.dwarf.Ranges(entry)
+	for i := range cu.ranges {
+		cu.ranges[i][0] += image.StaticBase
+		cu.ranges[i][1] += image.StaticBase
+	}
+	if len(cu.ranges) >= 1 {
+		cu.lowPC = cu.ranges[0][0]
+	}
+	cu.lineTable = bi.compileUnitLineTable(entry, debugLineBytes)
+	cu.producer, _ = entry.Val(dwarf.AttrProducer).(string)
+	if cu.isgo && cu.producer != "" {
+		semicolon := strings.Index(cu.producer, ";")
+		if semicolon < 0 {
+			cu.optimized = goversion.ProducerAfterOrEqual(cu.producer, 1, 10)
+		} else {
+			cu.optimized = !strings.Contains(cu.producer[semicolon:], "-N") || !strings.Contains(cu.producer[semicolon:], "-l")
+			cu.producer = cu.producer[:semicolon]
+		}
+	}
+	if gopkg, _ := entry.Val(godwarf.AttrGoPackageName).(string); cu.isgo {
+		unit.gopkg = gopkg
+		if gopkg == "main" && image.index > 0 && cu.name != "main" {
+			// The main package of a plugin is named after the plugin path, it
+			// must not be confused with the main package of the executable.
+			unit.gopkg = cu.name[strings.LastIndex(cu.name, "/")+1:]
+		}
+	}
+	unit.cu = cu
+	if entry.Children {
+		unit.partial.loadDebugInfoMapsCompileUnit(unit.ctxt, image, reader, cu)
+	}
+	return unit
+}
+
+// mergeLoadDebugInfoMapsUnit adds the maps loaded from a single compile
+// unit to bi.
+func (bi *BinaryInfo) mergeLoadDebugInfoMapsUnit(ctxt *loadDebugInfoMapsContext, image *Image, unit *loadDebugInfoMapsUnit) {
+	partial := unit.partial
+
+	base := len(bi.Functions)
+	bi.Functions = append(bi.Functions, partial.Functions...)
+	for off, idx := range unit.ctxt.abstractOriginTable {
+		ctxt.abstractOriginTable[off] = base + idx
+	}
+
+	for name, ref := range partial.types {
+		if _, exists := bi.types[name]; !exists {
+			bi.types[name] = ref
+		}
+	}
+	for ref, pct := range partial.consts {
+		ct := bi.consts[ref]
+		if ct == nil {
+			ct = &constantType{}
+			bi.consts[ref] = ct
+		}
+		ct.values = append(ct.values, pct.values...)
+	}
+	bi.packageVars = append(bi.packageVars, partial.packageVars...)
+	for name, paths := range partial.PackageMap {
+		bi.addPackageMapPaths(name, paths)
+	}
+	for fl, pcs := range partial.inlinedCallLines {
+		bi.inlinedCallLines[fl] = append(bi.inlinedCallLines[fl], pcs...)
+	}
+	for off, rtdie := range unit.ctxt.runtimeTypeToDIE {
+		if _, exists := image.runtimeTypeToDIE[off]; !exists {
+			image.runtimeTypeToDIE[off] = rtdie
+		}
+	}
+}
+
+// resolvePendingAbstractOrigins links concrete subprograms and inlined
+// calls to abstract origins that belong to a different compile unit.
+func (bi *BinaryInfo) resolvePendingAbstractOrigins(ctxt *loadDebugInfoMapsContext, pending []pendingAbstractOrigin) {
+	for _, p := range pending {
+		originIdx, ok := ctxt.abstractOriginTable[p.originOffset]
+		if !ok {
+			if p.concrete {
+				bi.logger.Warnf("reading debug_info: could not find abstract origin of concrete inlined subprogram at %#x (origin offset %#x)", p.offset, p.originOffset)
+			} else {
+				bi.logger.Warnf("reading debug_info: could not find abstract origin (%#x) of inlined call at %#x", p.originOffset, p.offset)
 			}
+			continue
+		}
+		fn := &bi.Functions[originIdx]
+		if p.concrete {
+			fn.offset = p.offset
+			fn.Entry = p.call.LowPC
+			fn.End = p.call.HighPC
+			continue
 		}
+		fn.InlinedCalls = append(fn.InlinedCalls, p.call)
+		bi.inlinedCallLines[p.callLine] = append(bi.inlinedCallLines[p.callLine], p.call.LowPC)
 	}
-	sort.Strings(bi.Sources)
-	bi.Sources = uniq(bi.Sources)
+}
 
-	if cont != nil {
-		cont()
+// compileUnitLineTable reads the directory and file tables of the
+// debug_line segment associated with the compile unit described by entry.
+func (bi *BinaryInfo) compileUnitLineTable(entry *dwarf.Entry, debugLineBytes []byte) *cuLineTable {
+	lineInfoOffset, hasLineInfo := entry.Val(dwarf.AttrStmtList).(int64)
+	if !hasLineInfo || lineInfoOffset < 0 || lineInfoOffset >= int64(len(debugLineBytes)) {
+		return nil
+	}
+	compdir, _ := entry.Val(dwarf.AttrCompDir).(string)
+	lt := &cuLineTable{data: debugLineBytes[lineInfoOffset:], normalizeBackslash: bi.GOOS == "windows", ptrSize: bi.Arch.PtrSize()}
+	lt.includeDirs, lt.fileNames, lt.instructionsOffset = line.ParseFileTable(compdir, lt.data, lineInfoLogf(), lt.normalizeBackslash, lt.ptrSize)
+	return lt
+}
+
+// cachedCompileUnitLineTable is like compileUnitLineTable but uses the
+// directory and file tables and the instructions offset saved in the
+// on-disk cache.
+func (bi *BinaryInfo) cachedCompileUnitLineTable(entry *dwarf.Entry, debugLineBytes []byte, includeDirs []string, fileNames []*line.FileEntry, instructionsOffset int) *cuLineTable {
+	lineInfoOffset, hasLineInfo := entry.Val(dwarf.AttrStmtList).(int64)
+	if !hasLineInfo || lineInfoOffset < 0 || lineInfoOffset >= int64(len(debugLineBytes)) {
+		return nil
+	}
+	return &cuLineTable{data: debugLineBytes[lineInfoOffset:], includeDirs: includeDirs, fileNames: fileNames, instructionsOffset: instructionsOffset, normalizeBackslash: bi.GOOS == "windows", ptrSize: bi.Arch.PtrSize()}
+}
+
+func lineInfoLogf() func(string, ...interface{}) {
+	if !logflags.DebugLineErrors() {
+		return nil
+	}
+	logger := logrus.New().WithFields(logrus.Fields{"layer": "dwarf-line"})
+	logger.Logger.Level = logrus.DebugLevel
+	return func(fmt string, args ...interface{}) {
+		logger.Printf(fmt, args)
 	}
 }
 
@@ -1684,7 +2290,7 @@ func (bi *BinaryInfo) loadDebugInfoMapsCompileUnit(ctxt *loadDebugInfoMapsContex
 			if cu != nil && cu.isgo && !hasAttrGoPkgName {
 				bi.registerTypeToPackageMap(entry)
 			}
-			image.registerRuntimeTypeToDIE(entry, ctxt.ardr)
+			ctxt.registerRuntimeTypeToDIE(entry)
 			reader.SkipChildren()
 
 		case dwarf.TagVariable:
@@ -1801,20 +2407,21 @@ func (bi *BinaryInfo) addConcreteInlinedSubprogram(entry *dwarf.Entry, originOff
 		return
 	}
 
-	originIdx, ok := ctxt.abstractOriginTable[originOffset]
-	if !ok {
-		bi.logger.Warnf("reading debug_info: could not find abstract origin of concrete inlined subprogram at %#x (origin offset %#x)", entry.Offset, originOffset)
-		if entry.Children {
-			reader.SkipChildren()
-		}
-		return
+	if originIdx, ok := ctxt.abstractOriginTable[originOffset]; ok {
+		fn := &bi.Functions[originIdx]
+		fn.offset = entry.Offset
+		fn.Entry = lowpc
+		fn.End = highpc
+	} else {
+		// the abstract origin belongs to a different compile unit
+		ctxt.pendingAbstractOrigins = append(ctxt.pendingAbstractOrigins, pendingAbstractOrigin{
+			originOffset: originOffset,
+			offset:       entry.Offset,
+			concrete:     true,
+			call:         InlinedCall{cu: cu, LowPC: lowpc, HighPC: highpc},
+		})
 	}
 
-	fn := &bi.Functions[originIdx]
-	fn.offset = entry.Offset
-	fn.Entry = lowpc
-	fn.End = highpc
-
 	if entry.Children {
 		bi.loadDebugInfoMapsInlinedCalls(ctxt, reader, cu)
 	}
@@ -1894,14 +2501,6 @@ func (bi *BinaryInfo) loadDebugInfoMapsInlinedCalls(ctxt *loadDebugInfoMapsConte
 				continue
 			}
 
-			originIdx, ok := ctxt.abstractOriginTable[originOffset]
-			if !ok {
-				bi.logger.Warnf("reading debug_info: could not find abstract origin (%#x) of inlined call at %#x", originOffset, entry.Offset)
-				reader.SkipChildren()
-				continue
-			}
-			fn := &bi.Functions[originIdx]
-
 			lowpc, highpc, ok := subprogramEntryRange(entry, cu.image)
 			if !ok {
 				bi.logger.Warnf("reading debug_info: inlined call without address range at %#x", entry.Offset)
@@ -1916,28 +2515,45 @@ func (bi *BinaryInfo) loadDebugInfoMapsInlinedCalls(ctxt *loadDebugInfoMapsConte
 				reader.SkipChildren()
 				continue
 			}
-			if cu.lineInfo == nil {
+			if cu.lineTable == nil {
 				bi.logger.Warnf("reading debug_info: inlined call on a compilation unit without debug_line section at %#x", entry.Offset)
 				reader.SkipChildren()
 				continue
 			}
-			if int(callfileidx-1) >= len(cu.lineInfo.FileNames) {
+			if int(callfileidx-1) >= len(cu.lineTable.fileNames) {
 				bi.logger.Warnf("reading debug_info: CallFile (%d) of inlined call does not exist in compile unit file table at %#x", callfileidx, entry.Offset)
 				reader.SkipChildren()
 				continue
 			}
-			callfile := cu.lineInfo.FileNames[callfileidx-1].Path
+			callfile := cu.lineTable.fileNames[callfileidx-1].Path
 
-			fn.InlinedCalls = append(fn.InlinedCalls, InlinedCall{
+			call := InlinedCall{
 				cu:     cu,
 				LowPC:  lowpc,
 				HighPC: highpc,
-			})
-
+			}
 			fl := fileLine{callfile, int(callline)}
-			bi.inlinedCallLines[fl] = append(bi.inlinedCallLines[fl], lowpc)
+
+			originIdx, ok := ctxt.abstractOriginTable[originOffset]
+			if !ok {
+				// the abstract origin belongs to a different compile unit
+				ctxt.pendingAbstractOrigins = append(ctxt.pendingAbstractOrigins, pendingAbstractOrigin{
+					originOffset: originOffset,
+					offset:       entry.Offset,
+					call:         call,
+					callLine:     fl,
+				})
+			} else {
+				fn := &bi.Functions[originIdx]
+				fn.InlinedCalls = append(fn.InlinedCalls, call)
+				bi.inlinedCallLines[fl] = append(bi.inlinedCallLines[fl], lowpc)
+			}
+		}
+		if entry.Children {
+			// inlined calls can be nested inside lexical blocks and other
+			// inlined calls.
+			bi.loadDebugInfoMapsInlinedCalls(ctxt, reader, cu)
 		}
-		reader.SkipChildren()
 	}
 }
 
@@ -2051,14 +2667,14 @@ type PackageBuildInfo struct {
 func (bi *BinaryInfo) ListPackagesBuildInfo(includeFiles bool) []*PackageBuildInfo {
 	m := make(map[string]*PackageBuildInfo)
 	for _, cu := range bi.Images[0].compileUnits {
-		if cu.image != bi.Images[0] || !cu.isgo || cu.lineInfo == nil {
+		if cu.image != bi.Images[0] || !cu.isgo || cu.lineTable == nil {
 			//TODO(aarzilli): what's the correct thing to do for plugins?
 			continue
 		}
 
 		ip := strings.Replace(cu.name, "\\", "/", -1)
 		if _, ok := m[ip]; !ok {
-			path := cu.lineInfo.FirstFile()
+			path := cu.lineInfo().FirstFile()
 			if ext := filepath.Ext(path); ext != ".go" && ext != ".s" {
 				continue
 			}
@@ -2073,7 +2689,7 @@ func (bi *BinaryInfo) ListPackagesBuildInfo(includeFiles bool) []*PackageBuildIn
 		if includeFiles {
 			pbi := m[ip]
 
-			for _, file := range cu.lineInfo.FileNames {
+			for _, file := range cu.lineTable.fileNames {
 				pbi.Files[file.Path] = struct{}{}
 			}
 		}
@@ -0,0 +1,408 @@
+package proc
+
+import (
+	"crypto/sha256"
+	"debug/dwarf"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/pkg/dwarf/line"
+)
+
+// binaryInfoCacheVersion is part of the name of every cache file, it must
+// be incremented whenever the format of the cache files, or the way the
+// cached data is computed, changes.
+const binaryInfoCacheVersion = 7
+
+// BinaryInfoCacheConfig describes the on-disk cache of the debug
+// information of executable files.
+// Reading the debug_info section of a large executable can take several
+// seconds, the cache stores the function table, compile units, line
+// tables, types index and inlined calls of executables so that subsequent
+// debugging sessions of the same file can skip it.
+// The cache is configured for each target with NewTargetConfig.
+// Only the executable file is cached, the debug information of shared
+// libraries and plugins is always read from their files.
+type BinaryInfoCacheConfig struct {
+	Dir     string // directory containing the cache files, the cache is disabled if empty
+	MaxSize int64  // maximum total size of the cache files in bytes, zero means unlimited
+}
+
+// DefaultBinaryInfoCacheDir returns the default directory of the on-disk
+// cache of debug information.
+func DefaultBinaryInfoCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "dlv", "bininfo"), nil
+}
+
+// binaryInfoCacheFile is the content of a cache file. All addresses are
+// relative to the static base of the image, the keys of RuntimeTypes are
+// offsets in runtime.moduledata.types.
+type binaryInfoCacheFile struct {
+	CompileUnits     []cachedCompileUnit
+	Functions        []cachedFunction
+	PackageVars      []cachedPackageVar
+	Types            map[string]dwarf.Offset
+	Consts           map[dwarf.Offset][]cachedConstant
+	PackageMap       map[string][]string
+	InlinedCallLines []cachedInlinedCallLine
+	RuntimeTypes     map[uint64]dwarf.Offset
+}
+
+type cachedCompileUnit struct {
+	Name      string
+	Version   uint8
+	Ranges    [][2]uint64
+	Offset    dwarf.Offset
+	IsGo      bool
+	Optimized bool
+	Producer  string
+
+	// IncludeDirs, FileNames and InstructionsOffset describe the line
+	// table of the compile unit, if HasLineInfo is set.
+	HasLineInfo        bool
+	IncludeDirs        []string
+	FileNames          []*line.FileEntry
+	InstructionsOffset int
+}
+
+type cachedFunction struct {
+	Name         string
+	Entry, End   uint64
+	Offset       dwarf.Offset
+	CU           int // index in CompileUnits, -1 if the function doesn't have a compile unit
+	InlinedCalls []cachedInlinedCall
+}
+
+type cachedInlinedCall struct {
+	CU            int
+	LowPC, HighPC uint64
+}
+
+type cachedPackageVar struct {
+	Name   string
+	CU     int
+	Offset dwarf.Offset
+	Addr   uint64
+}
+
+type cachedConstant struct {
+	Name, FullName string
+	Value          int64
+	SingleBit      bool
+}
+
+type cachedInlinedCallLine struct {
+	File string
+	Line int
+	PCs  []uint64
+}
+
+// binaryInfoCacheKey returns the name of the cache file for image, or the
+// empty string if image should not be cached.
+// Only the executable file is cached, and only if its debug information is
+// the first one loaded into bi, so that all the maps of bi describe it.
+// The cache file is identified by a hash of the contents of the
+// executable, reading the file is much faster than parsing its debug
+// information and, unlike its build-id or modification time, the hash
+// can't be left unchanged by tools that rewrite the debug sections.
+func (bi *BinaryInfo) binaryInfoCacheKey(image *Image) string {
+	if bi.cacheConfig.Dir == "" || image.index != 0 || image.Path == "" || len(bi.Functions) != 0 {
+		return ""
+	}
+	fh, err := os.Open(image.Path)
+	if err != nil {
+		return ""
+	}
+	defer fh.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, fh); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x.v%d", h.Sum(nil), binaryInfoCacheVersion)
+}
+
+// loadBinaryInfoCache fills the maps of bi with the contents of the cache
+// file for image, it returns false if the cache file does not exist or
+// could not be used.
+func (bi *BinaryInfo) loadBinaryInfoCache(image *Image, key string, debugLineBytes []byte) bool {
+	path := filepath.Join(bi.cacheConfig.Dir, key)
+	fh, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	var data binaryInfoCacheFile
+	err = gob.NewDecoder(fh).Decode(&data)
+	fh.Close()
+	if err == nil {
+		err = bi.useBinaryInfoCache(image, &data, debugLineBytes)
+	}
+	if err != nil {
+		bi.logger.Warnf("could not use debug info cache file %s: %v", path, err)
+		os.Remove(path)
+		return false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return true
+}
+
+func (bi *BinaryInfo) useBinaryInfoCache(image *Image, data *binaryInfoCacheFile, debugLineBytes []byte) error {
+	sb := image.StaticBase
+
+	cus := make([]*compileUnit, len(data.CompileUnits))
+	rdr := image.DwarfReader()
+	for i, ccu := range data.CompileUnits {
+		rdr.Seek(ccu.Offset)
+		entry, err := rdr.Next()
+		if err != nil {
+			return err
+		}
+		if entry == nil || entry.Tag != dwarf.TagCompileUnit {
+			return fmt.Errorf("no compile unit at %#x", ccu.Offset)
+		}
+		cu := &compileUnit{
+			name:      ccu.Name,
+			Version:   ccu.Version,
+			ranges:    ccu.Ranges,
+			entry:     entry,
+			isgo:      ccu.IsGo,
+			optimized: ccu.Optimized,
+			producer:  ccu.Producer,
+			offset:    ccu.Offset,
+			image:     image,
+		}
+		for i := range cu.ranges {
+			cu.ranges[i][0] += sb
+			cu.ranges[i][1] += sb
+		}
+		if len(cu.ranges) >= 1 {
+			cu.lowPC = cu.ranges[0][0]
+		}
+		if ccu.HasLineInfo {
+			cu.lineTable = bi.cachedCompileUnitLineTable(entry, debugLineBytes, ccu.IncludeDirs, ccu.FileNames, ccu.InstructionsOffset)
+		}
+		cus[i] = cu
+	}
+
+	cuAt := func(idx int) (*compileUnit, error) {
+		if idx < 0 {
+			return nil, nil
+		}
+		if idx >= len(cus) {
+			return nil, fmt.Errorf("compile unit index %d out of range", idx)
+		}
+		return cus[idx], nil
+	}
+
+	fns := make([]Function, len(data.Functions))
+	for i, cfn := range data.Functions {
+		cu, err := cuAt(cfn.CU)
+		if err != nil {
+			return err
+		}
+		fn := Function{Name: cfn.Name, offset: cfn.Offset, cu: cu}
+		if cfn.Entry != 0 || cfn.End != 0 {
+			fn.Entry, fn.End = cfn.Entry+sb, cfn.End+sb
+		}
+		for _, ccall := range cfn.InlinedCalls {
+			cu, err := cuAt(ccall.CU)
+			if err != nil {
+				return err
+			}
+			fn.InlinedCalls = append(fn.InlinedCalls, InlinedCall{cu: cu, LowPC: ccall.LowPC + sb, HighPC: ccall.HighPC + sb})
+		}
+		fns[i] = fn
+	}
+
+	pkgvars := make([]packageVar, len(data.PackageVars))
+	for i, cpv := range data.PackageVars {
+		cu, err := cuAt(cpv.CU)
+		if err != nil {
+			return err
+		}
+		pkgvars[i] = packageVar{name: cpv.Name, cu: cu, offset: cpv.Offset, addr: cpv.Addr + sb}
+	}
+
+	image.compileUnits = cus
+	bi.Functions = fns
+	bi.packageVars = pkgvars
+	for name, off := range data.Types {
+		bi.types[name] = dwarfRef{image.index, off}
+	}
+	for off, cvals := range data.Consts {
+		ct := &constantType{}
+		for _, cval := range cvals {
+			ct.values = append(ct.values, constantValue{name: cval.Name, fullName: cval.FullName, value: cval.Value, singleBit: cval.SingleBit})
+		}
+		bi.consts[dwarfRef{image.index, off}] = ct
+	}
+	for name, paths := range data.PackageMap {
+		bi.addPackageMapPaths(name, paths)
+	}
+	for _, cl := range data.InlinedCallLines {
+		pcs := make([]uint64, len(cl.PCs))
+		for i := range cl.PCs {
+			pcs[i] = cl.PCs[i] + sb
+		}
+		bi.inlinedCallLines[fileLine{cl.File, cl.Line}] = pcs
+	}
+	for off, dieOff := range data.RuntimeTypes {
+		image.runtimeTypeToDIE[off] = runtimeTypeDIE{dieOff, -1}
+	}
+	return nil
+}
+
+// saveBinaryInfoCache writes the maps of bi describing image to the cache
+// file key.
+func (bi *BinaryInfo) saveBinaryInfoCache(image *Image, key string) {
+	if image.LoadError() != nil {
+		return
+	}
+	cfg := bi.cacheConfig
+	sb := image.StaticBase
+
+	data := binaryInfoCacheFile{
+		Types:        make(map[string]dwarf.Offset, len(bi.types)),
+		Consts:       make(map[dwarf.Offset][]cachedConstant, len(bi.consts)),
+		PackageMap:   bi.PackageMap,
+		RuntimeTypes: make(map[uint64]dwarf.Offset, len(image.runtimeTypeToDIE)),
+	}
+
+	cuIndex := make(map[*compileUnit]int, len(image.compileUnits))
+	for i, cu := range image.compileUnits {
+		cuIndex[cu] = i
+		ccu := cachedCompileUnit{
+			Name:      cu.name,
+			Version:   cu.Version,
+			Ranges:    make([][2]uint64, len(cu.ranges)),
+			Offset:    cu.offset,
+			IsGo:      cu.isgo,
+			Optimized: cu.optimized,
+			Producer:  cu.producer,
+		}
+		for i := range cu.ranges {
+			ccu.Ranges[i] = [2]uint64{cu.ranges[i][0] - sb, cu.ranges[i][1] - sb}
+		}
+		if cu.lineTable != nil {
+			ccu.HasLineInfo = true
+			ccu.IncludeDirs = cu.lineTable.includeDirs
+			ccu.FileNames = cu.lineTable.fileNames
+			ccu.InstructionsOffset = cu.lineTable.instructionsOffset
+		}
+		data.CompileUnits = append(data.CompileUnits, ccu)
+	}
+	indexOf := func(cu *compileUnit) int {
+		if idx, ok := cuIndex[cu]; ok {
+			return idx
+		}
+		return -1
+	}
+
+	data.Functions = make([]cachedFunction, len(bi.Functions))
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		cfn := cachedFunction{Name: fn.Name, Offset: fn.offset, CU: indexOf(fn.cu)}
+		if fn.Entry != 0 || fn.End != 0 {
+			cfn.Entry, cfn.End = fn.Entry-sb, fn.End-sb
+		}
+		for _, call := range fn.InlinedCalls {
+			cfn.InlinedCalls = append(cfn.InlinedCalls, cachedInlinedCall{CU: indexOf(call.cu), LowPC: call.LowPC - sb, HighPC: call.HighPC - sb})
+		}
+		data.Functions[i] = cfn
+	}
+
+	data.PackageVars = make([]cachedPackageVar, len(bi.packageVars))
+	for i, pv := range bi.packageVars {
+		data.PackageVars[i] = cachedPackageVar{Name: pv.name, CU: indexOf(pv.cu), Offset: pv.offset, Addr: pv.addr - sb}
+	}
+
+	for name, ref := range bi.types {
+		data.Types[name] = ref.offset
+	}
+	for ref, ct := range bi.consts {
+		cvals := make([]cachedConstant, len(ct.values))
+		for i, val := range ct.values {
+			cvals[i] = cachedConstant{Name: val.name, FullName: val.fullName, Value: val.value, SingleBit: val.singleBit}
+		}
+		data.Consts[ref.offset] = cvals
+	}
+	for fl, pcs := range bi.inlinedCallLines {
+		cl := cachedInlinedCallLine{File: fl.file, Line: fl.line, PCs: make([]uint64, len(pcs))}
+		for i := range pcs {
+			cl.PCs[i] = pcs[i] - sb
+		}
+		data.InlinedCallLines = append(data.InlinedCallLines, cl)
+	}
+	for off, rtdie := range image.runtimeTypeToDIE {
+		data.RuntimeTypes[off] = rtdie.offset
+	}
+
+	if err := writeBinaryInfoCache(cfg.Dir, key, &data); err != nil {
+		bi.logger.Warnf("could not write debug info cache: %v", err)
+		return
+	}
+	trimBinaryInfoCache(cfg.Dir, cfg.MaxSize, key)
+}
+
+func writeBinaryInfoCache(dir, key string, data *binaryInfoCacheFile) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// The cache file is written to a temporary file first so that concurrent
+	// instances of Delve never see a partially written cache file.
+	tmp, err := ioutil.TempFile(dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	err = gob.NewEncoder(tmp).Encode(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(dir, key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// trimBinaryInfoCache deletes the least recently used cache files in dir
+// until their total size is below maxSize. The cache file keep, which was
+// just written, is never deleted.
+func trimBinaryInfoCache(dir string, maxSize int64, keep string) {
+	if maxSize <= 0 {
+		return
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	sort.Slice(fis, func(i, j int) bool { return fis[i].ModTime().After(fis[j].ModTime()) })
+	var size int64
+	for _, fi := range fis {
+		if fi.Name() == keep {
+			size += fi.Size()
+		}
+	}
+	for _, fi := range fis {
+		if fi.IsDir() || fi.Name() == keep || strings.HasPrefix(fi.Name(), "tmp-") {
+			continue
+		}
+		size += fi.Size()
+		if size > maxSize {
+			os.Remove(filepath.Join(dir, fi.Name()))
+		}
+	}
+}
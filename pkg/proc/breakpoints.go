@@ -66,6 +66,9 @@ type Breakpoint struct {
 	Cond ast.Expr
 	// internalCond is the same as Cond but used for the condition of internal breakpoints
 	internalCond ast.Expr
+	// freezeCond is true for the goroutines frozen at this address, it is
+	// only used by breakpoints of kind FreezeBreakpoint.
+	freezeCond ast.Expr
 
 	// ReturnInfo describes how to collect return variables when this
 	// breakpoint is hit as a return breakpoint.
@@ -91,6 +94,11 @@ const (
 	// Continue will set a new breakpoint (of NextBreakpoint kind) on the
 	// destination of CALL, delete this breakpoint and then continue again
 	StepBreakpoint
+	// FreezeBreakpoint is a breakpoint set by FreezeGoroutine at the address
+	// where a frozen goroutine will resume execution, it never stops the
+	// target, instead the thread that hits it is kept stopped if it is
+	// running the frozen goroutine.
+	FreezeBreakpoint
 )
 
 func (bp *Breakpoint) String() string {
@@ -129,6 +137,9 @@ type returnBreakpointInfo struct {
 // CheckCondition evaluates bp's condition on thread.
 func (bp *Breakpoint) CheckCondition(thread Thread) BreakpointState {
 	bpstate := BreakpointState{Breakpoint: bp, Active: false, Internal: false, CondError: nil}
+	if bp.Kind == FreezeBreakpoint {
+		return bpstate
+	}
 	if bp.Cond == nil && bp.internalCond == nil {
 		bpstate.Active = true
 		bpstate.Internal = bp.IsInternal()
@@ -180,7 +191,7 @@ func isDeferReturnCall(frames []Stackframe, deferReturns []uint64) (bool, uint64
 // User-set breakpoints can overlap with internal breakpoints, in that case
 // both IsUser and IsInternal will be true.
 func (bp *Breakpoint) IsInternal() bool {
-	return bp.Kind != UserBreakpoint
+	return bp.Kind&^(UserBreakpoint|FreezeBreakpoint) != 0
 }
 
 // IsUser returns true if bp is a user-set breakpoint.
@@ -251,7 +262,15 @@ func (t *Target) SetBreakpoint(addr uint64, kind BreakpointKind, cond ast.Expr)
 		// We can overlap one internal breakpoint with one user breakpoint, we
 		// need to support this otherwise a conditional breakpoint can mask a
 		// breakpoint set by next or step.
-		if (kind != UserBreakpoint && bp.Kind != UserBreakpoint) || (kind == UserBreakpoint && bp.IsUser()) {
+		// Freeze breakpoints can overlap with both.
+		switch {
+		case kind == FreezeBreakpoint:
+			if bp.Kind&FreezeBreakpoint != 0 {
+				return bp, BreakpointExistsError{bp.File, bp.Line, bp.Addr}
+			}
+			bp.Kind |= kind
+			return bp, nil
+		case (kind != UserBreakpoint && bp.IsInternal()) || (kind == UserBreakpoint && bp.IsUser()):
 			return bp, BreakpointExistsError{bp.File, bp.Line, bp.Addr}
 		}
 		bp.Kind |= kind
@@ -341,7 +360,7 @@ func (t *Target) ClearInternalBreakpoints() error {
 	bpmap := t.Breakpoints()
 	threads := t.ThreadList()
 	for addr, bp := range bpmap.M {
-		bp.Kind = bp.Kind & UserBreakpoint
+		bp.Kind = bp.Kind & (UserBreakpoint | FreezeBreakpoint)
 		bp.internalCond = nil
 		bp.returnInfo = nil
 		if bp.Kind != 0 {
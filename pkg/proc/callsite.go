@@ -0,0 +1,137 @@
+package proc
+
+import (
+	"bytes"
+	"debug/dwarf"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+// Call site tags and attributes, the GNU variants are used by GCC before
+// DWARFv5.
+const (
+	dwarfTagCallSite             dwarf.Tag  = 0x48   // debug/dwarf.TagCallSite in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfTagCallSiteParameter    dwarf.Tag  = 0x49   // debug/dwarf.TagCallSiteParameter in Go 1.14
+	dwarfTagGNUCallSite          dwarf.Tag  = 0x4109 // DW_TAG_GNU_call_site
+	dwarfTagGNUCallSiteParameter dwarf.Tag  = 0x410a // DW_TAG_GNU_call_site_parameter
+	dwarfAttrCallReturnPC        dwarf.Attr = 0x7d   // debug/dwarf.AttrCallReturnPC in Go 1.14
+	dwarfAttrCallValue           dwarf.Attr = 0x7e   // debug/dwarf.AttrCallValue in Go 1.14
+	dwarfAttrGNUCallSiteValue    dwarf.Attr = 0x2111 // DW_AT_GNU_call_site_value
+)
+
+// optimizedOutError is the error returned when the location of a variable
+// isn't available at the current PC, because the compiler optimized it
+// away.
+type optimizedOutError struct {
+	msg string
+}
+
+func (err *optimizedOutError) Error() string {
+	return err.msg
+}
+
+// isOptimizedOut returns true if err means that the variable being read
+// was optimized away.
+func isOptimizedOut(err error) bool {
+	if err == op.ErrEntryValueUnavailable {
+		return true
+	}
+	_, ok := err.(*optimizedOutError)
+	return ok
+}
+
+// callSiteEntryValue returns the value that the register described by
+// expr had on entry to the function that returns to retaddr, by evaluating
+// the DW_AT_call_value attribute of the corresponding call site parameter
+// in caller.
+func (bi *BinaryInfo) callSiteEntryValue(retaddr uint64, caller *Stackframe, expr []byte) (int64, error) {
+	regnum, ok := entryValueRegister(expr)
+	if !ok {
+		return 0, op.ErrEntryValueUnavailable
+	}
+	fn := caller.Current.Fn
+	if fn == nil || fn.cu == nil {
+		return 0, op.ErrEntryValueUnavailable
+	}
+	image := fn.cu.image
+	tree, err := image.getDwarfTree(fn.offset)
+	if err != nil {
+		return 0, op.ErrEntryValueUnavailable
+	}
+	site := findCallSite(tree, retaddr-image.StaticBase)
+	if site == nil {
+		return 0, op.ErrEntryValueUnavailable
+	}
+	for _, param := range site.Children {
+		if param.Tag != dwarfTagCallSiteParameter && param.Tag != dwarfTagGNUCallSiteParameter {
+			continue
+		}
+		loc, _ := param.Val(dwarf.AttrLocation).([]byte)
+		if paramReg, ok := entryValueRegister(loc); !ok || paramReg != regnum {
+			continue
+		}
+		valueExpr, ok := param.Val(dwarfAttrCallValue).([]byte)
+		if !ok {
+			valueExpr, ok = param.Val(dwarfAttrGNUCallSiteValue).([]byte)
+		}
+		if !ok {
+			return 0, op.ErrEntryValueUnavailable
+		}
+		regs := caller.Regs
+		regs.EntryValue = nil
+		val, pieces, err := op.ExecuteStackProgram(regs, valueExpr, bi.Arch.PtrSize())
+		if err != nil {
+			return 0, err
+		}
+		if len(pieces) == 1 && pieces[0].IsValue {
+			return pieces[0].Value, nil
+		}
+		if len(pieces) > 1 {
+			return 0, op.ErrEntryValueUnavailable
+		}
+		return val, nil
+	}
+	return 0, op.ErrEntryValueUnavailable
+}
+
+// findCallSite returns the call site, inside tree, whose return address is
+// retaddr (not relocated).
+func findCallSite(tree *godwarf.Tree, retaddr uint64) *godwarf.Tree {
+	for _, child := range tree.Children {
+		switch child.Tag {
+		case dwarfTagCallSite:
+			if pc, _ := child.Val(dwarfAttrCallReturnPC).(uint64); pc == retaddr {
+				return child
+			}
+		case dwarfTagGNUCallSite:
+			if pc, _ := child.Val(dwarf.AttrLowpc).(uint64); pc == retaddr {
+				return child
+			}
+		default:
+			if site := findCallSite(child, retaddr); site != nil {
+				return site
+			}
+		}
+	}
+	return nil
+}
+
+// entryValueRegister returns the register number described by expr, if
+// expr is a single DW_OP_regN or DW_OP_regx operation.
+func entryValueRegister(expr []byte) (uint64, bool) {
+	if len(expr) == 0 {
+		return 0, false
+	}
+	opcode := op.Opcode(expr[0])
+	switch {
+	case len(expr) == 1 && opcode >= op.DW_OP_reg0 && opcode <= op.DW_OP_reg31:
+		return uint64(opcode - op.DW_OP_reg0), true
+	case opcode == op.DW_OP_regx:
+		buf := bytes.NewBuffer(expr[1:])
+		regnum, _ := util.DecodeULEB128(buf)
+		return regnum, buf.Len() == 0
+	}
+	return 0, false
+}
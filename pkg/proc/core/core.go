@@ -154,6 +154,10 @@ type process struct {
 
 	entryPoint uint64
 
+	// solibs are the shared objects mapped in the address space of the
+	// process that were found on this machine.
+	solibs []*sharedObject
+
 	bi            *proc.BinaryInfo
 	breakpoints   proc.BreakpointMap
 	currentThread *thread
@@ -188,7 +192,7 @@ var (
 	ErrChangeRegisterCore = errors.New("can not change register values of core process")
 )
 
-type openFn func(string, string) (*process, error)
+type openFn func(string, string, *libraryLocator) (*process, error)
 
 var openFns = []openFn{readLinuxCore, readAMD64Minidump}
 
@@ -199,11 +203,15 @@ var ErrUnrecognizedFormat = errors.New("unrecognized core format")
 // OpenCore will open the core file and return a Process struct.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func OpenCore(corePath, exePath string, debugInfoDirs []string) (*proc.Target, error) {
+// Shared objects mapped by the process are looked up under sysroot and in
+// the directories listed in solibSearchPath, if sysroot is empty they are
+// also looked up at their original path.
+func OpenCore(corePath, exePath string, debugInfoDirs []string, bicache proc.BinaryInfoCacheConfig, sysroot string, solibSearchPath []string) (*proc.Target, error) {
 	var p *process
 	var err error
+	libs := &libraryLocator{sysroot: sysroot, searchPath: solibSearchPath}
 	for _, openFn := range openFns {
-		p, err = openFn(corePath, exePath)
+		p, err = openFn(corePath, exePath, libs)
 		if err != ErrUnrecognizedFormat {
 			break
 		}
@@ -212,11 +220,22 @@ func OpenCore(corePath, exePath string, debugInfoDirs []string) (*proc.Target, e
 		return nil, err
 	}
 
-	return proc.NewTarget(p, proc.NewTargetConfig{
+	t, err := proc.NewTarget(p, proc.NewTargetConfig{
 		Path:                exePath,
 		DebugInfoDirs:       debugInfoDirs,
+		BinaryInfoCache:     bicache,
 		DisableAsyncPreempt: false,
 		StopReason:          proc.StopAttached})
+	if err != nil {
+		p.closeSharedObjects()
+		return nil, err
+	}
+	for _, so := range p.solibs {
+		// Errors loading a shared object are recorded in its image and
+		// reported by the 'libraries' command.
+		p.bi.AddImage(so.path, so.staticBase)
+	}
+	return t, nil
 }
 
 // BinInfo will return the binary info.
@@ -405,13 +424,21 @@ func (p *process) CurrentThread() proc.Thread {
 	return p.currentThread
 }
 
-// Detach will always return nil and have no
-// effect as you cannot detach from a core file
-// and have it continue execution or exit.
+// Detach closes the shared objects opened to read the memory of the core
+// file, it has no other effect as you cannot detach from a core file and
+// have it continue execution or exit.
 func (p *process) Detach(bool) error {
+	p.closeSharedObjects()
 	return nil
 }
 
+func (p *process) closeSharedObjects() {
+	for _, so := range p.solibs {
+		so.file.Close()
+	}
+	p.solibs = nil
+}
+
 // Valid returns whether the process is active. Always returns true
 // for core files as it cannot exit or be otherwise detached from.
 func (p *process) Valid() (bool, error) {
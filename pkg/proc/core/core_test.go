@@ -17,6 +17,7 @@ import (
 
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/native"
 	"github.com/go-delve/delve/pkg/proc/test"
 )
 
@@ -173,7 +174,7 @@ func withCoreFile(t *testing.T, name, args string) *proc.Target {
 	}
 	corePath := cores[0]
 
-	p, err := OpenCore(corePath, fix.Path, []string{})
+	p, err := OpenCore(corePath, fix.Path, []string{}, proc.BinaryInfoCacheConfig{}, "", nil)
 	if err != nil {
 		t.Errorf("OpenCore(%q) failed: %v", corePath, err)
 		pat, err := ioutil.ReadFile("/proc/sys/kernel/core_pattern")
@@ -383,6 +384,55 @@ mainSearch:
 	t.Logf("s = %#v\n", v2)
 }
 
+func TestCoreSharedObjects(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return
+	}
+	pluginFixtures := test.WithPlugins(t, test.AllNonOptimized, "plugin1/", "plugin2/")
+	// The first call to runtime.Breakpoint, after plugin1 is loaded, crashes
+	// the fixture when it isn't running under a debugger.
+	p := withCoreFile(t, "plugintest", pluginFixtures[0].Path+" "+pluginFixtures[1].Path)
+
+	var plugin1 *proc.Image
+	for _, image := range p.BinInfo().Images {
+		t.Logf("%#x %q err:%v", image.StaticBase, image.Path, image.LoadError())
+		if image.Path == pluginFixtures[0].Path {
+			plugin1 = image
+		}
+	}
+	if plugin1 == nil {
+		t.Fatalf("could not find plugin1")
+	}
+	assertNoError(plugin1.LoadError(), t, "loading plugin1")
+
+	var fn1 *proc.Function
+	for i := range p.BinInfo().Functions {
+		fn := &p.BinInfo().Functions[i]
+		if strings.HasSuffix(fn.Name, ".Fn1") && p.BinInfo().PCToImage(fn.Entry) == plugin1 {
+			fn1 = fn
+		}
+	}
+	if fn1 == nil {
+		t.Fatalf("could not find Fn1 in plugin1")
+	}
+	if file, _, _ := p.BinInfo().PCToLine(fn1.Entry); filepath.Base(file) != "plugin1.go" {
+		t.Errorf("Fn1 at %#x is in %q, expected plugin1.go", fn1.Entry, file)
+	}
+	if fn := p.BinInfo().PCToFunc(fn1.Entry); fn != fn1 {
+		t.Errorf("PCToFunc(%#x) = %v, expected %s", fn1.Entry, fn, fn1.Name)
+	}
+
+	// The code of Fn1 is read from the local copy of plugin1.
+	buf := make([]byte, 8)
+	_, err := p.CurrentThread().ReadMemory(buf, fn1.Entry)
+	assertNoError(err, t, "ReadMemory")
+	if bytes.Equal(buf, make([]byte, len(buf))) {
+		t.Errorf("code of Fn1 at %#x not readable: %x", fn1.Entry, buf)
+	}
+
+	assertNoError(p.Detach(false), t, "Detach")
+}
+
 func TestMinidump(t *testing.T) {
 	if runtime.GOOS != "windows" {
 		t.Skip("minidumps can only be produced on windows")
@@ -394,7 +444,7 @@ func TestMinidump(t *testing.T) {
 	fix := test.BuildFixture("sleep", buildFlags)
 	mdmpPath := procdump(t, fix.Path)
 
-	p, err := OpenCore(mdmpPath, fix.Path, []string{})
+	p, err := OpenCore(mdmpPath, fix.Path, []string{}, proc.BinaryInfoCacheConfig{}, "", nil)
 	if err != nil {
 		t.Fatalf("OpenCore: %v", err)
 	}
@@ -467,3 +517,171 @@ func procdump(t *testing.T, exePath string) string {
 	t.Fatalf("could not find dump file")
 	return ""
 }
+
+func TestDump(t *testing.T) {
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("dump not supported on this platform")
+	}
+	var buildFlags test.BuildFlags
+	if buildMode == "pie" {
+		buildFlags = test.BuildModePIE
+	}
+	fix := test.BuildFixture("goroutinestackprog", buildFlags)
+	p, err := native.Launch([]string{fix.Path}, ".", false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
+	assertNoError(err, t, "Launch")
+	defer p.Detach(true)
+
+	addrs, err := proc.FindFunctionLocation(p, "main.stacktraceme", 0)
+	assertNoError(err, t, "FindFunctionLocation")
+	_, err = p.SetBreakpoint(addrs[0], proc.UserBreakpoint, nil)
+	assertNoError(err, t, "SetBreakpoint")
+	assertNoError(p.Continue(), t, "Continue")
+
+	wantGs, _, err := proc.GoroutinesInfo(p, 0, 0)
+	assertNoError(err, t, "GoroutinesInfo")
+	wantRegs, err := p.CurrentThread().Registers()
+	assertNoError(err, t, "Registers")
+
+	tempDir, err := ioutil.TempDir("", "")
+	assertNoError(err, t, "TempDir")
+	test.PathsToRemove = append(test.PathsToRemove, tempDir)
+	corePath := filepath.Join(tempDir, "core")
+	fh, err := os.Create(corePath)
+	assertNoError(err, t, "Create")
+	state := &DumpState{DoneChan: make(chan struct{})}
+	assertNoError(Dump(p, fh, state), t, "Dump")
+	assertNoError(fh.Close(), t, "Close")
+	if state.ThreadsDone != state.ThreadsTotal || state.MemDone != state.MemTotal {
+		t.Errorf("incomplete dump state: %#v", state)
+	}
+
+	c, err := OpenCore(corePath, fix.Path, []string{}, proc.BinaryInfoCacheConfig{}, "", nil)
+	assertNoError(err, t, "OpenCore")
+
+	if len(c.ThreadList()) != len(p.ThreadList()) {
+		t.Errorf("thread count mismatch: core %d live %d", len(c.ThreadList()), len(p.ThreadList()))
+	}
+	regs, err := c.CurrentThread().Registers()
+	assertNoError(err, t, "core Registers")
+	if regs.PC() != wantRegs.PC() || regs.SP() != wantRegs.SP() {
+		t.Errorf("current thread registers mismatch: core %#x/%#x live %#x/%#x", regs.PC(), regs.SP(), wantRegs.PC(), wantRegs.SP())
+	}
+
+	gs, _, err := proc.GoroutinesInfo(c, 0, 0)
+	assertNoError(err, t, "core GoroutinesInfo")
+	if len(gs) != len(wantGs) {
+		t.Errorf("goroutine count mismatch: core %d live %d", len(gs), len(wantGs))
+	}
+	for _, g := range gs {
+		if _, err := g.Stacktrace(10, 0); err != nil {
+			t.Errorf("Stacktrace() on goroutine %d = %v", g.ID, err)
+		}
+	}
+}
+
+func TestReadNTFile(t *testing.T) {
+	mappings := []dumpMapping{
+		{start: 0x400000, end: 0x401000, offset: 0, filename: "/usr/bin/prog"},
+		{start: 0x7f0000000000, end: 0x7f0000002000, offset: 0, filename: "/lib/libc.so.6"},
+		{start: 0x7f0000002000, end: 0x7f0000003000, offset: 0x2000, filename: "/lib/libc.so.6"},
+		{start: 0x7f0000010000, end: 0x7f0000011000, offset: 0, filename: "[heap]"},
+	}
+	var buf bytes.Buffer
+	writeDumpNote(&buf, "CORE", _NT_FILE, dumpNTFile(mappings))
+	note, err := readNote(bytes.NewReader(buf.Bytes()), _EM_X86_64)
+	assertNoError(err, t, "readNote")
+	data := note.Desc.(*linuxNTFile)
+	if len(data.entries) != 3 {
+		t.Fatalf("wrong number of entries %d", len(data.entries))
+	}
+	for i, entry := range data.entries {
+		m := mappings[i]
+		if entry.Start != m.start || entry.End != m.end || entry.FileOfs*data.PageSize != m.offset || entry.Filename != m.filename {
+			t.Errorf("entry %d mismatch: %#v %#v", i, entry, m)
+		}
+	}
+	if name := data.exeFilename(0x400100); name != "/usr/bin/prog" {
+		t.Errorf("wrong executable name %q", name)
+	}
+}
+
+func TestLibraryLocator(t *testing.T) {
+	sysroot, err := ioutil.TempDir("", "")
+	assertNoError(err, t, "TempDir")
+	defer os.RemoveAll(sysroot)
+	searchDir, err := ioutil.TempDir("", "")
+	assertNoError(err, t, "TempDir")
+	defer os.RemoveAll(searchDir)
+
+	assertNoError(os.MkdirAll(filepath.Join(sysroot, "lib"), 0755), t, "MkdirAll")
+	assertNoError(ioutil.WriteFile(filepath.Join(sysroot, "lib", "libfoo.so"), nil, 0644), t, "WriteFile")
+	assertNoError(ioutil.WriteFile(filepath.Join(searchDir, "libbar.so"), nil, 0644), t, "WriteFile")
+
+	libs := &libraryLocator{sysroot: sysroot, searchPath: []string{searchDir}}
+	for _, tc := range []struct {
+		filename, tgt string
+	}{
+		{"/lib/libfoo.so", filepath.Join(sysroot, "lib", "libfoo.so")},
+		{"/usr/lib/libbar.so", filepath.Join(searchDir, "libbar.so")},
+		{"/lib/libbaz.so", ""},
+	} {
+		if path := libs.find(tc.filename, nil); path != tc.tgt {
+			t.Errorf("find(%q) = %q, expected %q", tc.filename, path, tc.tgt)
+		}
+	}
+}
+
+func TestFindBuildIDNote(t *testing.T) {
+	var buf bytes.Buffer
+	writeDumpNote(&buf, "GNU", 1, []byte{1, 2, 3, 4})
+	writeDumpNote(&buf, "GNU", 3, []byte{0xde, 0xad, 0xbe, 0xef, 0x01})
+	if id := findBuildIDNote(buf.Bytes()); !bytes.Equal(id, []byte{0xde, 0xad, 0xbe, 0xef, 0x01}) {
+		t.Errorf("wrong build-id %x", id)
+	}
+	if id := findBuildIDNote(buf.Bytes()[:buf.Len()-4]); id != nil {
+		t.Errorf("build-id found in truncated note %x", id)
+	}
+}
+
+func TestOpenExecutable(t *testing.T) {
+	var buildFlags test.BuildFlags
+	if buildMode == "pie" {
+		buildFlags = test.BuildModePIE
+	}
+	fix := test.BuildFixture("issue1615", buildFlags)
+	p, err := OpenExecutable(fix.Path, []string{}, proc.BinaryInfoCacheConfig{})
+	assertNoError(err, t, "OpenExecutable")
+	defer p.Detach(false)
+
+	if threads := p.ThreadList(); len(threads) != 0 {
+		t.Errorf("expected no threads, got %d", len(threads))
+	}
+
+	pcs, err := proc.FindFunctionLocation(p, "main.f", 0)
+	assertNoError(err, t, "FindFunctionLocation")
+	bp, err := p.SetBreakpoint(pcs[0], proc.UserBreakpoint, nil)
+	assertNoError(err, t, "SetBreakpoint")
+	if bp.FunctionName != "main.f" || bp.Line != 13 {
+		t.Errorf("wrong breakpoint location %s:%d in %s", bp.File, bp.Line, bp.FunctionName)
+	}
+
+	v, err := proc.PackageScope(p).EvalVariable("strings[1]", proc.LoadConfig{MaxStringLen: 64})
+	assertNoError(err, t, "EvalVariable")
+	if s := constant.StringVal(v.Value); s != "two" {
+		t.Errorf("wrong value for strings[1]: %q", s)
+	}
+
+	// runtime.ncpu is zero-initialized and lives in .bss
+	v, err = proc.PackageScope(p).EvalVariable("runtime.ncpu", proc.LoadConfig{})
+	assertNoError(err, t, "EvalVariable(runtime.ncpu)")
+	if n, _ := constant.Int64Val(v.Value); n != 0 {
+		t.Errorf("wrong value for runtime.ncpu: %d", n)
+	}
+
+	if err := p.Continue(); err != ErrInspectOnly {
+		t.Errorf("expected ErrInspectOnly from Continue, got %v", err)
+	}
+	if _, err := proc.ThreadScope(p.CurrentThread()); err == nil {
+		t.Errorf("expected error from ThreadScope")
+	}
+}
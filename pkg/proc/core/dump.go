@@ -0,0 +1,366 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/linutil"
+)
+
+// ErrDumpCanceled is returned by Dump when the dump is canceled.
+var ErrDumpCanceled = errors.New("core dump canceled")
+
+const (
+	dumpPageSize  = 0x1000
+	dumpChunkSize = 1024 * 1024 // maximum size of a single memory read
+)
+
+// DumpState describes the state of a core dump. All fields must be
+// accessed while holding Mutex.
+type DumpState struct {
+	Mutex sync.Mutex
+
+	Dumping  bool // a dump is in progress
+	AllDone  bool // the dump finished, Err is set if it failed
+	Canceled bool // the dump was canceled, Dump will stop as soon as possible
+
+	// DoneChan is closed when AllDone becomes true.
+	DoneChan chan struct{}
+
+	ThreadsDone, ThreadsTotal int
+	MemDone, MemTotal         uint64
+
+	Err error
+}
+
+func (state *DumpState) canceled() bool {
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+	return state.Canceled
+}
+
+// dumpMapping is a readable memory mapping of the target process, as
+// listed by /proc/<pid>/maps.
+type dumpMapping struct {
+	start, end uint64
+	perms      string
+	offset     uint64
+	filename   string
+}
+
+// Dump writes an ELF core file of the target to out, in the format read by
+// OpenCore. The core file contains a PT_LOAD segment for every readable
+// memory mapping of the target and a PT_NOTE segment containing
+// NT_PRPSINFO, NT_AUXV, NT_FILE and, for every thread, NT_PRSTATUS,
+// NT_FPREGSET and (on amd64) NT_X86_XSTATE notes.
+// The target must be stopped. Progress is reported by updating state, the
+// dump can be interrupted by setting state.Canceled.
+// Only linux targets on amd64 and arm64, debugged with the native backend,
+// are supported.
+func Dump(t *proc.Target, out io.Writer, state *DumpState) error {
+	if _, err := t.Valid(); err != nil {
+		return err
+	}
+	bi := t.BinInfo()
+	if bi.GOOS != "linux" {
+		return fmt.Errorf("core dumps are not supported on %s", bi.GOOS)
+	}
+	var machine elf.Machine
+	switch bi.Arch.Name {
+	case "amd64":
+		machine = elf.EM_X86_64
+	case "arm64":
+		machine = elf.EM_AARCH64
+	default:
+		return fmt.Errorf("core dumps are not supported on %s", bi.Arch.Name)
+	}
+
+	pid := t.Pid()
+	mappings, err := readDumpMappings(t)
+	if err != nil {
+		return err
+	}
+	auxv, err := t.ReadProcFile("auxv")
+	if err != nil {
+		return err
+	}
+
+	// The first NT_PRSTATUS note is used as the current thread when the core
+	// file is opened.
+	threads := []proc.Thread{t.CurrentThread()}
+	for _, th := range t.ThreadList() {
+		if th.ThreadID() != t.CurrentThread().ThreadID() {
+			threads = append(threads, th)
+		}
+	}
+
+	state.Mutex.Lock()
+	state.ThreadsTotal = len(threads)
+	for _, m := range mappings {
+		state.MemTotal += m.end - m.start
+	}
+	state.Mutex.Unlock()
+
+	var notes bytes.Buffer
+	writeDumpNote(&notes, "CORE", elf.NT_PRPSINFO, dumpPrPsInfo(pid, bi.Images[0].Path))
+	for _, th := range threads {
+		if err := writeDumpThreadNotes(&notes, th); err != nil {
+			return fmt.Errorf("thread %d: %v", th.ThreadID(), err)
+		}
+		state.Mutex.Lock()
+		state.ThreadsDone++
+		state.Mutex.Unlock()
+	}
+	writeDumpNote(&notes, "CORE", _NT_AUXV, auxv)
+	writeDumpNote(&notes, "CORE", _NT_FILE, dumpNTFile(mappings))
+
+	// Layout: ELF header, program headers, notes and, starting at the next
+	// page boundary, the contents of each mapping.
+	const ehsize, phentsize = 64, 56
+	phnum := 1 + len(mappings)
+	notesOff := uint64(ehsize + phnum*phentsize)
+	dataOff := (notesOff + uint64(notes.Len()) + dumpPageSize - 1) &^ (dumpPageSize - 1)
+
+	w := bufio.NewWriter(out)
+	le := binary.LittleEndian
+
+	ehdr := elf.Header64{
+		Type:      uint16(elf.ET_CORE),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     ehsize,
+		Ehsize:    ehsize,
+		Phentsize: phentsize,
+		Phnum:     uint16(phnum),
+	}
+	copy(ehdr.Ident[:], elf.ELFMAG)
+	ehdr.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	ehdr.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	ehdr.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	ehdr.Ident[elf.EI_OSABI] = byte(elf.ELFOSABI_NONE)
+	if err := binary.Write(w, le, &ehdr); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, le, &elf.Prog64{
+		Type:   uint32(elf.PT_NOTE),
+		Off:    notesOff,
+		Filesz: uint64(notes.Len()),
+		Align:  4,
+	}); err != nil {
+		return err
+	}
+	off := dataOff
+	for _, m := range mappings {
+		var flags elf.ProgFlag
+		if strings.Contains(m.perms, "r") {
+			flags |= elf.PF_R
+		}
+		if strings.Contains(m.perms, "w") {
+			flags |= elf.PF_W
+		}
+		if strings.Contains(m.perms, "x") {
+			flags |= elf.PF_X
+		}
+		if err := binary.Write(w, le, &elf.Prog64{
+			Type:   uint32(elf.PT_LOAD),
+			Flags:  uint32(flags),
+			Off:    off,
+			Vaddr:  m.start,
+			Filesz: m.end - m.start,
+			Memsz:  m.end - m.start,
+			Align:  dumpPageSize,
+		}); err != nil {
+			return err
+		}
+		off += m.end - m.start
+	}
+
+	if _, err := w.Write(notes.Bytes()); err != nil {
+		return err
+	}
+	if _, err := w.Write(make([]byte, dataOff-notesOff-uint64(notes.Len()))); err != nil {
+		return err
+	}
+
+	mem := t.CurrentThread()
+	buf := make([]byte, dumpChunkSize)
+	for _, m := range mappings {
+		for addr := m.start; addr < m.end; {
+			if state.canceled() {
+				return ErrDumpCanceled
+			}
+			n := m.end - addr
+			if n > dumpChunkSize {
+				n = dumpChunkSize
+			}
+			readDumpMemory(mem, buf[:n], addr)
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			addr += n
+			state.Mutex.Lock()
+			state.MemDone += n
+			state.Mutex.Unlock()
+		}
+	}
+
+	return w.Flush()
+}
+
+// readDumpMemory reads len(buf) bytes at addr into buf. If the whole range
+// can not be read at once it is read one page at a time, pages that can not
+// be read are filled with zeroes.
+func readDumpMemory(mem proc.MemoryReader, buf []byte, addr uint64) {
+	if _, err := mem.ReadMemory(buf, addr); err == nil {
+		return
+	}
+	for off := 0; off < len(buf); off += dumpPageSize {
+		end := off + dumpPageSize
+		if end > len(buf) {
+			end = len(buf)
+		}
+		if _, err := mem.ReadMemory(buf[off:end], addr+uint64(off)); err != nil {
+			for i := off; i < end; i++ {
+				buf[i] = 0
+			}
+		}
+	}
+}
+
+// readDumpMappings returns the readable memory mappings of the target.
+func readDumpMappings(t *proc.Target) ([]dumpMapping, error) {
+	buf, err := t.ReadProcFile("maps")
+	if err != nil {
+		return nil, err
+	}
+	var r []dumpMapping
+	for _, line := range strings.Split(string(buf), "\n") {
+		// start-end perms offset dev inode [filename]
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		var m dumpMapping
+		addrs := strings.SplitN(fields[0], "-", 2)
+		if len(addrs) != 2 {
+			return nil, fmt.Errorf("malformed memory map entry %q", line)
+		}
+		m.start, err = strconv.ParseUint(addrs[0], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed memory map entry %q: %v", line, err)
+		}
+		m.end, err = strconv.ParseUint(addrs[1], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed memory map entry %q: %v", line, err)
+		}
+		m.perms = fields[1]
+		m.offset, err = strconv.ParseUint(fields[2], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed memory map entry %q: %v", line, err)
+		}
+		if len(fields) >= 6 {
+			m.filename = strings.Join(fields[5:], " ")
+		}
+		if !strings.HasPrefix(m.perms, "r") || m.filename == "[vvar]" {
+			// vvar can not be read through ptrace
+			continue
+		}
+		r = append(r, m)
+	}
+	return r, nil
+}
+
+func writeDumpNote(buf *bytes.Buffer, name string, typ elf.NType, desc []byte) {
+	name += "\x00"
+	binary.Write(buf, binary.LittleEndian, &elfNotesHdr{Namesz: uint32(len(name)), Descsz: uint32(len(desc)), Type: uint32(typ)})
+	buf.WriteString(name)
+	dumpPad(buf)
+	buf.Write(desc)
+	dumpPad(buf)
+}
+
+// dumpPad pads buf to a multiple of 4 bytes, as required by readNote.
+func dumpPad(buf *bytes.Buffer) {
+	for buf.Len()%4 != 0 {
+		buf.WriteByte(0)
+	}
+}
+
+func dumpPrPsInfo(pid int, path string) []byte {
+	info := linuxPrPsInfo{Pid: int32(pid)}
+	copy(info.Fname[:len(info.Fname)-1], filepath.Base(path))
+	copy(info.Args[:len(info.Args)-1], path)
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, &info)
+	return buf.Bytes()
+}
+
+func dumpNTFile(mappings []dumpMapping) []byte {
+	var entries []dumpMapping
+	for _, m := range mappings {
+		if strings.HasPrefix(m.filename, "/") {
+			entries = append(entries, m)
+		}
+	}
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	binary.Write(&buf, le, &linuxNTFileHdr{Count: uint64(len(entries)), PageSize: dumpPageSize})
+	for _, m := range entries {
+		binary.Write(&buf, le, &linuxNTFileEntryHdr{Start: m.start, End: m.end, FileOfs: m.offset / dumpPageSize})
+	}
+	for _, m := range entries {
+		buf.WriteString(m.filename)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// writeDumpThreadNotes writes the NT_PRSTATUS, NT_FPREGSET and
+// NT_X86_XSTATE notes of th to buf.
+func writeDumpThreadNotes(buf *bytes.Buffer, th proc.Thread) error {
+	regs, err := th.Registers()
+	if err != nil {
+		return err
+	}
+	// Copy loads the floating point registers.
+	regs, err = regs.Copy()
+	if err != nil {
+		return err
+	}
+	le := binary.LittleEndian
+	var prstatus, fpregset bytes.Buffer
+	switch regs := regs.(type) {
+	case *linutil.AMD64Registers:
+		binary.Write(&prstatus, le, &linuxPrStatusAMD64{Pid: int32(th.ThreadID()), Reg: *regs.Regs, Fpvalid: 1})
+		writeDumpNote(buf, "CORE", elf.NT_PRSTATUS, prstatus.Bytes())
+		if regs.Fpregset != nil {
+			binary.Write(&fpregset, le, &regs.Fpregset.AMD64PtraceFpRegs)
+			writeDumpNote(buf, "CORE", _NT_FPREGSET, fpregset.Bytes())
+			if len(regs.Fpregset.Xsave) > 0 {
+				writeDumpNote(buf, "LINUX", _NT_X86_XSTATE, regs.Fpregset.Xsave)
+			}
+		}
+	case *linutil.ARM64Registers:
+		binary.Write(&prstatus, le, &linuxPrStatusARM64{Pid: int32(th.ThreadID()), Reg: *regs.Regs, Fpvalid: 1})
+		writeDumpNote(buf, "CORE", elf.NT_PRSTATUS, prstatus.Bytes())
+		fpregset.Write(regs.Fpregset)
+		for fpregset.Len() < _ARM_FP_HEADER_START {
+			fpregset.WriteByte(0)
+		}
+		writeDumpNote(buf, "CORE", _NT_FPREGSET, fpregset.Bytes())
+	default:
+		return fmt.Errorf("unsupported registers type %T", regs)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package core
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+// ErrInspectOnly is returned when trying to execute, or otherwise change
+// the state of, a target opened with OpenExecutable.
+var ErrInspectOnly = errors.New("can not execute the program: the executable was opened for static inspection only")
+
+// ErrNoThreads is returned when trying to read the registers of a target
+// opened with OpenExecutable.
+var ErrNoThreads = errors.New("no threads: the executable was opened for static inspection only")
+
+// executableProcess is a process backed only by the executable image: it
+// has no threads and its memory is the contents of the loadable segments
+// of the executable file.
+// Its current thread is a placeholder, not listed by ThreadList, that can
+// be used to read memory but has no registers.
+// Breakpoints are resolved and recorded but never written to memory.
+type executableProcess struct {
+	process
+	closer io.Closer
+}
+
+var _ proc.ProcessInternal = &executableProcess{}
+
+// OpenExecutable opens the executable file at exePath for static
+// inspection, without running it.
+// The returned target has no threads, execution commands will fail with
+// ErrInspectOnly.
+func OpenExecutable(exePath string, debugInfoDirs []string, bicache proc.BinaryInfoCacheConfig) (*proc.Target, error) {
+	f, err := os.Open(exePath)
+	if err != nil {
+		return nil, err
+	}
+	p, err := readExecutable(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	p.closer = f
+
+	t, err := proc.NewTarget(p, proc.NewTargetConfig{
+		Path:                exePath,
+		DebugInfoDirs:       debugInfoDirs,
+		BinaryInfoCache:     bicache,
+		DisableAsyncPreempt: false,
+		StopReason:          proc.StopUnknown})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// readExecutable builds an executableProcess for the executable file f,
+// mapping the contents of its loadable segments at their virtual
+// addresses. The part of a segment that isn't backed by the file (for
+// example .bss) reads as zeros.
+func readExecutable(f *os.File) (*executableProcess, error) {
+	mem := &splicedMemory{}
+	var goos, goarch string
+
+	if exe, err := elf.NewFile(f); err == nil {
+		if exe.Type != elf.ET_EXEC && exe.Type != elf.ET_DYN {
+			return nil, fmt.Errorf("%s is not an executable file", f.Name())
+		}
+		goos = "linux"
+		switch exe.Machine {
+		case elf.EM_X86_64:
+			goarch = "amd64"
+		case elf.EM_AARCH64:
+			goarch = "arm64"
+		case elf.EM_386:
+			goarch = "386"
+		}
+		for _, prog := range exe.Progs {
+			if prog.Type == elf.PT_LOAD {
+				mem.Add(zeroReader{}, prog.Vaddr, prog.Memsz)
+				mem.Add(&offsetReaderAt{prog.ReaderAt, prog.Vaddr}, prog.Vaddr, prog.Filesz)
+			}
+		}
+	} else if exe, err := macho.NewFile(f); err == nil {
+		goos = "darwin"
+		switch exe.Cpu {
+		case macho.CpuAmd64:
+			goarch = "amd64"
+		case macho.CpuArm64:
+			goarch = "arm64"
+		}
+		for _, load := range exe.Loads {
+			if seg, ok := load.(*macho.Segment); ok {
+				mem.Add(zeroReader{}, seg.Addr, seg.Memsz)
+				mem.Add(&offsetReaderAt{seg.ReaderAt, seg.Addr}, seg.Addr, seg.Filesz)
+			}
+		}
+	} else if exe, err := pe.NewFile(f); err == nil {
+		goos = "windows"
+		var imageBase uint64
+		switch oh := exe.OptionalHeader.(type) {
+		case *pe.OptionalHeader64:
+			imageBase = oh.ImageBase
+		case *pe.OptionalHeader32:
+			imageBase = uint64(oh.ImageBase)
+		}
+		switch exe.Machine {
+		case pe.IMAGE_FILE_MACHINE_AMD64:
+			goarch = "amd64"
+		case pe.IMAGE_FILE_MACHINE_I386:
+			goarch = "386"
+		}
+		for _, sect := range exe.Sections {
+			addr := imageBase + uint64(sect.VirtualAddress)
+			size := uint64(sect.Size)
+			if sect.VirtualSize != 0 && uint64(sect.VirtualSize) < size {
+				// Size is rounded up to the file alignment, the bytes past
+				// VirtualSize aren't part of the section.
+				size = uint64(sect.VirtualSize)
+			}
+			mem.Add(zeroReader{}, addr, uint64(sect.VirtualSize))
+			mem.Add(&offsetReaderAt{sect.ReaderAt, addr}, addr, size)
+		}
+	} else {
+		return nil, fmt.Errorf("could not open %s: unrecognized executable format", f.Name())
+	}
+
+	if goarch == "" {
+		return nil, fmt.Errorf("could not open %s: unsupported architecture", f.Name())
+	}
+
+	p := &executableProcess{
+		process: process{
+			mem:         mem,
+			Threads:     map[int]*thread{},
+			bi:          proc.NewBinaryInfo(goos, goarch),
+			breakpoints: proc.NewBreakpointMap(),
+		},
+	}
+	p.currentThread = &thread{th: noThread{}, p: &p.process}
+	return p, nil
+}
+
+// noThread is the osThread of the placeholder thread of an
+// executableProcess.
+type noThread struct{}
+
+func (noThread) registers() (proc.Registers, error) { return nil, ErrNoThreads }
+
+func (noThread) pid() int { return 0 }
+
+// WriteBreakpoint resolves the location of addr without writing anything to
+// memory, so that breakpoints can be used to check where they would be set.
+func (p *executableProcess) WriteBreakpoint(addr uint64) (file string, line int, fn *proc.Function, originalData []byte, err error) {
+	file, line, fn = p.bi.PCToLine(addr)
+	if fn == nil {
+		return "", 0, nil, nil, proc.InvalidAddressError{Address: addr}
+	}
+	return file, line, fn, nil, nil
+}
+
+// EraseBreakpoint always succeeds since breakpoints are never written to
+// memory.
+func (p *executableProcess) EraseBreakpoint(bp *proc.Breakpoint) error {
+	return nil
+}
+
+// Recorded returns false, there is no recording associated with the
+// executable.
+func (p *executableProcess) Recorded() (bool, string) { return false, "" }
+
+// Restart always returns ErrInspectOnly.
+func (p *executableProcess) Restart(string) error { return ErrInspectOnly }
+
+// ChangeDirection always returns ErrInspectOnly.
+func (p *executableProcess) ChangeDirection(proc.Direction) error { return ErrInspectOnly }
+
+// Checkpoint always returns ErrInspectOnly.
+func (p *executableProcess) Checkpoint(string) (int, error) { return -1, ErrInspectOnly }
+
+// ContinueOnce always returns ErrInspectOnly.
+func (p *executableProcess) ContinueOnce() (proc.Thread, proc.StopReason, error) {
+	return nil, proc.StopUnknown, ErrInspectOnly
+}
+
+// StepInstruction always returns ErrInspectOnly.
+func (p *executableProcess) StepInstruction() error {
+	return ErrInspectOnly
+}
+
+// Detach closes the executable file.
+func (p *executableProcess) Detach(bool) error {
+	return p.closer.Close()
+}
+
+// zeroReader is a MemoryReader that reads zeros at every address, it is
+// used for the parts of loadable segments that are not backed by the
+// executable file.
+type zeroReader struct{}
+
+// ReadMemory implements MemoryReader.ReadMemory.
+func (zeroReader) ReadMemory(buf []byte, addr uint64) (n int, err error) {
+	for i := range buf {
+		buf[i] = 0
+	}
+	return len(buf), nil
+}
@@ -87,7 +87,7 @@ func linuxThreadsFromNotes(p *process, notes []*note, machineType elf.Machine) {
 // http://uhlo.blogspot.fr/2012/05/brief-look-into-core-dumps.html,
 // elf_core_dump in http://lxr.free-electrons.com/source/fs/binfmt_elf.c,
 // and, if absolutely desperate, readelf.c from the binutils source.
-func readLinuxCore(corePath, exePath string) (*process, error) {
+func readLinuxCore(corePath, exePath string, libs *libraryLocator) (*process, error) {
 	coreFile, err := elf.Open(corePath)
 	if err != nil {
 		if _, isfmterr := err.(*elf.FormatError); isfmterr && (strings.Contains(err.Error(), elfErrorBadMagicNumber) || strings.Contains(err.Error(), " at offset 0x0: too short")) {
@@ -117,7 +117,6 @@ func readLinuxCore(corePath, exePath string) (*process, error) {
 	if err != nil {
 		return nil, err
 	}
-	memory := buildMemory(coreFile, exeELF, exe, notes)
 
 	// TODO support 386
 	var bi *proc.BinaryInfo
@@ -131,11 +130,14 @@ func readLinuxCore(corePath, exePath string) (*process, error) {
 	}
 
 	entryPoint := findEntryPoint(notes, bi.Arch.PtrSize())
+	solibs := findSharedObjects(coreFile, notes, entryPoint, libs)
+	memory := buildMemory(coreFile, exeELF, exe, notes, entryPoint, solibs)
 
 	p := &process{
 		mem:         memory,
 		Threads:     map[int]*thread{},
 		entryPoint:  entryPoint,
+		solibs:      solibs,
 		bi:          bi,
 		breakpoints: proc.NewBreakpointMap(),
 	}
@@ -262,18 +264,27 @@ func readNote(r io.ReadSeeker, machineType elf.Machine) (*note, error) {
 		// No good documentation reference, but the structure is
 		// simply a header, including entry count, followed by that
 		// many entries, and then the file name of each entry,
-		// null-delimited. Not reading the names here.
+		// null-delimited.
 		data := &linuxNTFile{}
 		if err := binary.Read(descReader, binary.LittleEndian, &data.linuxNTFileHdr); err != nil {
 			return nil, fmt.Errorf("reading NT_FILE header: %v", err)
 		}
 		for i := 0; i < int(data.Count); i++ {
 			entry := &linuxNTFileEntry{}
-			if err := binary.Read(descReader, binary.LittleEndian, entry); err != nil {
+			if err := binary.Read(descReader, binary.LittleEndian, &entry.linuxNTFileEntryHdr); err != nil {
 				return nil, fmt.Errorf("reading NT_FILE entry %v: %v", i, err)
 			}
 			data.entries = append(data.entries, entry)
 		}
+		names := desc[len(desc)-descReader.Len():]
+		for _, entry := range data.entries {
+			i := bytes.IndexByte(names, 0)
+			if i < 0 {
+				return nil, fmt.Errorf("reading NT_FILE file names: missing terminator")
+			}
+			entry.Filename = string(names[:i])
+			names = names[i+1:]
+		}
 		note.Desc = data
 	case _NT_X86_XSTATE:
 		if machineType == _EM_X86_64 {
@@ -316,21 +327,37 @@ func skipPadding(r io.ReadSeeker, pad int64) error {
 	return nil
 }
 
-func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note) proc.MemoryReader {
+func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note, entryPoint uint64, solibs []*sharedObject) proc.MemoryReader {
 	memory := &splicedMemory{}
 
-	// For now, assume all file mappings are to the exe.
+	// Map the file backed regions of the address space to the executable and
+	// to the shared objects we could find on this machine.
 	for _, note := range notes {
-		if note.Type == _NT_FILE {
-			fileNote := note.Desc.(*linuxNTFile)
-			for _, entry := range fileNote.entries {
-				r := &offsetReaderAt{
-					reader: exe,
-					offset: entry.Start - (entry.FileOfs * fileNote.PageSize),
+		if note.Type != _NT_FILE {
+			continue
+		}
+		fileNote := note.Desc.(*linuxNTFile)
+		exeName := fileNote.exeFilename(entryPoint)
+		for _, entry := range fileNote.entries {
+			var reader io.ReaderAt
+			if entry.Filename == exeName {
+				reader = exe
+			} else {
+				for _, so := range solibs {
+					if so.filename == entry.Filename {
+						reader = so.file
+						break
+					}
 				}
-				memory.Add(r, entry.Start, entry.End-entry.Start)
 			}
-
+			if reader == nil {
+				continue
+			}
+			r := &offsetReaderAt{
+				reader: reader,
+				offset: entry.Start - (entry.FileOfs * fileNote.PageSize),
+			}
+			memory.Add(r, entry.Start, entry.End-entry.Start)
 		}
 	}
 
@@ -419,6 +446,21 @@ type linuxNTFile struct {
 	entries []*linuxNTFileEntry
 }
 
+// exeFilename returns the name of the file mapped at entryPoint, which is
+// the executable of the process. If the entry point is unknown the first
+// mapped file is assumed to be the executable.
+func (data *linuxNTFile) exeFilename(entryPoint uint64) string {
+	for _, entry := range data.entries {
+		if entryPoint >= entry.Start && entryPoint < entry.End {
+			return entry.Filename
+		}
+	}
+	if len(data.entries) > 0 {
+		return data.entries[0].Filename
+	}
+	return ""
+}
+
 // LinuxNTFileHdr is a header struct for NTFile.
 type linuxNTFileHdr struct {
 	Count    uint64
@@ -427,6 +469,13 @@ type linuxNTFileHdr struct {
 
 // LinuxNTFileEntry is an entry of an NT_FILE note.
 type linuxNTFileEntry struct {
+	linuxNTFileEntryHdr
+	Filename string
+}
+
+// linuxNTFileEntryHdr is the fixed size part of an NT_FILE entry, the file
+// names follow the last entry.
+type linuxNTFileEntryHdr struct {
 	Start   uint64
 	End     uint64
 	FileOfs uint64
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// libraryLocator finds, on this machine, the shared objects that were
+// mapped by the process when the core file was created.
+type libraryLocator struct {
+	// sysroot is prepended to the original path of each shared object.
+	sysroot string
+	// searchPath is a list of directories searched for the base name of
+	// each shared object.
+	searchPath []string
+}
+
+// find returns the absolute path of the local copy of the shared object
+// originally located at filename, or the empty string if none was found.
+// If match is not nil candidates for which it returns false are skipped.
+func (libs *libraryLocator) find(filename string, match func(path string) bool) string {
+	candidates := []string{}
+	if libs.sysroot != "" {
+		candidates = append(candidates, filepath.Join(libs.sysroot, filename))
+	}
+	for _, dir := range libs.searchPath {
+		candidates = append(candidates, filepath.Join(dir, filepath.Base(filename)))
+	}
+	if libs.sysroot == "" {
+		candidates = append(candidates, filename)
+	}
+	for _, path := range candidates {
+		fi, err := os.Stat(path)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		if match != nil && !match(path) {
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs
+		}
+	}
+	return ""
+}
+
+// sharedObject is a shared object mapped in the address space of the
+// process.
+type sharedObject struct {
+	filename   string   // path of the shared object on the machine that created the core file
+	path       string   // path of the local copy of the shared object
+	file       *os.File // the local copy of the shared object
+	buildID    []byte   // GNU build-id of the local copy, if it has one
+	staticBase uint64   // address at which the shared object was loaded
+}
+
+// findSharedObjects returns the shared objects listed in the NT_FILE note
+// that libs can find on this machine. Files that aren't ELF shared
+// objects are ignored, as are files whose build-id is different from the
+// one of the shared object mapped in core, if the core file contains it.
+func findSharedObjects(core *elf.File, notes []*note, entryPoint uint64, libs *libraryLocator) []*sharedObject {
+	var r []*sharedObject
+	seen := make(map[string]bool)
+	for _, note := range notes {
+		if note.Type != _NT_FILE {
+			continue
+		}
+		fileNote := note.Desc.(*linuxNTFile)
+		exeName := fileNote.exeFilename(entryPoint)
+		for _, entry := range fileNote.entries {
+			if entry.FileOfs != 0 || entry.Filename == exeName || seen[entry.Filename] {
+				continue
+			}
+			seen[entry.Filename] = true
+			var so *sharedObject
+			coreBuildID := mappedBuildID(core, entry.Start, entry.End)
+			path := libs.find(entry.Filename, func(path string) bool {
+				so = openSharedObject(path, entry.Start, fileNote.PageSize)
+				if so != nil && coreBuildID != nil && !bytes.Equal(so.buildID, coreBuildID) {
+					so.file.Close()
+					so = nil
+				}
+				return so != nil
+			})
+			if path == "" {
+				continue
+			}
+			so.path = path
+			so.filename = entry.Filename
+			r = append(r, so)
+		}
+	}
+	return r
+}
+
+// openSharedObject opens the shared object at path whose first page is
+// mapped at addr.
+func openSharedObject(path string, addr, pageSize uint64) *sharedObject {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	ef, err := elf.NewFile(f)
+	if err != nil || ef.Type != elf.ET_DYN {
+		f.Close()
+		return nil
+	}
+	var buildID []byte
+	for _, prog := range ef.Progs {
+		if prog.Type != elf.PT_NOTE {
+			continue
+		}
+		buf := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(buf, 0); err == nil {
+			if buildID = findBuildIDNote(buf); buildID != nil {
+				break
+			}
+		}
+	}
+	for _, prog := range ef.Progs {
+		if prog.Type == elf.PT_LOAD && prog.Off == 0 {
+			return &sharedObject{path: path, file: f, buildID: buildID, staticBase: addr - (prog.Vaddr &^ (pageSize - 1))}
+		}
+	}
+	f.Close()
+	return nil
+}
+
+// mappedBuildID returns the build-id of the ELF file mapped between start
+// and end, read from the memory saved in core. Returns nil if core doesn't
+// contain the ELF header and notes of the file, linux only saves them if
+// bit 4 of /proc/<pid>/coredump_filter is set.
+func mappedBuildID(core *elf.File, start, end uint64) []byte {
+	mem := coreReaderAt(core, start)
+	if mem == nil {
+		return nil
+	}
+	var ehdr elf.Header64
+	if err := binary.Read(io.NewSectionReader(mem, 0, int64(end-start)), binary.LittleEndian, &ehdr); err != nil {
+		return nil
+	}
+	if !bytes.HasPrefix(ehdr.Ident[:], []byte(elf.ELFMAG)) || elf.Class(ehdr.Ident[elf.EI_CLASS]) != elf.ELFCLASS64 || elf.Data(ehdr.Ident[elf.EI_DATA]) != elf.ELFDATA2LSB {
+		return nil
+	}
+	for i := 0; i < int(ehdr.Phnum); i++ {
+		var phdr elf.Prog64
+		off := int64(ehdr.Phoff) + int64(i)*int64(ehdr.Phentsize)
+		if err := binary.Read(io.NewSectionReader(mem, off, int64(end-start)-off), binary.LittleEndian, &phdr); err != nil {
+			return nil
+		}
+		if elf.ProgType(phdr.Type) != elf.PT_NOTE || phdr.Off+phdr.Filesz > end-start {
+			continue
+		}
+		buf := make([]byte, phdr.Filesz)
+		if _, err := mem.ReadAt(buf, int64(phdr.Off)); err != nil {
+			continue
+		}
+		if buildID := findBuildIDNote(buf); buildID != nil {
+			return buildID
+		}
+	}
+	return nil
+}
+
+// coreReaderAt returns a reader for the memory saved in core starting at
+// addr, the reader is limited to the PT_LOAD segment containing addr.
+func coreReaderAt(core *elf.File, addr uint64) io.ReaderAt {
+	for _, prog := range core.Progs {
+		if prog.Type == elf.PT_LOAD && addr >= prog.Vaddr && addr < prog.Vaddr+prog.Filesz {
+			return io.NewSectionReader(prog, int64(addr-prog.Vaddr), int64(prog.Vaddr+prog.Filesz-addr))
+		}
+	}
+	return nil
+}
+
+// findBuildIDNote returns the description of the NT_GNU_BUILD_ID note
+// contained in buf, a list of notes.
+func findBuildIDNote(buf []byte) []byte {
+	const _NT_GNU_BUILD_ID = 3
+	align4 := func(n uint32) int { return (int(n) + 3) &^ 3 }
+	for len(buf) >= 12 {
+		namesz := binary.LittleEndian.Uint32(buf[0:])
+		descsz := binary.LittleEndian.Uint32(buf[4:])
+		typ := binary.LittleEndian.Uint32(buf[8:])
+		buf = buf[12:]
+		if align4(namesz)+align4(descsz) > len(buf) {
+			return nil
+		}
+		name := buf[:namesz]
+		desc := buf[align4(namesz):][:descsz]
+		buf = buf[align4(namesz)+align4(descsz):]
+		if typ == _NT_GNU_BUILD_ID && string(name) == "GNU\x00" {
+			return desc
+		}
+	}
+	return nil
+}
@@ -353,3 +353,59 @@ func TestNestedCompileUnts(t *testing.T) {
 		t.Errorf("expected 2 variables, got %d", n)
 	}
 }
+
+func TestDwarfExprEntryValue(t *testing.T) {
+	const callSiteReturnPC = 0x40250
+
+	dwb := dwarfbuilder.New()
+
+	uint16off := dwb.AddBaseType("uint16", dwarfbuilder.DW_ATE_unsigned, 2)
+
+	dwb.AddSubprogram("main.f", 0x40100, 0x40200)
+	dwb.AddVariable("a", uint16off, dwarfbuilder.LocationBlock(op.DW_OP_entry_value, uint(1), op.DW_OP_reg5, op.DW_OP_stack_value))
+	dwb.AddVariable("b", uint16off, dwarfbuilder.LocationBlock(op.DW_OP_entry_value, uint(1), op.DW_OP_reg4, op.DW_OP_stack_value))
+	dwb.TagClose()
+
+	dwb.AddSubprogram("main.main", 0x40200, 0x41000)
+	dwb.TagOpen(0x48, "")                                  // DW_TAG_call_site
+	dwb.Attr(0x7d, dwarfbuilder.Address(callSiteReturnPC)) // DW_AT_call_return_pc
+	dwb.TagOpen(0x49, "")                                  // DW_TAG_call_site_parameter
+	dwb.Attr(dwarf.AttrLocation, dwarfbuilder.LocationBlock(op.DW_OP_reg5))
+	dwb.Attr(0x7e, dwarfbuilder.LocationBlock(op.DW_OP_breg3, int(2))) // DW_AT_call_value
+	dwb.TagClose()
+	dwb.TagClose()
+	dwb.TagClose()
+
+	dwb.AddSubprogram("main.g", 0x41000, 0x41100)
+	dwb.TagClose()
+
+	bi, _ := fakeBinaryInfo(t, dwb)
+
+	var regs, callerRegs linutil.AMD64Registers
+	regs.Regs = &linutil.AMD64PtraceRegs{Rip: 0x40150}
+	callerRegs.Regs = &linutil.AMD64PtraceRegs{Rip: callSiteReturnPC, Rbx: 0x1232}
+
+	frames := []proc.Stackframe{
+		{Current: proc.Location{PC: 0x40150, Fn: bi.LookupFunc["main.f"]}, Regs: dwarfRegisters(bi, &regs), Ret: callSiteReturnPC},
+		{Current: proc.Location{PC: callSiteReturnPC, Fn: bi.LookupFunc["main.main"]}, Regs: dwarfRegisters(bi, &callerRegs)},
+	}
+	frames[0].Call = frames[0].Current
+	frames[1].Call = frames[1].Current
+
+	scope := proc.FrameToScope(bi, newFakeMemory(fakeCFA()), nil, frames...)
+	scope.PC = 0x40150
+	uintExprCheck(t, scope, "a", 0x1234)
+
+	thevar, err := scope.EvalExpression("b", normalLoadConfig)
+	assertNoError(err, t, "EvalExpression(b)")
+	if thevar.Unreadable == nil || thevar.Flags&proc.VariableOptimizedOut == 0 {
+		t.Errorf("expected b to be optimized out, got %v (flags %#x)", thevar.Unreadable, thevar.Flags)
+	}
+
+	// The call site is found in the first non-inlined caller frame.
+	inlined := proc.Stackframe{Current: proc.Location{PC: callSiteReturnPC, Fn: bi.LookupFunc["main.g"]}, Regs: dwarfRegisters(bi, &regs), Inlined: true}
+	inlined.Call = inlined.Current
+	scope = proc.FrameToScope(bi, newFakeMemory(fakeCFA()), nil, frames[0], inlined, frames[1])
+	scope.PC = 0x40150
+	uintExprCheck(t, scope, "a", 0x1234)
+}
@@ -0,0 +1,305 @@
+package proc
+
+import (
+	"debug/dwarf"
+	"fmt"
+
+	"github.com/go-delve/delve/pkg/dwarf/frame"
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/loclist"
+	"github.com/go-delve/delve/pkg/dwarf/reader"
+)
+
+// DwarfProblemKind describes the kind of a DwarfProblem.
+type DwarfProblemKind string
+
+const (
+	// DwarfNoProducer is a compile unit without a DW_AT_producer attribute.
+	DwarfNoProducer DwarfProblemKind = "no-producer"
+	// DwarfNoFrameEntry is a function not covered by any frame description
+	// entry in .debug_frame or .eh_frame.
+	DwarfNoFrameEntry DwarfProblemKind = "no-frame-entry"
+	// DwarfNoLineInfo is a function whose compile unit has no line table.
+	DwarfNoLineInfo DwarfProblemKind = "no-line-info"
+	// DwarfLineGap is a range of addresses inside the body of a function
+	// that is not described by the line table, or is assigned to line 0.
+	DwarfLineGap DwarfProblemKind = "line-gap"
+	// DwarfOptimizedOut is a variable without a location, with an empty
+	// location expression or with an empty location list, i.e. a variable
+	// that was optimized away by the compiler.
+	DwarfOptimizedOut DwarfProblemKind = "optimized-out"
+	// DwarfBadLocation is a variable with a location that can not be read or
+	// that describes addresses outside of its function.
+	DwarfBadLocation DwarfProblemKind = "bad-location"
+	// DwarfBadType is a variable without a type or whose type can not be
+	// read.
+	DwarfBadType DwarfProblemKind = "bad-type"
+)
+
+// DwarfProblemSeverity is the severity of a DwarfProblem.
+type DwarfProblemSeverity string
+
+const (
+	// DwarfWarning is a problem that is expected in the output of an
+	// optimizing compiler, for example a variable that was optimized away.
+	DwarfWarning DwarfProblemSeverity = "warning"
+	// DwarfError is a problem caused by invalid or missing debug
+	// information.
+	DwarfError DwarfProblemSeverity = "error"
+)
+
+// Severity returns the severity of problems of kind k.
+func (k DwarfProblemKind) Severity() DwarfProblemSeverity {
+	switch k {
+	case DwarfNoProducer, DwarfLineGap, DwarfOptimizedOut:
+		return DwarfWarning
+	default:
+		return DwarfError
+	}
+}
+
+// DwarfProblem describes a problem with the debug information of the target
+// that makes it harder, or impossible, to debug part of it.
+type DwarfProblem struct {
+	Kind        DwarfProblemKind
+	Severity    DwarfProblemSeverity
+	Package     string // package the problem refers to, if known
+	CompileUnit string
+	Function    string       // function the problem refers to, if any
+	Variable    string       // variable the problem refers to, if any
+	Offset      dwarf.Offset // offset of the debug_info entry the problem refers to
+	Start, End  uint64       // range of addresses the problem refers to, if any
+	Description string
+}
+
+func (p *DwarfProblem) String() string {
+	var s string
+	switch {
+	case p.Variable != "" && p.Function != "":
+		s = fmt.Sprintf("%s: variable %s of %s", p.Kind, p.Variable, p.Function)
+	case p.Variable != "":
+		s = fmt.Sprintf("%s: variable %s", p.Kind, p.Variable)
+	case p.Function != "":
+		s = fmt.Sprintf("%s: function %s", p.Kind, p.Function)
+	default:
+		s = fmt.Sprintf("%s: compile unit %s", p.Kind, p.CompileUnit)
+	}
+	if p.Start != 0 || p.End != 0 {
+		s += fmt.Sprintf(" [%#x, %#x)", p.Start, p.End)
+	}
+	return string(p.Severity) + ": " + s + ": " + p.Description
+}
+
+// CheckDwarf walks the compile units, functions and variables of all
+// loaded images looking for problems with their debug information.
+func (bi *BinaryInfo) CheckDwarf() []DwarfProblem {
+	var problems []DwarfProblem
+	add := func(p DwarfProblem) {
+		p.Severity = p.Kind.Severity()
+		problems = append(problems, p)
+	}
+
+	for _, image := range bi.Images {
+		for _, cu := range image.compileUnits {
+			if cu.producer == "" {
+				pkg := ""
+				if cu.isgo {
+					pkg = cu.name
+				}
+				add(DwarfProblem{Kind: DwarfNoProducer, Package: pkg, CompileUnit: cu.name, Offset: cu.offset, Description: "compile unit has no producer"})
+			}
+		}
+	}
+
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		if fn.Entry == 0 || fn.cu == nil {
+			// abstract origin of inlined functions
+			continue
+		}
+		bi.checkFunction(fn, add)
+	}
+
+	for _, pv := range bi.packageVars {
+		if pv.cu == nil || pv.cu.image == nil {
+			continue
+		}
+		pkg := packageName(pv.name)
+		tree, err := pv.cu.image.getDwarfTree(pv.offset)
+		if err != nil {
+			add(DwarfProblem{Kind: DwarfBadLocation, Package: pkg, CompileUnit: pv.cu.name, Variable: pv.name, Offset: pv.offset, Description: err.Error()})
+			continue
+		}
+		problem := DwarfProblem{Package: pkg, CompileUnit: pv.cu.name, Variable: pv.name, Offset: pv.offset}
+		switch loc := tree.Val(dwarf.AttrLocation).(type) {
+		case nil:
+			problem.Kind, problem.Description = DwarfOptimizedOut, "no location attribute"
+			add(problem)
+		case []byte:
+			if len(loc) == 0 {
+				problem.Kind, problem.Description = DwarfOptimizedOut, "empty location expression"
+				add(problem)
+			}
+		default:
+			problem.Kind, problem.Description = DwarfBadLocation, fmt.Sprintf("unexpected location attribute of type %T", loc)
+			add(problem)
+		}
+		bi.checkVariableType(pv.cu.image, tree, problem, add)
+	}
+
+	return problems
+}
+
+// checkFunction checks the frame entries, line table and variables of fn.
+func (bi *BinaryInfo) checkFunction(fn *Function, add func(DwarfProblem)) {
+	cu := fn.cu
+	problem := DwarfProblem{Package: fn.PackageName(), CompileUnit: cu.name, Function: fn.Name, Offset: fn.offset}
+
+	if _, err := bi.frameEntries.FDEForPC(fn.Entry); err != nil {
+		if _, nofde := err.(*frame.ErrNoFDEForPC); nofde {
+			_, err = bi.ehFrameFDEForPC(fn.Entry)
+		}
+		if err != nil {
+			p := problem
+			p.Kind, p.Start, p.End, p.Description = DwarfNoFrameEntry, fn.Entry, fn.End, err.Error()
+			add(p)
+		}
+	}
+
+	if cu.lineTable == nil {
+		p := problem
+		p.Kind, p.Start, p.End, p.Description = DwarfNoLineInfo, fn.Entry, fn.End, "compile unit has no line table"
+		add(p)
+	} else {
+		for _, gap := range cu.lineInfo().Gaps(fn.Entry, fn.End) {
+			p := problem
+			p.Kind, p.Start, p.End, p.Description = DwarfLineGap, gap[0], gap[1], "no line information"
+			add(p)
+		}
+	}
+
+	tree, err := cu.image.getDwarfTree(fn.offset)
+	if err != nil {
+		p := problem
+		p.Kind, p.Description = DwarfBadLocation, fmt.Sprintf("could not read function entry: %v", err)
+		add(p)
+		return
+	}
+	for _, v := range reader.Variables(tree, 0, 0, 0) {
+		p := problem
+		p.Variable, _ = v.Val(dwarf.AttrName).(string)
+		p.Offset = v.Offset
+		bi.checkVariableLocation(fn, v.Tree, p, add)
+		bi.checkVariableType(cu.image, v.Tree, p, add)
+	}
+}
+
+// checkVariableLocation checks that the location of v, a local variable or
+// argument of fn, is present and only describes addresses inside fn.
+func (bi *BinaryInfo) checkVariableLocation(fn *Function, v *godwarf.Tree, problem DwarfProblem, add func(DwarfProblem)) {
+	switch loc := v.Val(dwarf.AttrLocation).(type) {
+	case nil:
+		problem.Kind, problem.Description = DwarfOptimizedOut, "no location attribute"
+		add(problem)
+	case []byte:
+		if len(loc) == 0 {
+			problem.Kind, problem.Description = DwarfOptimizedOut, "empty location expression"
+			add(problem)
+		}
+	case uint64:
+		// DW_FORM_loclistx, an index into the offset table of the location
+		// lists of the compile unit.
+		off, err := bi.loclistxOffset(fn.cu, loc)
+		if err != nil {
+			problem.Kind, problem.Description = DwarfBadLocation, fmt.Sprintf("could not resolve location list index %d: %v", loc, err)
+			add(problem)
+			return
+		}
+		bi.checkLoclist(fn, off, problem, add)
+	case int64:
+		bi.checkLoclist(fn, loc, problem, add)
+	default:
+		problem.Kind, problem.Description = DwarfBadLocation, fmt.Sprintf("unexpected location attribute of type %T", loc)
+		add(problem)
+	}
+}
+
+// checkLoclist checks that the location list at offset loc, describing a
+// variable of fn, only describes addresses inside fn.
+func (bi *BinaryInfo) checkLoclist(fn *Function, loc int64, problem DwarfProblem, add func(DwarfProblem)) {
+	entries, err := bi.loclistEntries(fn.cu, loc)
+	if err != nil {
+		problem.Kind, problem.Description = DwarfBadLocation, fmt.Sprintf("could not read location list at %#x: %v", loc, err)
+		add(problem)
+		return
+	}
+	if len(entries) == 0 {
+		problem.Kind, problem.Description = DwarfOptimizedOut, fmt.Sprintf("empty location list at %#x", loc)
+		add(problem)
+		return
+	}
+	for _, e := range entries {
+		p := problem
+		p.Kind, p.Start, p.End = DwarfBadLocation, e.LowPC, e.HighPC
+		switch {
+		case e.LowPC == 0 && e.HighPC == ^uint64(0):
+			// default location entry
+			continue
+		case e.LowPC > e.HighPC:
+			p.Description = "inverted address range in location list"
+		case e.LowPC < fn.Entry || e.HighPC > fn.End:
+			p.Description = "location list entry outside of function"
+		case len(e.Instr) == 0:
+			p.Description = "empty location expression in location list"
+		default:
+			continue
+		}
+		add(p)
+	}
+}
+
+// checkVariableType checks that the type of v can be read.
+func (bi *BinaryInfo) checkVariableType(image *Image, v *godwarf.Tree, problem DwarfProblem, add func(DwarfProblem)) {
+	off, ok := v.Val(dwarf.AttrType).(dwarf.Offset)
+	if !ok {
+		problem.Kind, problem.Description = DwarfBadType, "no type attribute"
+		add(problem)
+		return
+	}
+	if _, err := image.Type(off); err != nil {
+		problem.Kind, problem.Description = DwarfBadType, fmt.Sprintf("could not read type at %#x: %v", off, err)
+		add(problem)
+	}
+}
+
+// loclistxOffset returns the offset of the location list with index idx
+// in the location lists of compile unit cu, resolving an attribute of form
+// DW_FORM_loclistx.
+func (bi *BinaryInfo) loclistxOffset(cu *compileUnit, idx uint64) (int64, error) {
+	if cu.image.loclist5 == nil {
+		return 0, fmt.Errorf("no location lists section")
+	}
+	loclistsBase, ok := cu.entry.Val(dwarfAttrLoclistsBase).(int64)
+	if !ok {
+		return 0, fmt.Errorf("compile unit has no loclists base")
+	}
+	return cu.image.loclist5.OffsetOfIndex(uint64(loclistsBase), idx)
+}
+
+// loclistEntries returns all entries of the location list at offset off
+// belonging to compile unit cu.
+func (bi *BinaryInfo) loclistEntries(cu *compileUnit, off int64) ([]loclist.Entry, error) {
+	image := cu.image
+	var rdr loclist.Reader = image.loclist2
+	var debugAddr *godwarf.DebugAddr
+	if cu.Version >= 5 && image.loclist5 != nil {
+		rdr = image.loclist5
+		if addrBase, ok := cu.entry.Val(dwarfAttrAddrBase).(int64); ok {
+			debugAddr = image.debugAddr.GetSubsection(uint64(addrBase))
+		}
+	}
+	if rdr.Empty() {
+		return nil, fmt.Errorf("no location lists section")
+	}
+	return rdr.Entries(int(off), image.StaticBase, cu.lowPC, debugAddr)
+}
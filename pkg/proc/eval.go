@@ -24,6 +24,7 @@ import (
 )
 
 var errOperationOnSpecialFloat = errors.New("operations on non-finite floats not implemented")
+var errKeyNotFound = errors.New("key not found")
 
 // EvalScope is the scope for variable evaluation. Contains the thread,
 // current location (PC), and canonical frame address.
@@ -56,6 +57,10 @@ type EvalScope struct {
 	// will have one assigned by looking at their position in the argument
 	// list.
 	trustArgOrder bool
+
+	// defaultPkg is the package used to resolve identifiers in scopes that
+	// aren't associated with a function, see PackageScope.
+	defaultPkg string
 }
 
 // ConvertEvalScope returns a new EvalScope in the context of the
@@ -133,6 +138,21 @@ func FrameToScope(bi *BinaryInfo, thread MemoryReadWriter, g *G, frames ...Stack
 
 	s := &EvalScope{Location: frames[0].Call, Regs: frames[0].Regs, Mem: thread, g: g, BinInfo: bi, frameOffset: frames[0].FrameOffset()}
 	s.PC = frames[0].lastpc
+	if !frames[0].Inlined {
+		// DW_OP_entry_value is resolved using the call site parameters of the
+		// caller frame, the first frame after frames[0] that isn't inlined
+		// (frames inlined into the caller share its call sites).
+		for i := 1; i < len(frames); i++ {
+			if frames[i].Inlined {
+				continue
+			}
+			retaddr, caller := frames[0].Ret, frames[i]
+			s.Regs.EntryValue = func(expr []byte) (int64, error) {
+				return bi.callSiteEntryValue(retaddr, &caller, expr)
+			}
+			break
+		}
+	}
 	return s
 }
 
@@ -148,6 +168,18 @@ func ThreadScope(thread Thread) (*EvalScope, error) {
 	return FrameToScope(thread.BinInfo(), thread, nil, locations...), nil
 }
 
+// PackageScope returns an EvalScope that is not associated with any
+// goroutine or stack frame, it can only be used to evaluate package
+// variables, constants and types. Identifiers without a package name are
+// resolved in package main.
+// It is meant for targets without threads, like the ones returned by
+// core.OpenExecutable.
+func PackageScope(t *Target) *EvalScope {
+	scope := globalScope(t.BinInfo(), t.BinInfo().Images[0], t.CurrentThread())
+	scope.defaultPkg = "main"
+	return scope
+}
+
 // GoroutineScope returns an EvalScope for the goroutine running on the given thread.
 func GoroutineScope(thread Thread) (*EvalScope, error) {
 	locations, err := ThreadStacktrace(thread, 1)
@@ -309,19 +341,33 @@ func afterLastArgAddr(vars []*Variable) uint64 {
 // * If srcv is nil and dstv is of a nil'able type then dstv is nilled.
 // * If srcv is the empty string and dstv is a string then dstv is set to the
 //   empty string.
-// * If dstv is an "interface {}" and srcv is either an interface (possibly
-//   non-empty) or a pointer shaped type (map, channel, pointer or struct
-//   containing a single pointer field) the type conversion to "interface {}"
-//   is performed.
+// * If dstv is an interface and srcv is either an interface or a value of
+//   a type implementing it the type conversion is performed. Values that
+//   are not pointer shaped are copied to newly allocated memory, the itab
+//   of non-empty interfaces is obtained by calling runtime.getitab.
+// * If srcv and dstv are structs of the same type and srcv is not
+//   addressable (for example the return value of a function call) its
+//   fields are assigned to the fields of dstv one by one.
 // * If srcv and dstv have the same type and are both addressable then the
 //   contents of srcv are copied byte-by-byte into dstv
+// * If srcv is a function or a method value and dstv is a func variable a
+//   funcval for srcv is allocated in the target.
 func (scope *EvalScope) setValue(dstv, srcv *Variable, srcExpr string) error {
 	srcv.loadValue(loadSingleValue)
 
+	if dstv.Kind == reflect.Func && srcv.Kind == reflect.Func && srcv.closureAddr == 0 && srcv.Base != 0 {
+		// srcv is the name of a function or a method value, neither of which
+		// has a funcval in the target.
+		closureAddr, err := allocFuncval(scope, srcv, dstv.DwarfType)
+		if err != nil {
+			return err
+		}
+		return dstv.writeUint(closureAddr, int64(scope.BinInfo.Arch.PtrSize()))
+	}
+
 	typerr := srcv.isType(dstv.RealType, dstv.Kind)
-	if _, isTypeConvErr := typerr.(*typeConvErr); isTypeConvErr {
-		// attempt iface -> eface and ptr-shaped -> eface conversions.
-		return convertToEface(srcv, dstv)
+	if typerr != nil && dstv.Kind == reflect.Interface {
+		return scope.convertToInterface(dstv, srcv)
 	}
 	if typerr != nil {
 		return typerr
@@ -373,6 +419,35 @@ func (scope *EvalScope) setValue(dstv, srcv *Variable, srcExpr string) error {
 		return dstv.writeUint(uint64(srcv.Children[0].Addr), int64(t.ByteSize))
 	}
 
+	if srcv.Kind == reflect.Struct && (srcv.Addr == 0 || srcv.Flags&VariableFakeAddress != 0) && len(srcv.Children) == int(srcv.Len) {
+		// the memory of srcv could have already been reused by the target,
+		// assign the values that were loaded.
+		dsttyp, ok := dstv.RealType.(*godwarf.StructType)
+		if !ok {
+			return fmt.Errorf("can not assign %s to %s", srcv.TypeString(), dstv.TypeString())
+		}
+		for i := range srcv.Children {
+			var field *godwarf.StructField
+			for _, f := range dsttyp.Field {
+				if f.Name == srcv.Children[i].Name {
+					field = f
+					break
+				}
+			}
+			if field == nil || !sameType(field.Type, srcv.Children[i].DwarfType) {
+				return fmt.Errorf("can not assign %s to %s: field %s does not match", srcv.TypeString(), dstv.TypeString(), srcv.Children[i].Name)
+			}
+			fv, err := dstv.toField(field)
+			if err != nil {
+				return err
+			}
+			if err := scope.setValue(fv, &srcv.Children[i], srcExpr+"."+srcv.Children[i].Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// byte-by-byte copying for everything else, but the source must be addressable
 	if srcv.Addr != 0 {
 		return dstv.writeCopy(srcv)
@@ -381,6 +456,131 @@ func (scope *EvalScope) setValue(dstv, srcv *Variable, srcExpr string) error {
 	return fmt.Errorf("can not set variables of type %s (not implemented)", dstv.Kind.String())
 }
 
+// convertToInterface converts srcv to the interface type of dstv and
+// writes the result to dstv. Untyped constants are converted to their
+// default type first.
+func (scope *EvalScope) convertToInterface(dstv, srcv *Variable) error {
+	if srcv.Unreadable != nil {
+		return srcv.Unreadable
+	}
+
+	var typeAddr, data uint64
+	var srcTypeName string
+	if srcv.Kind == reflect.Interface {
+		_type, datav, isnil := srcv.readInterface()
+		if srcv.Unreadable != nil {
+			return srcv.Unreadable
+		}
+		if isnil {
+			return dstv.writeZero()
+		}
+		if len(srcv.Children) > 0 {
+			srcTypeName = srcv.Children[0].TypeString()
+		}
+		typeAddr = _type.maybeDereference().Addr
+		data = datav.maybeDereference().Addr
+	} else {
+		srctyp := srcv.DwarfType
+		if srctyp == nil {
+			var err error
+			srctyp, err = scope.constantDefaultType(srcv, dstv)
+			if err != nil {
+				return err
+			}
+		}
+		srcTypeName = srctyp.String()
+		var typeKind uint64
+		var err error
+		typeAddr, typeKind, err = runtimeType(scope, srctyp)
+		if err != nil {
+			return err
+		}
+		if typeKind&kindDirectIface != 0 {
+			data, err = pointerShapedValue(srcv)
+			if err != nil {
+				return err
+			}
+		} else {
+			// the interface will point to a copy of srcv
+			data, err = allocValue(scope, srctyp, 1)
+			if err != nil {
+				return err
+			}
+			if err := scope.setValue(newVariable("", data, srctyp, scope.BinInfo, scope.Mem), srcv, srcv.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if dstv.RealType.String() != "interface {}" {
+		if scope.callCtx == nil {
+			return funcCallNotAllowedConvErr{srcTypeName, dstv.TypeString()}
+		}
+		ifaceTypeAddr, _, err := runtimeType(scope, dstv.DwarfType)
+		if err != nil {
+			return err
+		}
+		itab, err := runtimeCall(scope, "getitab", runtimePtr(ifaceTypeAddr), runtimePtr(typeAddr), true)
+		if err != nil {
+			return err
+		}
+		if itab == 0 {
+			return fmt.Errorf("%s does not implement %s", srcTypeName, dstv.TypeString())
+		}
+		typeAddr = itab
+	}
+
+	if err := writePointer(scope.BinInfo, dstv.mem, dstv.Addr, typeAddr); err != nil {
+		return err
+	}
+	return writePointer(scope.BinInfo, dstv.mem, dstv.Addr+uint64(scope.BinInfo.Arch.PtrSize()), data)
+}
+
+// constantDefaultType returns the default type of the untyped constant v,
+// which is being converted to the interface dstv.
+func (scope *EvalScope) constantDefaultType(v, dstv *Variable) (godwarf.Type, error) {
+	if v.Value == nil {
+		return nil, fmt.Errorf("can not convert %s to %s", v.Name, dstv.TypeString())
+	}
+	var typename string
+	switch v.Value.Kind() {
+	case constant.Bool:
+		typename = "bool"
+	case constant.String:
+		typename = "string"
+	case constant.Int:
+		typename = "int"
+	case constant.Float:
+		typename = "float64"
+	case constant.Complex:
+		typename = "complex128"
+	default:
+		return nil, fmt.Errorf("can not convert %s constant to %s", v.Value, dstv.TypeString())
+	}
+	return scope.BinInfo.findType(typename)
+}
+
+// pointerShapedValue returns the value of v, a variable of a pointer
+// shaped type.
+func pointerShapedValue(v *Variable) (uint64, error) {
+	if v.Addr != 0 {
+		return readUintRaw(v.mem, v.Addr, int64(v.bi.Arch.PtrSize()))
+	}
+	switch v.Kind {
+	case reflect.Ptr, reflect.UnsafePointer:
+		// the result of a type cast or of the & operator
+		v.loadValue(loadSingleValue)
+		if len(v.Children) == 1 {
+			return v.Children[0].Addr, nil
+		}
+	case reflect.Func:
+		if v.closureAddr != 0 {
+			return v.closureAddr, nil
+		}
+	}
+	return 0, fmt.Errorf("can not convert value of type %s to an interface", v.TypeString())
+}
+
 // EvalVariable returns the value of the given expression (backwards compatibility).
 func (scope *EvalScope) EvalVariable(name string, cfg LoadConfig) (*Variable, error) {
 	return scope.EvalExpression(name, cfg)
@@ -393,9 +593,26 @@ func (scope *EvalScope) SetVariable(name, value string) error {
 		return err
 	}
 
-	xv, err := scope.evalAST(t)
-	if err != nil {
-		return err
+	var xv *Variable
+	if node, ok := t.(*ast.IndexExpr); ok {
+		// elements of a map are handled separately so that new keys can be
+		// inserted.
+		xev, err := scope.evalAST(node.X)
+		if err != nil {
+			return err
+		}
+		if xev.Kind == reflect.Map {
+			return scope.setMapElem(xev, node.Index, value)
+		}
+		xv, err = scope.evalIndexOf(xev, node)
+		if err != nil {
+			return err
+		}
+	} else {
+		xv, err = scope.evalAST(t)
+		if err != nil {
+			return err
+		}
 	}
 
 	if xv.Addr == 0 {
@@ -419,6 +636,52 @@ func (scope *EvalScope) SetVariable(name, value string) error {
 	return scope.setValue(xv, yv, value)
 }
 
+// setMapElem sets the element of mapv with key keyExpr to the value of the
+// expression value. If the key is not in the map it is inserted by calling
+// runtime.mapassign.
+func (scope *EvalScope) setMapElem(mapv *Variable, keyExpr ast.Expr, value string) error {
+	if mapv.Unreadable != nil {
+		return mapv.Unreadable
+	}
+	keyv, err := scope.evalAST(keyExpr)
+	if err != nil {
+		return err
+	}
+	keyv.loadValue(loadFullValue)
+	if keyv.Unreadable != nil {
+		return keyv.Unreadable
+	}
+
+	t, err := parser.ParseExpr(value)
+	if err != nil {
+		return err
+	}
+	yv, err := scope.evalAST(t)
+	if err != nil {
+		return err
+	}
+
+	elemv, err := mapv.mapAccess(keyv)
+	if err == errKeyNotFound {
+		if mapv.Base == 0 {
+			return errors.New("assignment to entry in nil map")
+		}
+		if scope.callCtx == nil {
+			return errFuncCallNotAllowedNewKey
+		}
+		var maptypeAddr uint64
+		maptypeAddr, _, err = runtimeType(scope, mapv.DwarfType)
+		if err != nil {
+			return err
+		}
+		elemv, err = scope.mapAssign(mapv.RealType.(*godwarf.MapType), maptypeAddr, mapv.Base, keyv, exprToString(keyExpr))
+	}
+	if err != nil {
+		return err
+	}
+	return scope.setValue(elemv, yv, value)
+}
+
 // LocalVariables returns all local variables from the current function scope.
 func (scope *EvalScope) LocalVariables(cfg LoadConfig) ([]*Variable, error) {
 	vars, err := scope.Locals()
@@ -511,30 +774,50 @@ func (scope *EvalScope) findGlobal(pkgName, varName string) (*Variable, error) {
 }
 
 func (scope *EvalScope) findGlobalInternal(name string) (*Variable, error) {
-	for _, pkgvar := range scope.BinInfo.packageVars {
+	// Plugins contain their own copy of every package they import, when a
+	// name is defined in more than one image the one defined in the
+	// executable, or in the image loaded first, is used.
+	var found *packageVar
+	for i := range scope.BinInfo.packageVars {
+		pkgvar := &scope.BinInfo.packageVars[i]
 		if pkgvar.name == name || strings.HasSuffix(pkgvar.name, "/"+name) {
-			reader := pkgvar.cu.image.dwarfReader
-			reader.Seek(pkgvar.offset)
-			entry, err := reader.Next()
-			if err != nil {
-				return nil, err
+			if found == nil || pkgvar.cu.image.index < found.cu.image.index {
+				found = pkgvar
 			}
-			return extractVarInfoFromEntry(scope.BinInfo, pkgvar.cu.image, regsReplaceStaticBase(scope.Regs, pkgvar.cu.image), scope.Mem, godwarf.EntryToTree(entry))
-		}
-	}
-	for _, fn := range scope.BinInfo.Functions {
-		if fn.Name == name || strings.HasSuffix(fn.Name, "/"+name) {
-			//TODO(aarzilli): convert function entry into a function type?
-			r := newVariable(fn.Name, fn.Entry, &godwarf.FuncType{}, scope.BinInfo, scope.Mem)
-			r.Value = constant.MakeString(fn.Name)
-			r.Base = fn.Entry
-			r.loaded = true
-			if fn.Entry == 0 {
-				r.Unreadable = fmt.Errorf("function %s is inlined", fn.Name)
+		}
+	}
+	if found != nil {
+		reader := found.cu.image.dwarfReader
+		reader.Seek(found.offset)
+		entry, err := reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		return extractVarInfoFromEntry(scope.BinInfo, found.cu.image, regsReplaceStaticBase(scope.Regs, found.cu.image), scope.Mem, godwarf.EntryToTree(entry))
+	}
+	// LookupFunc already prefers the function defined in the executable
+	foundFn := scope.BinInfo.LookupFunc[name]
+	if foundFn == nil {
+		for i := range scope.BinInfo.Functions {
+			fn := &scope.BinInfo.Functions[i]
+			if strings.HasSuffix(fn.Name, "/"+name) {
+				if foundFn == nil || fn.imageIndex() < foundFn.imageIndex() {
+					foundFn = fn
+				}
 			}
-			return r, nil
 		}
 	}
+	if fn := foundFn; fn != nil {
+		//TODO(aarzilli): convert function entry into a function type?
+		r := newVariable(fn.Name, fn.Entry, &godwarf.FuncType{}, scope.BinInfo, scope.Mem)
+		r.Value = constant.MakeString(fn.Name)
+		r.Base = fn.Entry
+		r.loaded = true
+		if fn.Entry == 0 {
+			r.Unreadable = fmt.Errorf("function %s is inlined", fn.Name)
+		}
+		return r, nil
+	}
 	for dwref, ctyp := range scope.BinInfo.consts {
 		for _, cval := range ctyp.values {
 			if cval.fullName == name || strings.HasSuffix(cval.fullName, "/"+name) {
@@ -730,39 +1013,7 @@ func (scope *EvalScope) evalAST(t ast.Expr) (*Variable, error) {
 		return scope.evalAST(node.X)
 
 	case *ast.SelectorExpr: // <expression>.<identifier>
-		// try to interpret the selector as a package variable
-		if maybePkg, ok := node.X.(*ast.Ident); ok {
-			if maybePkg.Name == "runtime" && node.Sel.Name == "curg" {
-				if scope.g == nil {
-					typ, err := scope.BinInfo.findType("runtime.g")
-					if err != nil {
-						return nil, fmt.Errorf("blah: %v", err)
-					}
-					gvar := newVariable("curg", fakeAddress, typ, scope.BinInfo, scope.Mem)
-					gvar.loaded = true
-					gvar.Flags = VariableFakeAddress
-					gvar.Children = append(gvar.Children, *newConstant(constant.MakeInt64(0), scope.Mem))
-					gvar.Children[0].Name = "goid"
-					return gvar, nil
-				}
-				return scope.g.variable.clone(), nil
-			} else if maybePkg.Name == "runtime" && node.Sel.Name == "frameoff" {
-				return newConstant(constant.MakeInt64(scope.frameOffset), scope.Mem), nil
-			} else if v, err := scope.findGlobal(maybePkg.Name, node.Sel.Name); err == nil {
-				return v, nil
-			}
-		}
-		// try to accept "package/path".varname syntax for package variables
-		if maybePkg, ok := node.X.(*ast.BasicLit); ok && maybePkg.Kind == token.STRING {
-			pkgpath, err := strconv.Unquote(maybePkg.Value)
-			if err == nil {
-				if v, err := scope.findGlobal(pkgpath, node.Sel.Name); err == nil {
-					return v, nil
-				}
-			}
-		}
-		// if it's not a package variable then it must be a struct member access
-		return scope.evalStructSelector(node)
+		return scope.evalSelector(node, false)
 
 	case *ast.TypeAssertExpr: // <expression>.(<type>)
 		return scope.evalTypeAssert(node)
@@ -796,6 +1047,9 @@ func (scope *EvalScope) evalAST(t ast.Expr) (*Variable, error) {
 	case *ast.BasicLit:
 		return newConstant(constant.MakeFromLiteral(node.Value, node.Kind, 0), scope.Mem), nil
 
+	case *ast.CompositeLit:
+		return scope.evalCompositeLit(node, nil)
+
 	default:
 		return nil, fmt.Errorf("expression %T not implemented", t)
 
@@ -1142,19 +1396,26 @@ func (scope *EvalScope) evalIdent(node *ast.Ident) (*Variable, error) {
 		return nilVariable, nil
 	}
 
-	vars, err := scope.Locals()
-	if err != nil {
-		return nil, err
-	}
-	for i := range vars {
-		if vars[i].Name == node.Name && vars[i].Flags&VariableShadowed == 0 {
-			return vars[i], nil
+	// Scopes that aren't associated with a function have no local variables
+	// and only resolve identifiers in their default package, if they have
+	// one (see PackageScope).
+	pkgName := scope.defaultPkg
+	if scope.Fn != nil {
+		vars, err := scope.Locals()
+		if err != nil {
+			return nil, err
+		}
+		for i := range vars {
+			if vars[i].Name == node.Name && vars[i].Flags&VariableShadowed == 0 {
+				return vars[i], nil
+			}
 		}
+		pkgName = scope.Fn.PackageName()
 	}
 
 	// if it's not a local variable then it could be a package variable w/o explicit package name
-	if scope.Fn != nil {
-		if v, err := scope.findGlobal(scope.Fn.PackageName(), node.Name); err == nil {
+	if pkgName != "" {
+		if v, err := scope.findGlobal(pkgName, node.Name); err == nil {
 			v.Name = node.Name
 			return v, nil
 		}
@@ -1162,8 +1423,48 @@ func (scope *EvalScope) evalIdent(node *ast.Ident) (*Variable, error) {
 	return nil, fmt.Errorf("could not find symbol value for %s", node.Name)
 }
 
+// evalSelector evaluates expressions <expression>.<identifier>, callee is
+// true if the selector is the function of a call expression.
+func (scope *EvalScope) evalSelector(node *ast.SelectorExpr, callee bool) (*Variable, error) {
+	// try to interpret the selector as a package variable
+	if maybePkg, ok := node.X.(*ast.Ident); ok {
+		if maybePkg.Name == "runtime" && node.Sel.Name == "curg" {
+			if scope.g == nil {
+				typ, err := scope.BinInfo.findType("runtime.g")
+				if err != nil {
+					return nil, fmt.Errorf("blah: %v", err)
+				}
+				gvar := newVariable("curg", fakeAddress, typ, scope.BinInfo, scope.Mem)
+				gvar.loaded = true
+				gvar.Flags = VariableFakeAddress
+				gvar.Children = append(gvar.Children, *newConstant(constant.MakeInt64(0), scope.Mem))
+				gvar.Children[0].Name = "goid"
+				return gvar, nil
+			}
+			return scope.g.variable.clone(), nil
+		} else if maybePkg.Name == "runtime" && node.Sel.Name == "frameoff" {
+			return newConstant(constant.MakeInt64(scope.frameOffset), scope.Mem), nil
+		} else if v, err := scope.findGlobal(maybePkg.Name, node.Sel.Name); err == nil {
+			return v, nil
+		}
+	}
+	// try to accept "package/path".varname syntax for package variables
+	if maybePkg, ok := node.X.(*ast.BasicLit); ok && maybePkg.Kind == token.STRING {
+		pkgpath, err := strconv.Unquote(maybePkg.Value)
+		if err == nil {
+			if v, err := scope.findGlobal(pkgpath, node.Sel.Name); err == nil {
+				return v, nil
+			}
+		}
+	}
+	// if it's not a package variable then it must be a struct member access
+	return scope.evalStructSelector(node, callee)
+}
+
 // Evaluates expressions <subexpr>.<field name> where subexpr is not a package name
-func (scope *EvalScope) evalStructSelector(node *ast.SelectorExpr) (*Variable, error) {
+// If the selector is the function of a call expression methods of
+// interface values are read from the method table of the interface.
+func (scope *EvalScope) evalStructSelector(node *ast.SelectorExpr, callee bool) (*Variable, error) {
 	xv, err := scope.evalAST(node.X)
 	if err != nil {
 		return nil, err
@@ -1178,7 +1479,7 @@ func (scope *EvalScope) evalStructSelector(node *ast.SelectorExpr) (*Variable, e
 		return nil, fmt.Errorf("%s (type %s) is not a struct", xv.Value, xv.TypeString())
 	}
 
-	rv, err := xv.findMethod(node.Sel.Name)
+	rv, err := xv.findMethod(node.Sel.Name, callee)
 	if err != nil {
 		return nil, err
 	}
@@ -1233,6 +1534,12 @@ func (scope *EvalScope) evalIndex(node *ast.IndexExpr) (*Variable, error) {
 	if err != nil {
 		return nil, err
 	}
+	return scope.evalIndexOf(xev, node)
+}
+
+// evalIndexOf evaluates node, an index expression, using xev as the value
+// of node.X.
+func (scope *EvalScope) evalIndexOf(xev *Variable, node *ast.IndexExpr) (*Variable, error) {
 	if xev.Unreadable != nil {
 		return nil, xev.Unreadable
 	}
@@ -1386,6 +1693,270 @@ func (scope *EvalScope) evalAddrOf(node *ast.UnaryExpr) (*Variable, error) {
 	return xev.pointerToVariable(), nil
 }
 
+// evalCompositeLit evaluates a composite literal, its value is allocated
+// in the target. If the type of the literal is elided (because it is an
+// element of another composite literal) typ is its type.
+func (scope *EvalScope) evalCompositeLit(node *ast.CompositeLit, typ godwarf.Type) (*Variable, error) {
+	if node.Type != nil {
+		var err error
+		typ, err = scope.BinInfo.findTypeExpr(node.Type)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if typ == nil {
+		return nil, fmt.Errorf("missing type for composite literal %s", exprToString(node))
+	}
+
+	switch t := resolveTypedef(typ).(type) {
+	case *godwarf.PtrType:
+		if node.Type != nil {
+			break
+		}
+		// &T can be elided from the elements of a []*T or map[K]*T literal
+		v, err := scope.evalCompositeLit(node, t.Type)
+		if err != nil {
+			return nil, err
+		}
+		return v.pointerToVariable(), nil
+
+	case *godwarf.StructType:
+		addr, err := allocValue(scope, typ, 1)
+		if err != nil {
+			return nil, err
+		}
+		v := newVariable("", addr, typ, scope.BinInfo, scope.Mem)
+		return v, scope.compositeLitStruct(v, t, node)
+
+	case *godwarf.ArrayType:
+		idx, n, err := scope.compositeLitIndexes(node)
+		if err != nil {
+			return nil, err
+		}
+		if n > t.Count {
+			return nil, fmt.Errorf("index %d out of bounds in array literal of type %s", n-1, typ)
+		}
+		addr, err := allocValue(scope, t.Type, t.Count)
+		if err != nil {
+			return nil, err
+		}
+		v := newVariable("", addr, typ, scope.BinInfo, scope.Mem)
+		return v, scope.compositeLitElements(v, idx, node)
+
+	case *godwarf.SliceType:
+		idx, n, err := scope.compositeLitIndexes(node)
+		if err != nil {
+			return nil, err
+		}
+		v, err := scope.allocSlice(typ, n)
+		if err != nil {
+			return nil, err
+		}
+		return v, scope.compositeLitElements(v, idx, node)
+
+	case *godwarf.MapType:
+		return scope.compositeLitMap(typ, t, node)
+	}
+
+	return nil, fmt.Errorf("invalid composite literal type %s", typ)
+}
+
+// compositeLitElem evaluates an element of a composite literal, typ is the
+// type used when the type of the element is elided.
+func (scope *EvalScope) compositeLitElem(elt ast.Expr, typ godwarf.Type) (*Variable, error) {
+	if lit, ok := elt.(*ast.CompositeLit); ok && lit.Type == nil {
+		return scope.evalCompositeLit(lit, typ)
+	}
+	return scope.evalAST(elt)
+}
+
+// compositeLitStruct sets the fields of v, a newly allocated struct, to
+// the values listed in node.
+func (scope *EvalScope) compositeLitStruct(v *Variable, t *godwarf.StructType, node *ast.CompositeLit) error {
+	keyed := false
+	for i, elt := range node.Elts {
+		var field *godwarf.StructField
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if i > 0 && !keyed {
+				return errors.New("mixture of field:value and value elements in struct literal")
+			}
+			keyed = true
+			name, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("invalid field name %s in struct literal", exprToString(kv.Key))
+			}
+			for _, f := range t.Field {
+				if f.Name == name.Name {
+					field = f
+					break
+				}
+			}
+			if field == nil {
+				return fmt.Errorf("unknown field %s in struct literal of type %s", name.Name, v.TypeString())
+			}
+			elt = kv.Value
+		} else {
+			if keyed {
+				return errors.New("mixture of field:value and value elements in struct literal")
+			}
+			if i >= len(t.Field) {
+				return fmt.Errorf("too many values in struct literal of type %s", v.TypeString())
+			}
+			field = t.Field[i]
+		}
+
+		fv, err := v.toField(field)
+		if err != nil {
+			return err
+		}
+		val, err := scope.compositeLitElem(elt, field.Type)
+		if err != nil {
+			return err
+		}
+		if err := scope.setValue(fv, val, exprToString(elt)); err != nil {
+			return err
+		}
+	}
+	if !keyed && len(node.Elts) > 0 && len(node.Elts) < len(t.Field) {
+		return fmt.Errorf("too few values in struct literal of type %s", v.TypeString())
+	}
+	return nil
+}
+
+// compositeLitIndexes returns the index of each element of node, an array
+// or slice literal, and the length of the literal.
+func (scope *EvalScope) compositeLitIndexes(node *ast.CompositeLit) ([]int64, int64, error) {
+	idx := make([]int64, len(node.Elts))
+	n, i := int64(0), int64(0)
+	for j, elt := range node.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			keyv, err := scope.evalAST(kv.Key)
+			if err != nil {
+				return nil, 0, err
+			}
+			if keyv.Value == nil || keyv.Value.Kind() != constant.Int {
+				return nil, 0, fmt.Errorf("index %s must be integer constant", exprToString(kv.Key))
+			}
+			i, _ = constant.Int64Val(keyv.Value)
+			if i < 0 {
+				return nil, 0, fmt.Errorf("index %s must be non-negative integer constant", exprToString(kv.Key))
+			}
+		}
+		idx[j] = i
+		i++
+		if i > n {
+			n = i
+		}
+	}
+	return idx, n, nil
+}
+
+// compositeLitElements sets the elements of v, a newly allocated array or
+// slice, to the values listed in node, idx contains the index of each
+// element.
+func (scope *EvalScope) compositeLitElements(v *Variable, idx []int64, node *ast.CompositeLit) error {
+	for j, elt := range node.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		ev, err := v.sliceAccess(int(idx[j]))
+		if err != nil {
+			return err
+		}
+		val, err := scope.compositeLitElem(elt, v.fieldType)
+		if err != nil {
+			return err
+		}
+		if err := scope.setValue(ev, val, exprToString(elt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocSlice allocates the backing array of a slice of type typ and
+// length n in the target and returns the slice.
+func (scope *EvalScope) allocSlice(typ godwarf.Type, n int64) (*Variable, error) {
+	elemType := resolveTypedef(typ).(*godwarf.SliceType).ElemType
+	base, err := allocValue(scope, elemType, n)
+	if err != nil {
+		return nil, err
+	}
+	v := newVariable("", 0, typ, scope.BinInfo, DereferenceMemory(scope.Mem))
+	v.Len = n
+	v.Cap = n
+	v.Base = base
+	v.fieldType = elemType
+	v.stride = alignAddr(elemType.Size(), elemType.Align())
+	return v, nil
+}
+
+// compositeLitMap creates a map with runtime.makemap and inserts the
+// elements listed in node with runtime.mapassign.
+func (scope *EvalScope) compositeLitMap(typ godwarf.Type, t *godwarf.MapType, node *ast.CompositeLit) (*Variable, error) {
+	if scope.callCtx == nil {
+		return nil, errFuncCallNotAllowedLitAlloc
+	}
+	maptypeAddr, _, err := runtimeType(scope, typ)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := allocValue(scope, typ, 1)
+	if err != nil {
+		return nil, err
+	}
+	h, err := runtimeCall(scope, "makemap", runtimePtr(maptypeAddr), int64(len(node.Elts)), runtimePtr(0))
+	if err != nil {
+		return nil, err
+	}
+	// addr was pinned by allocValue, storing h in it keeps the map reachable.
+	if err := writePointer(scope.BinInfo, scope.Mem, addr, h); err != nil {
+		return nil, err
+	}
+
+	for _, elt := range node.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, fmt.Errorf("missing key in map literal")
+		}
+		keyv, err := scope.compositeLitElem(kv.Key, t.KeyType)
+		if err != nil {
+			return nil, err
+		}
+		elemv, err := scope.mapAssign(t, maptypeAddr, h, keyv, exprToString(kv.Key))
+		if err != nil {
+			return nil, err
+		}
+		val, err := scope.compositeLitElem(kv.Value, t.ElemType)
+		if err != nil {
+			return nil, err
+		}
+		if err := scope.setValue(elemv, val, exprToString(kv.Value)); err != nil {
+			return nil, err
+		}
+	}
+
+	return newVariable("", addr, typ, scope.BinInfo, scope.Mem), nil
+}
+
+// mapAssign inserts keyv in the map h of type t, whose runtime type is at
+// maptypeAddr, by calling runtime.mapassign and returns the element
+// associated with it.
+func (scope *EvalScope) mapAssign(t *godwarf.MapType, maptypeAddr, h uint64, keyv *Variable, keyExpr string) (*Variable, error) {
+	keyAddr, err := allocValue(scope, t.KeyType, 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := scope.setValue(newVariable("", keyAddr, t.KeyType, scope.BinInfo, scope.Mem), keyv, keyExpr); err != nil {
+		return nil, err
+	}
+	elemAddr, err := runtimeCall(scope, "mapassign", runtimePtr(maptypeAddr), runtimePtr(h), runtimePtr(keyAddr))
+	if err != nil {
+		return nil, err
+	}
+	return newVariable("", elemAddr, t.ElemType, scope.BinInfo, scope.Mem), nil
+}
+
 func (v *Variable) pointerToVariable() *Variable {
 	v.OnlyAddr = true
 
@@ -1924,7 +2495,7 @@ func (v *Variable) mapAccess(idx *Variable) (*Variable, error) {
 		return nil, v.Unreadable
 	}
 	// go would return zero for the map value type here, we do not have the ability to create zeroes
-	return nil, fmt.Errorf("key not found")
+	return nil, errKeyNotFound
 }
 
 func (v *Variable) reslice(low int64, high int64) (*Variable, error) {
@@ -1970,14 +2541,26 @@ func (v *Variable) reslice(low int64, high int64) (*Variable, error) {
 	return r, nil
 }
 
-// findMethod finds method mname in the type of variable v
-func (v *Variable) findMethod(mname string) (*Variable, error) {
+// findMethod finds method mname in the type of variable v.
+// If callee is true, and v is an interface, the method is read from the
+// method table of the interface, like a dynamic call would, falling back
+// to looking it up in the dynamic type of v if the interface type doesn't
+// have a method called mname.
+func (v *Variable) findMethod(mname string, callee bool) (*Variable, error) {
 	if _, isiface := v.RealType.(*godwarf.InterfaceType); isiface {
+		if callee {
+			if rv, err := itabMethod(v, mname); rv != nil || err != nil {
+				return rv, err
+			}
+		}
 		v.loadInterface(0, false, loadFullValue)
 		if v.Unreadable != nil {
 			return nil, v.Unreadable
 		}
-		return v.Children[0].findMethod(mname)
+		if v.Children[0].Addr == 0 {
+			return nil, errors.New("nil pointer dereference")
+		}
+		return v.Children[0].findMethod(mname, callee)
 	}
 
 	typ := v.DwarfType
@@ -2021,10 +2604,10 @@ func (v *Variable) findMethod(mname string) (*Variable, error) {
 		}
 		return r, nil
 	}
-	return v.tryFindMethodInEmbeddedFields(mname)
+	return v.tryFindMethodInEmbeddedFields(mname, callee)
 }
 
-func (v *Variable) tryFindMethodInEmbeddedFields(mname string) (*Variable, error) {
+func (v *Variable) tryFindMethodInEmbeddedFields(mname string, callee bool) (*Variable, error) {
 	structVar := v.maybeDereference()
 	structVar.Name = v.Name
 	if structVar.Unreadable != nil {
@@ -2039,7 +2622,7 @@ func (v *Variable) tryFindMethodInEmbeddedFields(mname string) (*Variable, error
 				if err != nil {
 					return nil, err
 				}
-				if embeddedMethod, err := embeddedVar.findMethod(mname); err != nil {
+				if embeddedMethod, err := embeddedVar.findMethod(mname, callee); err != nil {
 					return nil, err
 				} else if embeddedMethod != nil {
 					return embeddedMethod, nil
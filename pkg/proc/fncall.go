@@ -60,8 +60,36 @@ var (
 	errNotAGoFunction             = errors.New("not a Go function")
 	errFuncCallNotAllowed         = errors.New("function calls not allowed without using 'call'")
 	errFuncCallNotAllowedStrAlloc = errors.New("literal string can not be allocated because function calls are not allowed without using 'call'")
+	errFuncCallNotAllowedLitAlloc = errors.New("literal can not be allocated because function calls are not allowed without using 'call'")
+	errFuncCallNotAllowedNewKey   = errors.New("key not found, new keys can not be inserted because function calls are not allowed without using 'call'")
 )
 
+// funcCallNotAllowedConvErr is the error returned when converting a
+// value to a non-empty interface, which calls runtime.getitab, without
+// using 'call'.
+type funcCallNotAllowedConvErr struct {
+	srcType, dstType string
+}
+
+func (err funcCallNotAllowedConvErr) Error() string {
+	return fmt.Sprintf("can not convert value of type %s to %s because function calls are not allowed without using 'call'", err.srcType, err.dstType)
+}
+
+// IsFuncCallNotAllowed returns true if err was returned because evaluating
+// an expression, or assigning a value, needs to call a function in the
+// target (for example to allocate memory) but function calls were not
+// allowed.
+func IsFuncCallNotAllowed(err error) bool {
+	if _, isconv := err.(funcCallNotAllowedConvErr); isconv {
+		return true
+	}
+	switch err {
+	case errFuncCallNotAllowed, errFuncCallNotAllowedStrAlloc, errFuncCallNotAllowedLitAlloc, errFuncCallNotAllowedNewKey:
+		return true
+	}
+	return false
+}
+
 type functionCallState struct {
 	// savedRegs contains the saved registers
 	savedRegs Registers
@@ -75,6 +103,9 @@ type functionCallState struct {
 	receiver *Variable
 	// closureAddr is the address of the closure being called
 	closureAddr uint64
+	// variadic is true if the trailing arguments of the call must be packed
+	// into a slice and passed as the last argument of fn
+	variadic bool
 	// formalArgs are the formal arguments of fn
 	formalArgs []funcCallArg
 	// argFrameSize contains the size of the arguments
@@ -115,6 +146,13 @@ type callContext struct {
 	// stacks is a slice of known goroutine stacks used to check for
 	// inappropriate escapes
 	stacks []stack
+
+	// pinHead is the address of the first chunk of a list of the values
+	// allocated in the target during the evaluation, pinTail is the address
+	// of the last chunk and npins the number of values stored in it.
+	// See allocMemory.
+	pinHead, pinTail uint64
+	npins            int64
 }
 
 type continueRequest struct {
@@ -312,6 +350,16 @@ func evalFunctionCall(scope *EvalScope, node *ast.CallExpr) (*Variable, error) {
 	if err := writePointer(bi, thread, regs.SP()-3*uint64(bi.Arch.PtrSize()), uint64(fncall.argFrameSize)); err != nil {
 		return nil, err
 	}
+	if scope.callCtx.pinHead != 0 {
+		// debugCallV1 saves all registers that may contain pointers in its
+		// frame, which the garbage collector scans conservatively, this keeps
+		// the values allocated during the evaluation reachable for the
+		// duration of the call. The original value of DX is restored with the
+		// other registers when the call returns.
+		if err := thread.SetDX(scope.callCtx.pinHead); err != nil {
+			return nil, err
+		}
+	}
 
 	fncallLog("function call initiated %v frame size %d goroutine %d (thread %d)", fncall.fn, fncall.argFrameSize, scope.g.ID, thread.ThreadID())
 
@@ -441,8 +489,14 @@ func funcCallEvalFuncExpr(scope *EvalScope, fncall *functionCallState, allowCall
 		}()
 	}
 
-	fnvar, err := scope.evalAST(fncall.expr.Fun)
-	if err == errFuncCallNotAllowed {
+	var fnvar *Variable
+	var err error
+	if sel, ok := fncall.expr.Fun.(*ast.SelectorExpr); ok {
+		fnvar, err = scope.evalSelector(sel, true)
+	} else {
+		fnvar, err = scope.evalAST(fncall.expr.Fun)
+	}
+	if err == errFuncCallNotAllowed || err == errFuncCallNotAllowedLitAlloc {
 		// we can't determine the frame size because callexpr.Fun can't be
 		// evaluated without enabling function calls, just set up an argument
 		// frame for the maximum possible argument size.
@@ -477,27 +531,152 @@ func funcCallEvalFuncExpr(scope *EvalScope, fncall *functionCallState, allowCall
 
 	argnum := len(fncall.expr.Args)
 
+	nformal := len(fncall.formalArgs)
+	maybeVariadic := funcCallVariadic(bi, fncall, fnvar)
+
 	// If the function variable has a child then that child is the method
 	// receiver. However, if the method receiver is not being used (e.g.
 	// func (_ X) Foo()) then it will not actually be listed as a formal
 	// argument. Ensure that we are really off by 1 to add the receiver to
 	// the function call.
-	if len(fnvar.Children) > 0 && argnum == (len(fncall.formalArgs)-1) {
+	if len(fnvar.Children) > 0 && (argnum == nformal-1 || (maybeVariadic && nformal > 1 && sameType(fncall.formalArgs[0].typ, fnvar.Children[0].DwarfType))) {
 		argnum++
 		fncall.receiver = &fnvar.Children[0]
 		fncall.receiver.Name = exprToString(fncall.expr.Fun)
+		if nformal == 1 {
+			maybeVariadic = false
+		}
 	}
 
-	if argnum > len(fncall.formalArgs) {
+	fncall.variadic = false
+	switch {
+	case fncall.expr.Ellipsis.IsValid():
+		if !maybeVariadic || argnum != nformal {
+			return fmt.Errorf("can not use ... in call to %s", fncall.fn.Name)
+		}
+	case maybeVariadic && argnum >= nformal-1:
+		fncall.variadic = argnum != nformal
+	case argnum > nformal:
 		return errTooManyArguments
-	}
-	if argnum < len(fncall.formalArgs) {
+	case argnum < nformal:
 		return errNotEnoughArguments
 	}
 
 	return nil
 }
 
+// funcCallVariadic returns true if the function called by fncall, whose
+// value is fnvar, is variadic.
+// The debug information of a function doesn't say whether it is variadic
+// but the type of a function value does, as does the func type describing
+// the function if the program uses it somewhere. If neither is available,
+// or if both a variadic and a non-variadic func type match the function,
+// we assume that functions whose last argument is a slice are variadic.
+func funcCallVariadic(bi *BinaryInfo, fncall *functionCallState, fnvar *Variable) bool {
+	nformal := len(fncall.formalArgs)
+	if nformal == 0 {
+		return false
+	}
+	if _, isslice := resolveTypedef(fncall.formalArgs[nformal-1].typ).(*godwarf.SliceType); !isslice {
+		return false
+	}
+	if typ, isfunc := resolveTypedef(fnvar.DwarfType).(*godwarf.FuncType); isfunc && typ.Offset != 0 {
+		// fnvar is a func value, its type was read from debug_info
+		return funcTypeVariadic(typ)
+	}
+
+	_, formalArgs, err := funcCallArgs(fncall.fn, bi, true)
+	if err != nil {
+		return true
+	}
+	candidates := [][]funcCallArg{formalArgs}
+	if len(fnvar.Children) > 0 && len(formalArgs) > 0 && !formalArgs[0].isret {
+		// the type of a method value doesn't include the receiver
+		candidates = append(candidates, formalArgs[1:])
+	}
+
+	foundPlain, foundVariadic := false, false
+	for name, ref := range bi.types {
+		if !strings.HasPrefix(name, "func(") {
+			continue
+		}
+		typ, err := bi.Images[ref.imageIndex].Type(ref.offset)
+		if err != nil {
+			continue
+		}
+		ftyp, isfunc := resolveTypedef(typ).(*godwarf.FuncType)
+		if !isfunc {
+			continue
+		}
+		for _, args := range candidates {
+			if match, variadic := funcTypeMatches(ftyp, args); match {
+				if variadic {
+					foundVariadic = true
+				} else {
+					foundPlain = true
+				}
+			}
+		}
+		if foundPlain && foundVariadic {
+			break
+		}
+	}
+	return foundVariadic || !foundPlain
+}
+
+// funcTypeVariadic returns true if typ is the type of a variadic function.
+// The parameters of a func type read from debug_info are its arguments
+// followed by its return values, for variadic functions the arguments are
+// followed by a DotDotDotType.
+func funcTypeVariadic(typ *godwarf.FuncType) bool {
+	for _, param := range typ.ParamType {
+		if _, isdotdotdot := param.(*godwarf.DotDotDotType); isdotdotdot {
+			return true
+		}
+	}
+	return false
+}
+
+// funcTypeMatches returns true if typ describes a function with the
+// arguments and return values in formalArgs, as returned by funcCallArgs
+// with includeRet set. Variadic is true if typ is variadic.
+func funcTypeMatches(typ *godwarf.FuncType, formalArgs []funcCallArg) (match, variadic bool) {
+	params := typ.ParamType
+	for i := range formalArgs {
+		if len(params) == 0 {
+			return false, false
+		}
+		if _, isdotdotdot := params[0].(*godwarf.DotDotDotType); isdotdotdot {
+			// ... can only follow the last argument, which must be a slice
+			if i == 0 || formalArgs[i-1].isret || !formalArgs[i].isret {
+				return false, false
+			}
+			if _, isslice := resolveTypedef(formalArgs[i-1].typ).(*godwarf.SliceType); !isslice {
+				return false, false
+			}
+			variadic = true
+			params = params[1:]
+			if len(params) == 0 {
+				return false, false
+			}
+		}
+		if !sameType(params[0], formalArgs[i].typ) {
+			return false, false
+		}
+		params = params[1:]
+	}
+	if len(params) == 1 && len(formalArgs) > 0 {
+		// variadic function without return values
+		last := formalArgs[len(formalArgs)-1]
+		_, isdotdotdot := params[0].(*godwarf.DotDotDotType)
+		_, isslice := resolveTypedef(last.typ).(*godwarf.SliceType)
+		if isdotdotdot && isslice && !last.isret {
+			return true, true
+		}
+	}
+	return len(params) == 0, variadic
+}
+
 type funcCallArg struct {
 	name  string
 	typ   godwarf.Type
@@ -514,6 +693,9 @@ func funcCallEvalArgs(scope *EvalScope, fncall *functionCallState, argFrameAddr
 	}
 
 	if fncall.receiver != nil {
+		if err := funcCallAddressableReceiver(scope, fncall.receiver); err != nil {
+			return fmt.Errorf("cannot use %s as receiver of %s: %v", fncall.receiver.Name, fncall.fn.Name, err)
+		}
 		err := funcCallCopyOneArg(scope, fncall, fncall.receiver, &fncall.formalArgs[0], argFrameAddr)
 		if err != nil {
 			return err
@@ -523,12 +705,29 @@ func funcCallEvalArgs(scope *EvalScope, fncall *functionCallState, argFrameAddr
 
 	for i := range fncall.formalArgs {
 		formalArg := &fncall.formalArgs[i]
+		last := i == len(fncall.formalArgs)-1
 
-		actualArg, err := scope.evalAST(fncall.expr.Args[i])
-		if err != nil {
-			return fmt.Errorf("error evaluating %q as argument %s in function %s: %v", exprToString(fncall.expr.Args[i]), formalArg.name, fncall.fn.Name, err)
+		var actualArg *Variable
+		var err error
+		if last && fncall.variadic {
+			actualArg, err = funcCallVariadicArg(scope, fncall, formalArg, nil, fncall.expr.Args[i:])
+			if err != nil {
+				return err
+			}
+		} else {
+			actualArg, err = scope.evalAST(fncall.expr.Args[i])
+			if err != nil {
+				return fmt.Errorf("error evaluating %q as argument %s in function %s: %v", exprToString(fncall.expr.Args[i]), formalArg.name, fncall.fn.Name, err)
+			}
+			actualArg.Name = exprToString(fncall.expr.Args[i])
+
+			if last && !fncall.expr.Ellipsis.IsValid() && funcCallPackArg(actualArg, formalArg.typ) {
+				actualArg, err = funcCallVariadicArg(scope, fncall, formalArg, actualArg, fncall.expr.Args[i:])
+				if err != nil {
+					return err
+				}
+			}
 		}
-		actualArg.Name = exprToString(fncall.expr.Args[i])
 
 		err = funcCallCopyOneArg(scope, fncall, actualArg, formalArg, argFrameAddr)
 		if err != nil {
@@ -539,6 +738,53 @@ func funcCallEvalArgs(scope *EvalScope, fncall *functionCallState, argFrameAddr
 	return nil
 }
 
+// funcCallPackArg returns true if actualArg, the last argument of a call,
+// should be packed into a slice because the callee is variadic. This is
+// only true if actualArg can not be assigned to typ but can be assigned to
+// its elements.
+func funcCallPackArg(actualArg *Variable, typ godwarf.Type) bool {
+	styp, isslice := typ.(*godwarf.SliceType)
+	if !isslice || actualArg == nilVariable || actualArg.isType(typ, reflect.Slice) == nil {
+		return false
+	}
+	if _, isiface := resolveTypedef(styp.ElemType).(*godwarf.InterfaceType); isiface {
+		return true
+	}
+	return actualArg.isType(resolveTypedef(styp.ElemType), reflect.Invalid) == nil
+}
+
+// funcCallVariadicArg packs the trailing arguments of a call to a variadic
+// function into a slice of type formalArg.typ. If first is not nil it is
+// the value of the first trailing argument, which was already evaluated.
+func funcCallVariadicArg(scope *EvalScope, fncall *functionCallState, formalArg *funcCallArg, first *Variable, args []ast.Expr) (*Variable, error) {
+	if len(args) == 0 {
+		return nilVariable, nil
+	}
+	v, err := scope.allocSlice(formalArg.typ, int64(len(args)))
+	if err != nil {
+		return nil, err
+	}
+	v.Name = formalArg.name
+	for i, arg := range args {
+		argv := first
+		if i > 0 || argv == nil {
+			argv, err = scope.evalAST(arg)
+			if err != nil {
+				return nil, fmt.Errorf("error evaluating %q as argument %s in function %s: %v", exprToString(arg), formalArg.name, fncall.fn.Name, err)
+			}
+			argv.Name = exprToString(arg)
+		}
+		ev, err := v.sliceAccess(i)
+		if err != nil {
+			return nil, err
+		}
+		if err := scope.setValue(ev, argv, argv.Name); err != nil {
+			return nil, fmt.Errorf("cannot use %s as argument %s in function %s: %v", argv.Name, formalArg.name, fncall.fn.Name, err)
+		}
+	}
+	return v, nil
+}
+
 func funcCallCopyOneArg(scope *EvalScope, fncall *functionCallState, actualArg *Variable, formalArg *funcCallArg, argFrameAddr uint64) error {
 	if scope.callCtx.checkEscape {
 		//TODO(aarzilli): only apply the escapeCheck to leaking parameters.
@@ -552,9 +798,6 @@ func funcCallCopyOneArg(scope *EvalScope, fncall *functionCallState, actualArg *
 		}
 	}
 
-	//TODO(aarzilli): autmoatic wrapping in interfaces for cases not handled
-	// by convertToEface.
-
 	formalArgVar := newVariable(formalArg.name, uint64(formalArg.off+int64(argFrameAddr)), formalArg.typ, scope.BinInfo, scope.Mem)
 	if err := scope.setValue(formalArgVar, actualArg, actualArg.Name); err != nil {
 		return err
@@ -563,6 +806,46 @@ func funcCallCopyOneArg(scope *EvalScope, fncall *functionCallState, actualArg *
 	return nil
 }
 
+// funcCallAddressableReceiver makes sure that recv, the receiver of a
+// method with a pointer receiver, points to target memory that outlives
+// the call. Values that are not addressable, such as the return values of
+// other calls or variables stored in registers, are copied to the heap the
+// way the compiler would do with a temporary: changes made by the method
+// will not be visible in the original value.
+func funcCallAddressableReceiver(scope *EvalScope, recv *Variable) error {
+	if recv.Kind != reflect.Ptr || recv.Addr != 0 || len(recv.Children) != 1 {
+		return nil
+	}
+	pointee := &recv.Children[0]
+	if pointee.Addr != 0 && pointee.Flags&VariableFakeAddress == 0 {
+		return nil
+	}
+	var buf []byte
+	if pointee.Addr != 0 {
+		// Return values live in the stack space used by function calls, read
+		// them before the allocation overwrites them.
+		buf = make([]byte, pointee.DwarfType.Size())
+		if _, err := pointee.mem.ReadMemory(buf, pointee.Addr); err != nil {
+			return err
+		}
+	}
+	addr, err := allocValue(scope, pointee.DwarfType, 1)
+	if err != nil {
+		return err
+	}
+	tmp := newVariable(pointee.Name, addr, pointee.DwarfType, scope.BinInfo, scope.Mem)
+	if buf != nil {
+		_, err = scope.Mem.WriteMemory(addr, buf)
+	} else {
+		err = scope.setValue(tmp, pointee, recv.Name)
+	}
+	if err != nil {
+		return err
+	}
+	recv.Children[0] = *tmp
+	return nil
+}
+
 func funcCallArgs(fn *Function, bi *BinaryInfo, includeRet bool) (argFrameSize int64, formalArgs []funcCallArg, err error) {
 	const CFA = 0x1000
 
@@ -671,6 +954,14 @@ func escapeCheck(v *Variable, name string, stack stack) error {
 			}
 		}
 	case reflect.Func:
+		if v.closureAddr == 0 && v.Base != 0 {
+			// function names don't point to anything and method values will be
+			// copied into a new closure, along with their receiver.
+			if len(v.Children) > 0 {
+				return escapeCheck(&v.Children[0], name, stack)
+			}
+			return nil
+		}
 		if err := escapeCheckPointer(v.funcvalAddr(), name, stack); err != nil {
 			return err
 		}
@@ -905,37 +1196,409 @@ func allocString(scope *EvalScope, v *Variable) error {
 	if scope.callCtx == nil {
 		return errFuncCallNotAllowedStrAlloc
 	}
+	var err error
+	v.Base, err = allocMemory(scope, v.Len, 0)
+	if err != nil {
+		return err
+	}
+	_, err = scope.Mem.WriteMemory(v.Base, []byte(constant.StringVal(v.Value)))
+	return err
+}
+
+// runtimePtr is a pointer argument of runtimeCall, it is converted to the
+// type of the corresponding formal argument of the runtime function, the
+// names of the types of the runtime change between versions of Go.
+type runtimePtr uint64
+
+// runtimeCall calls the runtime function fnname, which must return a
+// pointer, and returns the address it points to. Each argument must be a
+// runtimePtr, an int64 or a bool.
+func runtimeCall(scope *EvalScope, fnname string, args ...interface{}) (uint64, error) {
+	fn := scope.BinInfo.LookupFunc["runtime."+fnname]
+	if fn == nil {
+		return 0, fmt.Errorf("could not find function runtime.%s", fnname)
+	}
+	_, formalArgs, err := funcCallArgs(fn, scope.BinInfo, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(formalArgs) != len(args) {
+		return 0, fmt.Errorf("unexpected number of arguments for runtime.%s", fnname)
+	}
+	argExprs := make([]ast.Expr, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case runtimePtr:
+			if arg == 0 {
+				argExprs[i] = &ast.Ident{Name: "nil"}
+				break
+			}
+			argExprs[i] = &ast.CallExpr{
+				Fun:  &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(formalArgs[i].typ.String())},
+				Args: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: fmt.Sprintf("%#x", uint64(arg))}},
+			}
+		case int64:
+			argExprs[i] = &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(arg, 10)}
+		case bool:
+			argExprs[i] = &ast.Ident{Name: strconv.FormatBool(arg)}
+		default:
+			return 0, fmt.Errorf("unsupported argument type %T in call to runtime.%s", arg, fnname)
+		}
+	}
+
 	savedLoadCfg := scope.callCtx.retLoadCfg
 	scope.callCtx.retLoadCfg = loadFullValue
 	defer func() {
 		scope.callCtx.retLoadCfg = savedLoadCfg
 	}()
-	mallocv, err := evalFunctionCall(scope, &ast.CallExpr{
+	retv, err := evalFunctionCall(scope, &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
 			X:   &ast.Ident{Name: "runtime"},
-			Sel: &ast.Ident{Name: "mallocgc"},
-		},
-		Args: []ast.Expr{
-			&ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(int(v.Len))},
-			&ast.Ident{Name: "nil"},
-			&ast.Ident{Name: "false"},
+			Sel: &ast.Ident{Name: fnname},
 		},
+		Args: argExprs,
 	})
+	if err != nil {
+		return 0, err
+	}
+	if retv.Unreadable != nil {
+		return 0, retv.Unreadable
+	}
+	if (retv.Kind != reflect.Ptr && retv.Kind != reflect.UnsafePointer) || len(retv.Children) != 1 {
+		return 0, fmt.Errorf("unexpected return type for %s call: %s", fnname, retv.TypeString())
+	}
+	return retv.Children[0].Addr, nil
+}
+
+// pinChunkLen is the number of pointers in each chunk of the list of
+// values allocated during the evaluation, the last one is the address of
+// the next chunk.
+const pinChunkLen = 16
+
+// allocMemory allocates size bytes of zeroed memory in the target by
+// calling runtime.mallocgc, typeAddr is the address of the runtime type
+// of the values stored in it or 0.
+// The allocated memory is added to the list of values allocated during
+// the evaluation, which is what keeps it reachable until it's assigned
+// to a variable or passed to a function: the garbage collector can run
+// during any of the function calls that follow.
+func allocMemory(scope *EvalScope, size int64, typeAddr uint64) (uint64, error) {
+	// Make room in the list before allocating so that the allocated memory
+	// is never unreachable during a function call.
+	if err := reservePin(scope); err != nil {
+		return 0, err
+	}
+	addr, err := runtimeCall(scope, "mallocgc", size, runtimePtr(typeAddr), true)
+	if err != nil {
+		return 0, err
+	}
+	callCtx := scope.callCtx
+	ptrSize := int64(scope.BinInfo.Arch.PtrSize())
+	if err := writePointer(scope.BinInfo, scope.Mem, callCtx.pinTail+uint64(callCtx.npins*ptrSize), addr); err != nil {
+		return 0, err
+	}
+	callCtx.npins++
+	return addr, nil
+}
+
+// reservePin makes sure that the list of values allocated during the
+// evaluation has room for one more value, allocating a new chunk if
+// necessary.
+func reservePin(scope *EvalScope) error {
+	callCtx := scope.callCtx
+	if callCtx.pinTail != 0 && callCtx.npins < pinChunkLen-1 {
+		return nil
+	}
+	bi := scope.BinInfo
+	ptrSize := int64(bi.Arch.PtrSize())
+	typ, err := bi.findType("unsafe.Pointer")
+	if err != nil {
+		return fmt.Errorf("could not find type unsafe.Pointer: %v", err)
+	}
+	typeAddr, _, err := runtimeType(scope, typ)
+	if err != nil {
+		return err
+	}
+	chunk, err := runtimeCall(scope, "mallocgc", pinChunkLen*ptrSize, runtimePtr(typeAddr), true)
 	if err != nil {
 		return err
 	}
-	if mallocv.Unreadable != nil {
-		return mallocv.Unreadable
+	if callCtx.pinHead == 0 {
+		callCtx.pinHead = chunk
+	} else if err := writePointer(bi, scope.Mem, callCtx.pinTail+uint64((pinChunkLen-1)*ptrSize), chunk); err != nil {
+		return err
 	}
-	if mallocv.DwarfType.String() != "*void" {
-		return fmt.Errorf("unexpected return type for mallocgc call: %v", mallocv.DwarfType.String())
+	callCtx.pinTail = chunk
+	callCtx.npins = 0
+	return nil
+}
+
+// allocValue allocates space for n values of type typ in the target.
+// If values of type typ contain pointers the memory is allocated using
+// the runtime type of typ so that the garbage collector can see them.
+func allocValue(scope *EvalScope, typ godwarf.Type, n int64) (uint64, error) {
+	if scope.callCtx == nil {
+		return 0, errFuncCallNotAllowedLitAlloc
 	}
-	if len(mallocv.Children) != 1 {
-		return errors.New("internal error, could not interpret return value of mallocgc call")
+	var typeAddr uint64
+	if hasPointers(typ) {
+		var err error
+		typeAddr, _, err = runtimeType(scope, typ)
+		if err != nil {
+			return 0, err
+		}
 	}
-	v.Base = mallocv.Children[0].Addr
-	_, err = scope.Mem.WriteMemory(v.Base, []byte(constant.StringVal(v.Value)))
-	return err
+	return allocMemory(scope, alignAddr(typ.Size(), typ.Align())*n, typeAddr)
+}
+
+// runtimeType returns the address and the kind of the runtime type of typ.
+func runtimeType(scope *EvalScope, typ godwarf.Type) (typeAddr, typeKind uint64, err error) {
+	if typ.Common().Offset == 0 {
+		// types synthesized by us (for example pointers and arrays) do not
+		// have a runtime type, unless the same type is also used by the
+		// target program.
+		rtyp, err := scope.BinInfo.findType(typ.String())
+		if err != nil {
+			return 0, 0, fmt.Errorf("could not find runtime type of %s", typ)
+		}
+		typ = rtyp
+	}
+	typeAddr, typeKind, found, err := dwarfToRuntimeType(scope.BinInfo, scope.Mem, typ)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return 0, 0, fmt.Errorf("could not find runtime type of %s", typ)
+	}
+	return typeAddr, typeKind, nil
+}
+
+// hasPointers returns true if values of type typ can contain pointers.
+func hasPointers(typ godwarf.Type) bool {
+	switch typ := resolveTypedef(typ).(type) {
+	case *godwarf.BoolType, *godwarf.IntType, *godwarf.UintType, *godwarf.FloatType, *godwarf.ComplexType:
+		return false
+	case *godwarf.ArrayType:
+		return typ.Count > 0 && hasPointers(typ.Type)
+	case *godwarf.StructType:
+		for _, field := range typ.Field {
+			if hasPointers(field.Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// allocFuncval allocates a funcval for fnvar, a function or a method
+// value that doesn't exist in the target, so that it can be used as a
+// func value, and returns its address.
+func allocFuncval(scope *EvalScope, fnvar *Variable, dsttyp godwarf.Type) (uint64, error) {
+	if scope.callCtx == nil {
+		return 0, errFuncCallNotAllowedLitAlloc
+	}
+	bi := scope.BinInfo
+	fn := bi.PCToFunc(fnvar.Base)
+	if fn == nil {
+		return 0, fmt.Errorf("could not find function for %#x", fnvar.Base)
+	}
+	var recv *Variable
+	if len(fnvar.Children) > 0 {
+		// A method value is a closure of the method value wrapper, which the
+		// compiler only generates if the program uses that method value.
+		recv = &fnvar.Children[0]
+		wrapper := bi.LookupFunc[fn.Name+"-fm"]
+		if wrapper == nil {
+			return 0, fmt.Errorf("can not use method value %s: %s-fm is not in the target program", fnvar.Name, fn.Name)
+		}
+		fn = wrapper
+	}
+
+	_, formalArgs, err := funcCallArgs(fn, bi, true)
+	if err != nil {
+		return 0, err
+	}
+	if ftyp, isfunc := resolveTypedef(dsttyp).(*godwarf.FuncType); !isfunc {
+		return 0, fmt.Errorf("can not convert function to %s", dsttyp)
+	} else if match, _ := funcTypeMatches(ftyp, formalArgs); !match {
+		sig, err := funcSignature(fn, bi, false)
+		if err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("can not convert value of type %s to %s", sig, dsttyp.Common().Name)
+	}
+
+	ptrSize := int64(bi.Arch.PtrSize())
+	if recv == nil {
+		addr, err := allocMemory(scope, ptrSize, 0)
+		if err != nil {
+			return 0, err
+		}
+		return addr, writePointer(bi, scope.Mem, addr, fn.Entry)
+	}
+
+	var addr, recvAddr uint64
+	if !hasPointers(recv.DwarfType) {
+		off := alignAddr(ptrSize, recv.DwarfType.Align())
+		addr, err = allocMemory(scope, off+recv.DwarfType.Size(), 0)
+		if err != nil {
+			return 0, err
+		}
+		recvAddr = addr + uint64(off)
+	} else {
+		// The closure is a struct { F uintptr; R T }, which has no runtime
+		// type. Allocate two values of the type T of the receiver, so that
+		// the garbage collector sees the pointers in it, and put F in the
+		// last word of the first one (F points to the text segment, which
+		// the garbage collector ignores if it's a pointer slot of T).
+		stride := alignAddr(recv.DwarfType.Size(), recv.DwarfType.Align())
+		recvAddr, err = allocValue(scope, recv.DwarfType, 2)
+		if err != nil {
+			return 0, err
+		}
+		recvAddr += uint64(stride)
+		addr = recvAddr - uint64(ptrSize)
+	}
+	if err := writePointer(bi, scope.Mem, addr, fn.Entry); err != nil {
+		return 0, err
+	}
+	recvv := newVariable(recv.Name, recvAddr, recv.DwarfType, bi, scope.Mem)
+	return addr, scope.setValue(recvv, recv, recv.Name)
+}
+
+// itabMethod returns method mname of the dynamic type of v, a non-empty
+// interface, as a function variable with the receiver as its only child.
+// Like a dynamic call compiled by Go, the method is read from the method
+// table of the itab, which means that methods of types that can not be
+// named in an expression are found and that methods with a value
+// receiver are called through their autogenerated pointer wrapper.
+// Returns nil if v is an empty interface or its interface type doesn't
+// have a method called mname.
+func itabMethod(v *Variable, mname string) (*Variable, error) {
+	_type, data, isnil := v.readInterface()
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	var tab *Variable
+	ityp := resolveTypedef(&v.RealType.(*godwarf.InterfaceType).TypedefType).(*godwarf.StructType)
+	for _, f := range ityp.Field {
+		if f.Name == "tab" {
+			tab, _ = v.toField(f)
+		}
+	}
+	if tab == nil {
+		// empty interfaces do not have a method table
+		return nil, nil
+	}
+	if isnil {
+		return nil, errors.New("nil pointer dereference")
+	}
+	if data == nil {
+		return nil, errors.New("invalid interface type")
+	}
+
+	tab = tab.maybeDereference()
+	inter, err := tab.structMember("inter")
+	if err != nil {
+		return nil, err
+	}
+	inter = inter.maybeDereference()
+	methods, err := inter.structMember(interfacetypeFieldMhdr)
+	if err != nil {
+		return nil, err
+	}
+	methods.loadArrayValues(0, LoadConfig{false, 1, 0, 4096, -1, 0})
+	if methods.Unreadable != nil {
+		return nil, methods.Unreadable
+	}
+	mds, err := loadModuleData(v.bi, v.mem)
+	if err != nil {
+		return nil, err
+	}
+
+	// itab.fun[i] is the implementation of interfacetype.mhdr[i]
+	idx := -1
+	for i, im := range methods.Children {
+		nameField := im.loadFieldNamed(imethodFieldName)
+		if nameField == nil || nameField.Value == nil {
+			return nil, errors.New("invalid interface type")
+		}
+		nameoff, _ := constant.Int64Val(nameField.Value)
+		name, _, _, err := resolveNameOff(v.bi, mds, inter.Addr, uint64(nameoff), v.mem)
+		if err != nil {
+			return nil, err
+		}
+		if name == mname {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, nil
+	}
+
+	fun, err := tab.structMember("fun")
+	if err != nil {
+		return nil, err
+	}
+	ptrSize := v.bi.Arch.PtrSize()
+	pc, err := readUintRaw(v.mem, fun.Addr+uint64(idx*ptrSize), int64(ptrSize))
+	if err != nil {
+		return nil, err
+	}
+	fn := v.bi.PCToFunc(pc)
+	if fn == nil {
+		return nil, fmt.Errorf("could not find function for method %s at %#x", mname, pc)
+	}
+	r, err := functionToVariable(fn, v.bi, v.mem)
+	if err != nil {
+		return nil, err
+	}
+
+	// The data word of the interface is the receiver, it's either the value
+	// itself, for pointer shaped types, or a pointer to the value.
+	typ, kind, err := runtimeTypeToDIE(_type, data.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if kind&kindDirectIface == 0 {
+		if _, isptr := resolveTypedef(typ).(*godwarf.PtrType); !isptr {
+			typ = pointerTo(typ, v.bi.Arch)
+		}
+	}
+	r.Children = append(r.Children, *data.newVariable(v.Name, data.Addr, typ, data.mem))
+	return r, nil
+}
+
+// funcSignature returns the type of fn as a string, without argument
+// names. If removeReceiver is true the first argument is omitted.
+func funcSignature(fn *Function, bi *BinaryInfo, removeReceiver bool) (string, error) {
+	_, formalArgs, err := funcCallArgs(fn, bi, true)
+	if err != nil {
+		return "", err
+	}
+	if removeReceiver && len(formalArgs) > 0 && !formalArgs[0].isret {
+		formalArgs = formalArgs[1:]
+	}
+	var args, rets []string
+	for _, formalArg := range formalArgs {
+		if formalArg.isret {
+			rets = append(rets, formalArg.typ.String())
+		} else {
+			args = append(args, formalArg.typ.String())
+		}
+	}
+	s := "func(" + strings.Join(args, ", ") + ")"
+	switch len(rets) {
+	case 0:
+	case 1:
+		s += " " + rets[0]
+	default:
+		s += " (" + strings.Join(rets, ", ") + ")"
+	}
+	return s, nil
 }
 
 func isCallInjectionStop(t *Target, thread Thread, loc *Location) bool {
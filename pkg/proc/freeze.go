@@ -0,0 +1,229 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"sort"
+
+	"github.com/go-delve/delve/pkg/astutil"
+)
+
+// ErrFreezeUnsupported is returned by FreezeGoroutine when the backend
+// can not keep a single thread stopped while resuming the target.
+var ErrFreezeUnsupported = errors.New("freezing goroutines is not supported by this backend")
+
+// threadHolder is implemented by backends that can keep a thread stopped
+// while the rest of the target is resumed.
+type threadHolder interface {
+	// HoldThread marks th so that it will not be resumed (if hold is true)
+	// or releases it (if hold is false).
+	HoldThread(th Thread, hold bool) error
+}
+
+// frozenGoroutine describes a goroutine suspended by FreezeGoroutine.
+type frozenGoroutine struct {
+	// bpaddr is the address of the breakpoint that traps the goroutine the
+	// next time it is scheduled, or 0 if the goroutine was running when it
+	// was frozen.
+	bpaddr uint64
+	// thread is the thread held on behalf of the goroutine, or nil if the
+	// goroutine hasn't been scheduled since it was frozen.
+	thread Thread
+}
+
+// FreezeGoroutine suspends goroutine gid indefinitely, while the rest of
+// the target keeps running when it is resumed.
+// If the goroutine is currently running its thread is kept stopped,
+// otherwise a breakpoint is set at the address where the goroutine will
+// resume execution and its thread is kept stopped as soon as it is
+// scheduled. Since parked goroutines share their resume address the
+// breakpoint has a condition that only matches frozen goroutines.
+// Only supported on the native linux backend, ErrFreezeUnsupported is
+// returned by all other backends.
+// Note that a frozen goroutine still holds its P and the garbage collector
+// will not be able to stop the world while it is on a thread, this will
+// eventually block the whole program.
+func (t *Target) FreezeGoroutine(gid int) error {
+	if _, err := t.Valid(); err != nil {
+		return err
+	}
+	holder, ok := t.proc.(threadHolder)
+	if !ok {
+		return ErrFreezeUnsupported
+	}
+	if t.frozenGoroutines[gid] != nil {
+		return nil
+	}
+	g, err := FindGoroutine(t, gid)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return fmt.Errorf("unknown goroutine %d", gid)
+	}
+
+	fg := &frozenGoroutine{}
+	switch {
+	case g.Thread != nil:
+		if err := holder.HoldThread(g.Thread, true); err != nil {
+			return err
+		}
+		fg.thread = g.Thread
+	case g.Status == Gdead:
+		return fmt.Errorf("goroutine %d has exited", gid)
+	default:
+		if _, err := t.SetBreakpoint(g.PC, FreezeBreakpoint, nil); err != nil {
+			if _, exists := err.(BreakpointExistsError); !exists {
+				return err
+			}
+		}
+		fg.bpaddr = g.PC
+	}
+
+	if t.frozenGoroutines == nil {
+		t.frozenGoroutines = make(map[int]*frozenGoroutine)
+	}
+	t.frozenGoroutines[gid] = fg
+	if fg.bpaddr != 0 {
+		t.updateFreezeCondition(fg.bpaddr)
+	}
+	return nil
+}
+
+// ThawGoroutine releases a goroutine suspended by FreezeGoroutine.
+func (t *Target) ThawGoroutine(gid int) error {
+	fg := t.frozenGoroutines[gid]
+	if fg == nil {
+		return fmt.Errorf("goroutine %d is not frozen", gid)
+	}
+	delete(t.frozenGoroutines, gid)
+	if fg.thread != nil {
+		if err := t.proc.(threadHolder).HoldThread(fg.thread, false); err != nil {
+			return err
+		}
+	}
+	if fg.bpaddr != 0 && fg.thread == nil {
+		if t.updateFreezeCondition(fg.bpaddr) {
+			return nil
+		}
+		return t.clearFreezeBreakpoint(fg.bpaddr)
+	}
+	return nil
+}
+
+// ThawAllGoroutines releases all goroutines suspended by FreezeGoroutine.
+func (t *Target) ThawAllGoroutines() error {
+	for _, gid := range t.FrozenGoroutines() {
+		if err := t.ThawGoroutine(gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FrozenGoroutines returns the sorted list of IDs of the goroutines
+// suspended by FreezeGoroutine.
+func (t *Target) FrozenGoroutines() []int {
+	r := make([]int, 0, len(t.frozenGoroutines))
+	for gid := range t.frozenGoroutines {
+		r = append(r, gid)
+	}
+	sort.Ints(r)
+	return r
+}
+
+// IsGoroutineFrozen returns true if goroutine gid was suspended by
+// FreezeGoroutine.
+func (t *Target) IsGoroutineFrozen(gid int) bool {
+	return t.frozenGoroutines[gid] != nil
+}
+
+// updateFreezeCondition sets the condition of the freeze breakpoint at
+// addr so that it matches all the goroutines that are frozen at addr and
+// haven't been trapped yet. Returns false if there are no such goroutines.
+func (t *Target) updateFreezeCondition(addr uint64) bool {
+	var cond ast.Expr
+	for _, gid := range t.FrozenGoroutines() {
+		fg := t.frozenGoroutines[gid]
+		if fg.bpaddr != addr || fg.thread != nil {
+			continue
+		}
+		gcond := astutil.Eql(astutil.Sel(astutil.PkgVar("runtime", "curg"), "goid"), astutil.Int(int64(gid)))
+		if cond == nil {
+			cond = gcond
+		} else {
+			cond = astutil.Or(cond, gcond)
+		}
+	}
+	if bp := t.Breakpoints().M[addr]; bp != nil {
+		bp.freezeCond = cond
+	}
+	return cond != nil
+}
+
+func (t *Target) clearFreezeBreakpoint(addr uint64) error {
+	bp := t.Breakpoints().M[addr]
+	if bp == nil {
+		return nil
+	}
+	bp.Kind &^= FreezeBreakpoint
+	bp.freezeCond = nil
+	if bp.Kind != 0 {
+		return nil
+	}
+	if err := t.proc.EraseBreakpoint(bp); err != nil {
+		return err
+	}
+	for _, thread := range t.ThreadList() {
+		if thread.Breakpoint().Breakpoint == bp {
+			thread.Breakpoint().Clear()
+		}
+	}
+	delete(t.Breakpoints().M, addr)
+	return nil
+}
+
+// holdFrozenGoroutines keeps stopped every thread that was trapped by the
+// breakpoint of a frozen goroutine.
+func (t *Target) holdFrozenGoroutines(threads []Thread) error {
+	if len(t.frozenGoroutines) == 0 {
+		return nil
+	}
+	for _, th := range threads {
+		bp := th.Breakpoint().Breakpoint
+		if bp == nil || bp.Kind&FreezeBreakpoint == 0 || bp.freezeCond == nil {
+			continue
+		}
+		if frozen, err := evalBreakpointCondition(th, bp.freezeCond); !frozen || err != nil {
+			continue
+		}
+		g, _ := GetG(th)
+		if g == nil {
+			continue
+		}
+		fg := t.frozenGoroutines[g.ID]
+		if fg == nil || fg.thread != nil {
+			continue
+		}
+		if err := t.proc.(threadHolder).HoldThread(th, true); err != nil {
+			return err
+		}
+		fg.thread = th
+		if !t.updateFreezeCondition(fg.bpaddr) {
+			if err := t.clearFreezeBreakpoint(fg.bpaddr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkNotFrozen returns an error if g is frozen, commands that wait for
+// g to execute would never terminate otherwise.
+func (t *Target) checkNotFrozen(g *G) error {
+	if g != nil && t.frozenGoroutines[g.ID] != nil {
+		return fmt.Errorf("goroutine %d is frozen", g.ID)
+	}
+	return nil
+}
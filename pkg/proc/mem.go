@@ -1,6 +1,8 @@
 package proc
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 
@@ -110,6 +112,14 @@ func newCompositeMemory(mem MemoryReadWriter, regs op.DwarfRegisters, pieces []o
 				return nil, fmt.Errorf("could not read %d bytes from register %d (size: %d)", sz, piece.RegNum, len(reg))
 			}
 			cmem.data = append(cmem.data, reg[:sz]...)
+		} else if piece.IsValue {
+			var buf bytes.Buffer
+			binary.Write(&buf, regs.ByteOrder, piece.Value)
+			sz := piece.Size
+			if sz == 0 || sz > buf.Len() {
+				sz = buf.Len()
+			}
+			cmem.data = append(cmem.data, buf.Bytes()[:sz]...)
 		} else {
 			buf := make([]byte, piece.Size)
 			mem.ReadMemory(buf, uint64(piece.Addr))
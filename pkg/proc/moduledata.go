@@ -10,6 +10,7 @@ type moduleData struct {
 	text, etext   uint64
 	types, etypes uint64
 	typemapVar    *Variable
+	image         *Image // image containing the text section of the module, nil if it isn't known
 }
 
 func loadModuleData(bi *BinaryInfo, mem MemoryReadWriter) ([]moduleData, error) {
@@ -61,6 +62,7 @@ func loadModuleData(bi *BinaryInfo, mem MemoryReadWriter) ([]moduleData, error)
 		if err != nil {
 			return nil, err
 		}
+		r[len(r)-1].image = bi.textToImage(r[len(r)-1].text)
 
 		md = vars[nextField].maybeDereference()
 		if md.Unreadable != nil {
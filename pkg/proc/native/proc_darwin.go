@@ -37,7 +37,7 @@ type osProcessDetails struct {
 // custom fork/exec process in order to take advantage of
 // PT_SIGEXC on Darwin which will turn Unix signals into
 // Mach exceptions.
-func Launch(cmd []string, wd string, foreground bool, _ []string, _ string, _ [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, foreground bool, _ []string, bicache proc.BinaryInfoCacheConfig, _ string, _ [3]string) (*proc.Target, error) {
 	argv0Go, err := filepath.Abs(cmd[0])
 	if err != nil {
 		return nil, err
@@ -120,7 +120,7 @@ func Launch(cmd []string, wd string, foreground bool, _ []string, _ string, _ [3
 	dbp.os.initialized = true
 	dbp.currentThread = trapthread
 
-	tgt, err := dbp.initialize(argv0Go, []string{})
+	tgt, err := dbp.initialize(argv0Go, []string{}, bicache)
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +129,7 @@ func Launch(cmd []string, wd string, foreground bool, _ []string, _ string, _ [3
 }
 
 // Attach to an existing process with the given PID.
-func Attach(pid int, _ []string) (*proc.Target, error) {
+func Attach(pid int, _ []string, bicache proc.BinaryInfoCacheConfig) (*proc.Target, error) {
 	dbp := newProcess(pid)
 
 	kret := C.acquire_mach_task(C.int(pid),
@@ -152,7 +152,7 @@ func Attach(pid int, _ []string) (*proc.Target, error) {
 		return nil, err
 	}
 
-	tgt, err := dbp.initialize("", []string{})
+	tgt, err := dbp.initialize("", []string{}, bicache)
 	if err != nil {
 		dbp.Detach(false)
 		return nil, err
@@ -42,7 +42,7 @@ type osProcessDetails struct {
 // to be supplied to that process. `wd` is working directory of the program.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tty string, redirects [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, bicache proc.BinaryInfoCacheConfig, tty string, redirects [3]string) (*proc.Target, error) {
 	var (
 		process *exec.Cmd
 		err     error
@@ -97,7 +97,7 @@ func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tt
 	if err != nil {
 		return nil, fmt.Errorf("waiting for target execve failed: %s", err)
 	}
-	tgt, err := dbp.initialize(cmd[0], debugInfoDirs)
+	tgt, err := dbp.initialize(cmd[0], debugInfoDirs, bicache)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +107,7 @@ func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tt
 // Attach to an existing process with the given PID. Once attached, if
 // the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Attach(pid int, debugInfoDirs []string) (*proc.Target, error) {
+func Attach(pid int, debugInfoDirs []string, bicache proc.BinaryInfoCacheConfig) (*proc.Target, error) {
 	dbp := newProcess(pid)
 
 	var err error
@@ -120,7 +120,7 @@ func Attach(pid int, debugInfoDirs []string) (*proc.Target, error) {
 		return nil, err
 	}
 
-	tgt, err := dbp.initialize(findExecutable("", dbp.pid), debugInfoDirs)
+	tgt, err := dbp.initialize(findExecutable("", dbp.pid), debugInfoDirs, bicache)
 	if err != nil {
 		dbp.Detach(false)
 		return nil, err
@@ -49,7 +49,7 @@ type osProcessDetails struct {
 // to be supplied to that process. `wd` is working directory of the program.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tty string, redirects [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, bicache proc.BinaryInfoCacheConfig, tty string, redirects [3]string) (*proc.Target, error) {
 	var (
 		process *exec.Cmd
 		err     error
@@ -107,7 +107,7 @@ func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tt
 	if err != nil {
 		return nil, fmt.Errorf("waiting for target execve failed: %s", err)
 	}
-	tgt, err := dbp.initialize(cmd[0], debugInfoDirs)
+	tgt, err := dbp.initialize(cmd[0], debugInfoDirs, bicache)
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +117,7 @@ func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tt
 // Attach to an existing process with the given PID. Once attached, if
 // the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Attach(pid int, debugInfoDirs []string) (*proc.Target, error) {
+func Attach(pid int, debugInfoDirs []string, bicache proc.BinaryInfoCacheConfig) (*proc.Target, error) {
 	dbp := newProcess(pid)
 
 	var err error
@@ -130,7 +130,7 @@ func Attach(pid int, debugInfoDirs []string) (*proc.Target, error) {
 		return nil, err
 	}
 
-	tgt, err := dbp.initialize(findExecutable("", dbp.pid), debugInfoDirs)
+	tgt, err := dbp.initialize(findExecutable("", dbp.pid), debugInfoDirs, bicache)
 	if err != nil {
 		_ = dbp.Detach(false)
 		return nil, err
@@ -455,15 +455,18 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 func (dbp *nativeProcess) resume() error {
 	// all threads stopped over a breakpoint are made to step over it
 	for _, thread := range dbp.threads {
-		if thread.CurrentBreakpoint.Breakpoint != nil {
+		if thread.CurrentBreakpoint.Breakpoint != nil && !thread.os.held {
 			if err := thread.StepInstruction(); err != nil {
 				return err
 			}
 			thread.CurrentBreakpoint.Clear()
 		}
 	}
-	// everything is resumed
+	// everything is resumed, except threads held by HoldThread
 	for _, thread := range dbp.threads {
+		if thread.os.held {
+			continue
+		}
 		if err := thread.resume(); err != nil && err != sys.ESRCH {
 			return err
 		}
@@ -471,6 +474,32 @@ func (dbp *nativeProcess) resume() error {
 	return nil
 }
 
+// HoldThread keeps th stopped when the process is resumed, until it is
+// released by calling HoldThread(th, false).
+func (dbp *nativeProcess) HoldThread(th proc.Thread, hold bool) error {
+	thread, ok := dbp.threads[th.ThreadID()]
+	if !ok {
+		return fmt.Errorf("thread %d does not exist", th.ThreadID())
+	}
+	thread.os.held = hold
+	return nil
+}
+
+// Nanotime returns the current value of the clock used by runtime.nanotime,
+// which on linux is CLOCK_MONOTONIC.
+func (dbp *nativeProcess) Nanotime() (int64, error) {
+	var ts sys.Timespec
+	if err := sys.ClockGettime(sys.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0, err
+	}
+	return ts.Nano(), nil
+}
+
+// ReadProcFile returns the contents of /proc/<pid>/name.
+func (dbp *nativeProcess) ReadProcFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(fmt.Sprintf("/proc/%d/%s", dbp.pid, name))
+}
+
 // stop stops all running threads and sets breakpoints
 func (dbp *nativeProcess) stop(trapthread *nativeThread) (err error) {
 	if dbp.exited {
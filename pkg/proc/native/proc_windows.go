@@ -21,7 +21,7 @@ type osProcessDetails struct {
 }
 
 // Launch creates and begins debugging a new process.
-func Launch(cmd []string, wd string, foreground bool, _ []string, _ string, redirects [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, foreground bool, _ []string, bicache proc.BinaryInfoCacheConfig, _ string, redirects [3]string) (*proc.Target, error) {
 	argv0Go, err := filepath.Abs(cmd[0])
 	if err != nil {
 		return nil, err
@@ -56,7 +56,7 @@ func Launch(cmd []string, wd string, foreground bool, _ []string, _ string, redi
 	dbp.pid = p.Pid
 	dbp.childProcess = true
 
-	tgt, err := dbp.initialize(argv0Go, []string{})
+	tgt, err := dbp.initialize(argv0Go, []string{}, bicache)
 	if err != nil {
 		dbp.Detach(true)
 		return nil, err
@@ -132,7 +132,7 @@ func findExePath(pid int) (string, error) {
 }
 
 // Attach to an existing process with the given PID.
-func Attach(pid int, _ []string) (*proc.Target, error) {
+func Attach(pid int, _ []string, bicache proc.BinaryInfoCacheConfig) (*proc.Target, error) {
 	dbp := newProcess(pid)
 	var err error
 	dbp.execPtraceFunc(func() {
@@ -146,7 +146,7 @@ func Attach(pid int, _ []string) (*proc.Target, error) {
 	if err != nil {
 		return nil, err
 	}
-	tgt, err := dbp.initialize(exepath, []string{})
+	tgt, err := dbp.initialize(exepath, []string{}, bicache)
 	if err != nil {
 		dbp.Detach(true)
 		return nil, err
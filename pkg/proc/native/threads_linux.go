@@ -17,6 +17,7 @@ type osSpecificDetails struct {
 	registers     sys.PtraceRegs
 	running       bool
 	setbp         bool
+	held          bool // thread is kept stopped by HoldThread
 }
 
 func (t *nativeThread) stop() (err error) {
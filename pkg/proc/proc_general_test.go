@@ -1,11 +1,20 @@
 package proc
 
 import (
+	"bytes"
+	"debug/dwarf"
+	"fmt"
+	"go/parser"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"testing"
+	"time"
 	"unsafe"
 
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 )
 
@@ -118,3 +127,271 @@ func TestDwarfVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestBinaryInfoCache(t *testing.T) {
+	// Tests that the debug info loaded from the on-disk cache is the same
+	// as the one loaded from the executable file.
+	dir, err := ioutil.TempDir("", "dlv-bininfo-cache")
+	assertNoError(err, t, "TempDir")
+	defer os.RemoveAll(dir)
+	fixture := protest.BuildFixture("testinline", protest.EnableInlining)
+
+	bi1 := NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+	bi1.cacheConfig = BinaryInfoCacheConfig{Dir: dir}
+	assertNoError(bi1.LoadBinaryInfo(fixture.Path, 0, nil), t, "LoadBinaryInfo (uncached)")
+	fis, err := ioutil.ReadDir(dir)
+	assertNoError(err, t, "ReadDir")
+	if len(fis) != 1 {
+		t.Fatalf("expected one cache file, got %d", len(fis))
+	}
+
+	bi2 := NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+	bi2.cacheConfig = BinaryInfoCacheConfig{Dir: dir}
+	assertNoError(bi2.LoadBinaryInfo(fixture.Path, 0, nil), t, "LoadBinaryInfo (cached)")
+	if _, err := os.Stat(filepath.Join(dir, fis[0].Name())); err != nil {
+		t.Fatalf("cache file was discarded: %v", err)
+	}
+
+	if len(bi1.Functions) != len(bi2.Functions) {
+		t.Fatalf("function count mismatch %d %d", len(bi1.Functions), len(bi2.Functions))
+	}
+	for i := range bi1.Functions {
+		fn1, fn2 := &bi1.Functions[i], &bi2.Functions[i]
+		if fn1.Name != fn2.Name || fn1.Entry != fn2.Entry || fn1.End != fn2.End || fn1.offset != fn2.offset || fn1.cu.offset != fn2.cu.offset || len(fn1.InlinedCalls) != len(fn2.InlinedCalls) {
+			t.Errorf("function mismatch %#v %#v", fn1, fn2)
+		}
+	}
+	if len(bi1.Images[0].compileUnits) != len(bi2.Images[0].compileUnits) {
+		t.Fatalf("compile unit count mismatch %d %d", len(bi1.Images[0].compileUnits), len(bi2.Images[0].compileUnits))
+	}
+	for i, cu1 := range bi1.Images[0].compileUnits {
+		cu2 := bi2.Images[0].compileUnits[i]
+		if (cu1.lineTable == nil) != (cu2.lineTable == nil) {
+			t.Errorf("line table mismatch for %s", cu1.name)
+			continue
+		}
+		if cu1.lineTable != nil && (!reflect.DeepEqual(cu1.lineInfo().FileNames, cu2.lineInfo().FileNames) || !bytes.Equal(cu1.lineInfo().Instructions, cu2.lineInfo().Instructions)) {
+			t.Errorf("line table mismatch for %s", cu1.name)
+		}
+	}
+	if !reflect.DeepEqual(bi1.PackageMap, bi2.PackageMap) {
+		t.Errorf("package map mismatch")
+	}
+	if !reflect.DeepEqual(bi1.Sources, bi2.Sources) {
+		t.Errorf("sources mismatch")
+	}
+	if !reflect.DeepEqual(bi1.types, bi2.types) {
+		t.Errorf("types mismatch")
+	}
+	if !reflect.DeepEqual(bi1.inlinedCallLines, bi2.inlinedCallLines) {
+		t.Errorf("inlined call lines mismatch")
+	}
+	if !reflect.DeepEqual(bi1.Images[0].runtimeTypeToDIE, bi2.Images[0].runtimeTypeToDIE) {
+		t.Errorf("runtime types mismatch")
+	}
+	if len(bi1.packageVars) != len(bi2.packageVars) {
+		t.Errorf("package variables count mismatch %d %d", len(bi1.packageVars), len(bi2.packageVars))
+	}
+}
+
+func TestInlinedCallEntryPC(t *testing.T) {
+	const staticBase = 0x10000
+	ranges := [][2]uint64{{staticBase + 0x100, staticBase + 0x110}, {staticBase + 0x200, staticBase + 0x220}}
+	for _, tc := range []struct {
+		fields []dwarf.Field
+		tgt    uint64
+	}{
+		{[]dwarf.Field{{Attr: dwarf.AttrEntrypc, Val: uint64(0x200), Class: dwarf.ClassAddress}}, staticBase + 0x200},
+		{[]dwarf.Field{{Attr: dwarf.AttrLowpc, Val: uint64(0x100), Class: dwarf.ClassAddress}, {Attr: dwarf.AttrEntrypc, Val: int64(0x108), Class: dwarf.ClassConstant}}, staticBase + 0x208},
+		{[]dwarf.Field{{Attr: dwarf.AttrLowpc, Val: uint64(0x104), Class: dwarf.ClassAddress}}, staticBase + 0x104},
+		{nil, staticBase + 0x100},
+	} {
+		e := &godwarf.Tree{Entry: &dwarf.Entry{Tag: dwarf.TagInlinedSubroutine, Field: tc.fields}, Tag: dwarf.TagInlinedSubroutine, Ranges: ranges}
+		if pc := inlinedCallEntryPC(e, staticBase); pc != tc.tgt {
+			t.Errorf("%v: got %#x expected %#x", tc.fields, pc, tc.tgt)
+		}
+	}
+}
+
+func TestTrimBinaryInfoCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlv-bininfo-cache")
+	assertNoError(err, t, "TempDir")
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	for i, name := range []string{"old", "new", "keep"} {
+		path := filepath.Join(dir, name)
+		assertNoError(ioutil.WriteFile(path, make([]byte, 100), 0600), t, "WriteFile")
+		mtime := now.Add(time.Duration(i) * time.Minute)
+		if name == "keep" {
+			// the file just written must survive even if it isn't the most
+			// recently used one.
+			mtime = now.Add(-time.Hour)
+		}
+		assertNoError(os.Chtimes(path, mtime, mtime), t, "Chtimes")
+	}
+
+	trimBinaryInfoCache(dir, 250, "keep")
+
+	for _, tc := range []struct {
+		name   string
+		exists bool
+	}{{"old", false}, {"new", true}, {"keep", true}} {
+		if _, err := os.Stat(filepath.Join(dir, tc.name)); (err == nil) != tc.exists {
+			t.Errorf("%s: exists=%v, expected %v", tc.name, err == nil, tc.exists)
+		}
+	}
+
+	trimBinaryInfoCache(dir, 50, "keep")
+	if _, err := os.Stat(filepath.Join(dir, "keep")); err != nil {
+		t.Errorf("cache file larger than the maximum size was deleted: %v", err)
+	}
+}
+
+func loadBinaryInfoWithWorkers(t testing.TB, path string, workers int) *BinaryInfo {
+	saved := loadDebugInfoMapsWorkers
+	loadDebugInfoMapsWorkers = workers
+	defer func() {
+		loadDebugInfoMapsWorkers = saved
+	}()
+	bi := NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+	assertNoError(bi.LoadBinaryInfo(path, 0, nil), t, "LoadBinaryInfo")
+	return bi
+}
+
+func TestParallelDebugInfoLoading(t *testing.T) {
+	// Tests that loading compile units in parallel produces the same
+	// functions, types and variables as loading them one at a time.
+	fixture := protest.BuildFixture("pkgrenames", protest.EnableInlining)
+	bi1 := loadBinaryInfoWithWorkers(t, fixture.Path, 1)
+	bi2 := loadBinaryInfoWithWorkers(t, fixture.Path, 8)
+
+	if len(bi1.Functions) != len(bi2.Functions) {
+		t.Fatalf("function count mismatch %d %d", len(bi1.Functions), len(bi2.Functions))
+	}
+	for i := range bi1.Functions {
+		fn1, fn2 := &bi1.Functions[i], &bi2.Functions[i]
+		if fn1.Name != fn2.Name || fn1.Entry != fn2.Entry || fn1.End != fn2.End || fn1.offset != fn2.offset || len(fn1.InlinedCalls) != len(fn2.InlinedCalls) {
+			t.Errorf("function mismatch %#v %#v", fn1, fn2)
+		}
+	}
+	if !reflect.DeepEqual(bi1.types, bi2.types) {
+		t.Errorf("types mismatch")
+	}
+	if !reflect.DeepEqual(bi1.PackageMap, bi2.PackageMap) {
+		t.Errorf("package map mismatch")
+	}
+	if !reflect.DeepEqual(bi1.Sources, bi2.Sources) {
+		t.Errorf("sources mismatch")
+	}
+	if len(bi1.packageVars) != len(bi2.packageVars) {
+		t.Errorf("package variables count mismatch %d %d", len(bi1.packageVars), len(bi2.packageVars))
+	}
+	if len(bi1.inlinedCallLines) != len(bi2.inlinedCallLines) {
+		t.Errorf("inlined call lines count mismatch %d %d", len(bi1.inlinedCallLines), len(bi2.inlinedCallLines))
+	}
+}
+
+func BenchmarkLoadBinaryInfo(b *testing.B) {
+	// http_server imports net/http, which makes it a large executable.
+	path := protest.BuildFixture("http_server", 0).Path
+	b.ResetTimer()
+	for _, workers := range []int{1, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bi := loadBinaryInfoWithWorkers(b, path, workers)
+				bi.Close()
+			}
+		})
+	}
+}
+
+func TestPluginImage(t *testing.T) {
+	// Tests that the debug information of a plugin is loaded into its own
+	// image without replacing the runtime and main package of the
+	// executable and that the variables and types of the plugin package can
+	// be evaluated.
+	pluginFixtures := protest.WithPlugins(t, protest.AllNonOptimized, "plugin1/", "plugin2/")
+	fixture := protest.BuildFixture("plugintest", protest.AllNonOptimized)
+
+	const plugin2Addr = 0x7f1000000000
+
+	bi := NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+	assertNoError(bi.LoadBinaryInfo(fixture.Path, 0, nil), t, "LoadBinaryInfo")
+	assertNoError(bi.AddImage(pluginFixtures[0].Path, 0x7f0000000000), t, "AddImage")
+	assertNoError(bi.AddImage(pluginFixtures[1].Path, plugin2Addr), t, "AddImage")
+	if len(bi.Images) != 3 {
+		t.Fatalf("wrong number of images %d", len(bi.Images))
+	}
+	plugin := bi.Images[1]
+	assertNoError(plugin.LoadError(), t, "plugin load error")
+	assertNoError(bi.Images[2].LoadError(), t, "plugin load error")
+
+	if bi.Images[0].PluginPath != "" {
+		t.Errorf("executable has plugin path %q", bi.Images[0].PluginPath)
+	}
+	if plugin.PluginPath == "" {
+		t.Fatalf("plugin has no plugin path")
+	}
+
+	if fn := bi.LookupFunc["runtime.Breakpoint"]; fn == nil || fn.imageIndex() != 0 {
+		t.Errorf("runtime.Breakpoint not resolved to the executable: %#v", fn)
+	}
+	if fn := bi.LookupFunc["main.main"]; fn == nil || fn.imageIndex() != 0 {
+		t.Errorf("main.main not resolved to the executable: %#v", fn)
+	}
+	if fn := bi.LookupFunc[plugin.PluginPath+".Fn1"]; fn == nil || fn.imageIndex() != 1 {
+		t.Errorf("%s.Fn1 not resolved to the plugin: %#v", plugin.PluginPath, fn)
+	}
+	for _, path := range bi.PackageMap["main"] {
+		if path != "main" {
+			t.Errorf("plugin package %q registered as main", path)
+		}
+	}
+
+	scope := globalScope(bi, bi.Images[0], nil)
+
+	v, err := scope.findGlobal("plugin2", "A")
+	assertNoError(err, t, "findGlobal(plugin2.A)")
+	if v.Addr < plugin2Addr || v.DwarfType.String() != "interface {}" {
+		t.Errorf("plugin2.A not resolved to the plugin: %#x %s", v.Addr, v.DwarfType)
+	}
+
+	expr, err := parser.ParseExpr("plugin2.asomethingelse")
+	assertNoError(err, t, "ParseExpr")
+	typ, err := bi.findTypeExpr(expr)
+	assertNoError(err, t, "findTypeExpr(plugin2.asomethingelse)")
+	if styp, ok := typ.(*godwarf.StructType); !ok || len(styp.Field) != 2 || styp.Common().Index != 2 {
+		t.Errorf("plugin2.asomethingelse not resolved to the plugin: %#v", typ)
+	}
+}
+
+func TestFuncTypeMatches(t *testing.T) {
+	intType := &godwarf.IntType{BasicType: godwarf.BasicType{CommonType: godwarf.CommonType{Name: "int", ByteSize: 8}}}
+	stringType := &godwarf.StringType{StructType: godwarf.StructType{CommonType: godwarf.CommonType{Name: "string", ByteSize: 16}}}
+	intSlice := &godwarf.SliceType{StructType: godwarf.StructType{CommonType: godwarf.CommonType{Name: "[]int", ByteSize: 24}}, ElemType: intType}
+	dotdotdot := &godwarf.DotDotDotType{}
+
+	arg := func(typ godwarf.Type) funcCallArg { return funcCallArg{typ: typ} }
+	ret := func(typ godwarf.Type) funcCallArg { return funcCallArg{typ: typ, isret: true} }
+
+	for i, tc := range []struct {
+		params          []godwarf.Type
+		args            []funcCallArg
+		match, variadic bool
+	}{
+		{nil, nil, true, false},
+		{[]godwarf.Type{intSlice, intType}, []funcCallArg{arg(intSlice), ret(intType)}, true, false},
+		{[]godwarf.Type{intSlice, dotdotdot, intType}, []funcCallArg{arg(intSlice), ret(intType)}, true, true},
+		{[]godwarf.Type{stringType, intSlice, dotdotdot}, []funcCallArg{arg(stringType), arg(intSlice)}, true, true},
+		{[]godwarf.Type{intSlice, dotdotdot}, []funcCallArg{arg(intSlice), ret(intType)}, false, false},
+		{[]godwarf.Type{intType, dotdotdot, intType}, []funcCallArg{arg(intType), ret(intType)}, false, false},
+		{[]godwarf.Type{intSlice, intType}, []funcCallArg{arg(intSlice), arg(intType)}, true, false},
+		{[]godwarf.Type{intSlice, stringType}, []funcCallArg{arg(intSlice), ret(intType)}, false, false},
+	} {
+		match, variadic := funcTypeMatches(&godwarf.FuncType{ParamType: tc.params}, tc.args)
+		if match != tc.match || variadic != tc.variadic {
+			t.Errorf("%d: got match=%v variadic=%v, expected match=%v variadic=%v", i, match, variadic, tc.match, tc.variadic)
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/native"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 )
@@ -14,13 +16,56 @@ func TestLoadingExternalDebugInfo(t *testing.T) {
 	fixture := protest.BuildFixture("locationsprog", 0)
 	defer os.Remove(fixture.Path)
 	stripAndCopyDebugInfo(fixture, t)
-	p, err := native.Launch(append([]string{fixture.Path}, ""), "", false, []string{filepath.Dir(fixture.Path)}, "", [3]string{})
+	p, err := native.Launch(append([]string{fixture.Path}, ""), "", false, []string{filepath.Dir(fixture.Path)}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
 	if err != nil {
 		t.Fatal(err)
 	}
 	p.Detach(true)
 }
 
+func TestLoadingDebugLink(t *testing.T) {
+	fixture := protest.BuildFixture("locationsprog", 0)
+	defer os.Remove(fixture.Path)
+	dir := filepath.Dir(fixture.Path)
+	name := filepath.Base(fixture.Path)
+	debugFile := filepath.Join(dir, ".debug", name+".dbg")
+	assertNoError(os.MkdirAll(filepath.Dir(debugFile), 0755), t, "MkdirAll")
+	defer os.Remove(debugFile)
+
+	for _, args := range [][]string{
+		{"objcopy", "--only-keep-debug", name, debugFile},
+		{"strip", "--strip-debug", "--strip-unneeded", "--remove-section=.note.gnu.build-id", name},
+		{"objcopy", "--add-gnu-debuglink=" + debugFile, name},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, out)
+		}
+	}
+
+	p, err := native.Launch([]string{fixture.Path}, "", false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Detach(true)
+
+	// Modifying the debug file invalidates its CRC.
+	fh, err := os.OpenFile(debugFile, os.O_APPEND|os.O_WRONLY, 0)
+	assertNoError(err, t, "OpenFile")
+	fh.Write([]byte{0})
+	fh.Close()
+
+	p, err = native.Launch([]string{fixture.Path}, "", false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
+	if err == nil {
+		p.Detach(true)
+		t.Fatal("expected error loading debug file with wrong CRC")
+	}
+	if !strings.Contains(err.Error(), "CRC mismatch") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func stripAndCopyDebugInfo(f protest.Fixture, t *testing.T) {
 	name := filepath.Base(f.Path)
 	// Copy the debug information to an external file.
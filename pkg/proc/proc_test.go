@@ -97,13 +97,13 @@ func withTestProcessArgs(name string, t testing.TB, wd string, args []string, bu
 
 	switch testBackend {
 	case "native":
-		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, false, []string{}, "", [3]string{})
+		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, false, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
 	case "rr":
 		protest.MustHaveRecordingAllowed(t)
 		t.Log("recording")
-		p, tracedir, err = gdbserial.RecordAndReplay(append([]string{fixture.Path}, args...), wd, true, []string{}, [3]string{})
+		p, tracedir, err = gdbserial.RecordAndReplay(append([]string{fixture.Path}, args...), wd, true, []string{}, proc.BinaryInfoCacheConfig{}, [3]string{})
 		t.Logf("replaying %q", tracedir)
 	default:
 		t.Fatal("unknown backend")
@@ -2108,9 +2108,9 @@ func TestUnsupportedArch(t *testing.T) {
 
 	switch testBackend {
 	case "native":
-		p, err = native.Launch([]string{outfile}, ".", false, []string{}, "", [3]string{})
+		p, err = native.Launch([]string{outfile}, ".", false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch([]string{outfile}, ".", false, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch([]string{outfile}, ".", false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
 	default:
 		t.Skip("test not valid for this backend")
 	}
@@ -2344,6 +2344,156 @@ func TestGoroutineLables(t *testing.T) {
 	})
 }
 
+func TestGoroutineProfile(t *testing.T) {
+	withTestProcess("goroutineLabels", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		assertNoError(p.Continue(), t, "Continue()")
+		prof, err := proc.GoroutineProfile(p)
+		assertNoError(err, t, "GoroutineProfile()")
+		found := false
+		for _, s := range prof.Sample {
+			if len(s.Label["k1"]) != 1 || s.Label["k1"][0] != "v1" {
+				continue
+			}
+			for _, loc := range s.Location {
+				for _, line := range loc.Line {
+					if line.Function.Name == "main.f" {
+						found = true
+					}
+				}
+			}
+		}
+		if !found {
+			t.Fatalf("no sample for main.f labeled k1=v1")
+		}
+	})
+}
+
+func TestFreezeGoroutine(t *testing.T) {
+	withTestProcess("parallel_next", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.sayhi")
+		assertNoError(p.Continue(), t, "Continue()")
+		frozen := p.SelectedGoroutine().ID
+		err := p.FreezeGoroutine(frozen)
+		if err == proc.ErrFreezeUnsupported {
+			t.Skip("backend does not support freezing goroutines")
+		}
+		assertNoError(err, t, "FreezeGoroutine()")
+		if err := p.Next(); err == nil {
+			t.Fatal("next on a frozen goroutine succeeded")
+		}
+
+		setFileBreakpoint(p, t, fixture.Source, 10)
+		for hits := 0; hits < 9; {
+			assertNoError(p.Continue(), t, "Continue()")
+			if gid := p.SelectedGoroutine().ID; gid == frozen {
+				t.Fatalf("frozen goroutine %d stopped at a breakpoint", gid)
+			}
+			if _, ln := currentLineNumber(p, t); ln == 10 {
+				hits++
+			}
+		}
+
+		assertNoError(p.ThawGoroutine(frozen), t, "ThawGoroutine()")
+		if len(p.FrozenGoroutines()) != 0 {
+			t.Fatalf("goroutines still frozen: %v", p.FrozenGoroutines())
+		}
+		assertNoError(p.Continue(), t, "Continue()")
+		assertLineNumber(p, t, 10, "after thaw")
+		if gid := p.SelectedGoroutine().ID; gid != frozen {
+			t.Fatalf("expected goroutine %d after thaw, got %d", frozen, gid)
+		}
+	})
+}
+
+func TestGoroutineBlockedOn(t *testing.T) {
+	withTestProcess("goroutinestackprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.stacktraceme")
+		assertNoError(p.Continue(), t, "Continue()")
+		gs, _, err := proc.GoroutinesInfo(p, 0, 0)
+		assertNoError(err, t, "GoroutinesInfo")
+		var chanAddr uint64
+		count := 0
+		for _, g := range gs {
+			if loc := g.StartLoc(); loc.Fn == nil || loc.Fn.Name != "main.agoroutine" {
+				continue
+			}
+			count++
+			if wr := g.WaitReasonString(); wr != "chan send" {
+				t.Errorf("goroutine %d: wrong wait reason %q", g.ID, wr)
+			}
+			obj := g.BlockedOn()
+			if obj == nil || obj.Kind != "chan" || obj.Addr == 0 {
+				t.Fatalf("goroutine %d: wrong wait object %#v", g.ID, obj)
+			}
+			if chanAddr == 0 {
+				chanAddr = obj.Addr
+			} else if obj.Addr != chanAddr {
+				t.Errorf("goroutine %d: blocked on %#x, expected %#x", g.ID, obj.Addr, chanAddr)
+			}
+		}
+		if count != 10 {
+			t.Fatalf("expected 10 goroutines running main.agoroutine, found %d", count)
+		}
+	})
+}
+
+func TestGoroutineBlockedOnMutex(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("mutexblock", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		mu := evalVariable(p, t, "mu")
+		gs, _, err := proc.GoroutinesInfo(p, 0, 0)
+		assertNoError(err, t, "GoroutinesInfo")
+		count := 0
+		for _, g := range gs {
+			if loc := g.StartLoc(); loc.Fn == nil || loc.Fn.Name != "main.main.func1" {
+				continue
+			}
+			count++
+			wr := g.WaitReasonString()
+			if goversion.VersionAfterOrEqual(runtime.Version(), 1, 20) && wr != "sync.Mutex.Lock" {
+				t.Errorf("goroutine %d: wrong wait reason %q", g.ID, wr)
+			}
+			obj := g.BlockedOn()
+			if obj == nil || obj.Kind != "sema" {
+				t.Fatalf("goroutine %d: wrong wait object %#v (wait reason %q)", g.ID, obj, wr)
+			}
+			if obj.Addr < mu.Addr || obj.Addr >= mu.Addr+uint64(mu.RealType.Size()) {
+				t.Errorf("goroutine %d: blocked on %#x, expected an address inside mu (%#x)", g.ID, obj.Addr, mu.Addr)
+			}
+		}
+		if count != 3 {
+			t.Fatalf("expected 3 goroutines blocked on mu, found %d", count)
+		}
+	})
+}
+
+func TestCPUProfile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("manual stop is unreliable on windows")
+	}
+	withTestProcess("binarytrees", t, func(p *proc.Target, fixture protest.Fixture) {
+		prof, stats, err := proc.CPUProfile(p, proc.CPUProfileConfig{Duration: time.Second, Rate: 50})
+		assertNoError(err, t, "CPUProfile()")
+		t.Logf("samples %d stops %d elapsed %v stopped %v", stats.Samples, stats.Stops, stats.Elapsed, stats.Stopped)
+		if stats.Stops == 0 || len(prof.Sample) != stats.Samples {
+			t.Fatalf("wrong profile: %d samples in profile, stats %#v", len(prof.Sample), stats)
+		}
+		found := false
+		for _, loc := range prof.Location {
+			for _, line := range loc.Line {
+				if line.Function.Name == "main.bottomUpTree" {
+					found = true
+				}
+			}
+		}
+		if stats.Samples > 0 && !found {
+			t.Errorf("main.bottomUpTree not found in profile")
+		}
+	})
+}
+
 func TestStepOut(t *testing.T) {
 	testseq2(t, "testnextprog", "main.helloworld", []seqTest{{contContinue, 13}, {contStepout, 35}})
 }
@@ -2858,13 +3008,13 @@ func TestAttachDetach(t *testing.T) {
 
 	switch testBackend {
 	case "native":
-		p, err = native.Attach(cmd.Process.Pid, []string{})
+		p, err = native.Attach(cmd.Process.Pid, []string{}, proc.BinaryInfoCacheConfig{})
 	case "lldb":
 		path := ""
 		if runtime.GOOS == "darwin" {
 			path = fixture.Path
 		}
-		p, err = gdbserial.LLDBAttach(cmd.Process.Pid, path, []string{})
+		p, err = gdbserial.LLDBAttach(cmd.Process.Pid, path, []string{}, proc.BinaryInfoCacheConfig{})
 	default:
 		err = fmt.Errorf("unknown backend %q", testBackend)
 	}
@@ -3159,13 +3309,13 @@ func TestAttachStripped(t *testing.T) {
 
 	switch testBackend {
 	case "native":
-		p, err = native.Attach(cmd.Process.Pid, []string{})
+		p, err = native.Attach(cmd.Process.Pid, []string{}, proc.BinaryInfoCacheConfig{})
 	case "lldb":
 		path := ""
 		if runtime.GOOS == "darwin" {
 			path = fixture.Path
 		}
-		p, err = gdbserial.LLDBAttach(cmd.Process.Pid, path, []string{})
+		p, err = gdbserial.LLDBAttach(cmd.Process.Pid, path, []string{}, proc.BinaryInfoCacheConfig{})
 	default:
 		t.Fatalf("unknown backend %q", testBackend)
 	}
@@ -3889,6 +4039,60 @@ func TestInlineStepOut(t *testing.T) {
 	})
 }
 
+func TestInlineNestedStep(t *testing.T) {
+	// Stepping through nested inlined calls should enter and leave each
+	// inlined call as if it was a real function call.
+	if ver, _ := goversion.Parse(runtime.Version()); ver.Major >= 0 && !ver.AfterOrEqual(goversion.GoVersion{Major: 1, Minor: 10, Rev: -1}) {
+		// Versions of go before 1.10 do not have DWARF information for inlined calls
+		t.Skip("inlining not supported")
+	}
+	testseq2Args(".", []string{}, protest.EnableInlining, t, "testinlinenested", "", []seqTest{
+		{contContinue, 19},
+		{contStep, 13},
+		{contNext, 14},
+		{contStep, 8},
+		{contStepout, 14},
+		{contNext, 15},
+		{contNext, 19},
+		{contNext, 20},
+		{contStep, 13},
+		{contStepout, 20},
+	})
+}
+
+func TestInlineFunctionBreakpoint(t *testing.T) {
+	// A breakpoint on an inlined function should be set on every inlined
+	// instance of the function and stop in a stack frame for the inlined
+	// call.
+	if ver, _ := goversion.Parse(runtime.Version()); ver.Major >= 0 && !ver.AfterOrEqual(goversion.GoVersion{Major: 1, Minor: 10, Rev: -1}) {
+		// Versions of go before 1.10 do not have DWARF information for inlined calls
+		t.Skip("inlining not supported")
+	}
+	protest.AllowRecording(t)
+	withTestProcessArgs("testinlinenested", t, ".", []string{}, protest.EnableInlining, func(p *proc.Target, fixture protest.Fixture) {
+		pcs, err := proc.FindFunctionLocation(p, "main.outer", 0)
+		assertNoError(err, t, "FindFunctionLocation")
+		if len(pcs) < 2 {
+			t.Fatalf("expected a breakpoint for each inlined call of main.outer, got %#v", pcs)
+		}
+		for _, pc := range pcs {
+			_, err := p.SetBreakpoint(pc, proc.UserBreakpoint, nil)
+			assertNoError(err, t, fmt.Sprintf("SetBreakpoint(%#x)", pc))
+		}
+		for _, callLine := range []int{19, 20} {
+			assertNoError(p.Continue(), t, "Continue")
+			frames, err := proc.ThreadStacktrace(p.CurrentThread(), 2)
+			assertNoError(err, t, "ThreadStacktrace")
+			if !frames[0].Inlined || frames[0].Call.Fn.Name != "main.outer" {
+				t.Fatalf("expected inlined frame for main.outer, got %s (inlined: %v)", frames[0].Call.Fn.Name, frames[0].Inlined)
+			}
+			if frames[1].Inlined || frames[1].Call.Fn.Name != "main.main" || frames[1].Call.Line != callLine {
+				t.Fatalf("expected caller main.main at line %d, got %s at line %d", callLine, frames[1].Call.Fn.Name, frames[1].Call.Line)
+			}
+		}
+	})
+}
+
 func TestInlineFunctionList(t *testing.T) {
 	// We should be able to list all functions, even inlined ones.
 	if ver, _ := goversion.Parse(runtime.Version()); ver.Major >= 0 && !ver.AfterOrEqual(goversion.GoVersion{Major: 1, Minor: 10, Rev: -1}) {
@@ -0,0 +1,359 @@
+package proc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-delve/delve/pkg/pprof"
+)
+
+// profileStackDepth is the maximum number of frames recorded for each
+// sample of a profile.
+const profileStackDepth = 100
+
+// profileBuilder converts stacktraces into pprof samples, symbolizing them
+// with the information contained in BinaryInfo.
+type profileBuilder struct {
+	bi       *BinaryInfo
+	p        *pprof.Profile
+	locs     map[uint64]*pprof.Location
+	fns      map[string]*pprof.Function
+	mappings map[*Image]*pprof.Mapping
+}
+
+func newProfileBuilder(bi *BinaryInfo, p *pprof.Profile) *profileBuilder {
+	return &profileBuilder{
+		bi:       bi,
+		p:        p,
+		locs:     make(map[uint64]*pprof.Location),
+		fns:      make(map[string]*pprof.Function),
+		mappings: make(map[*Image]*pprof.Mapping),
+	}
+}
+
+// addSample adds a sample with the specified values and labels for the
+// call stack described by frames.
+func (pb *profileBuilder) addSample(frames []Stackframe, values []int64, labels map[string][]string) {
+	pb.p.Sample = append(pb.p.Sample, &pprof.Sample{
+		Location: pb.stackLocations(frames),
+		Value:    values,
+		Label:    labels,
+	})
+}
+
+// stackLocations returns the list of pprof locations for frames. Inlined
+// frames are folded into the location of the frame containing them.
+func (pb *profileBuilder) stackLocations(frames []Stackframe) []*pprof.Location {
+	r := make([]*pprof.Location, 0, len(frames))
+	var lines []pprof.Line
+	for i := range frames {
+		frame := &frames[i]
+		if frame.Call.Fn != nil {
+			lines = append(lines, pprof.Line{Function: pb.function(frame.Call.Fn, frame.Call.File), Line: int64(frame.Call.Line)})
+		}
+		if frame.Inlined {
+			continue
+		}
+		r = append(r, pb.location(frame.Current.PC, lines))
+		lines = nil
+	}
+	return r
+}
+
+func (pb *profileBuilder) location(pc uint64, lines []pprof.Line) *pprof.Location {
+	if loc := pb.locs[pc]; loc != nil {
+		return loc
+	}
+	loc := &pprof.Location{
+		ID:      uint64(len(pb.p.Location) + 1),
+		Mapping: pb.mapping(pc),
+		Address: pc,
+		Line:    lines,
+	}
+	pb.locs[pc] = loc
+	pb.p.Location = append(pb.p.Location, loc)
+	return loc
+}
+
+func (pb *profileBuilder) function(fn *Function, file string) *pprof.Function {
+	if f := pb.fns[fn.Name]; f != nil {
+		return f
+	}
+	f := &pprof.Function{
+		ID:         uint64(len(pb.p.Function) + 1),
+		Name:       fn.Name,
+		SystemName: fn.Name,
+		Filename:   file,
+	}
+	if fn.Entry != 0 {
+		_, line, _ := pb.bi.PCToLine(fn.Entry)
+		f.StartLine = int64(line)
+	}
+	pb.fns[fn.Name] = f
+	pb.p.Function = append(pb.p.Function, f)
+	return f
+}
+
+func (pb *profileBuilder) mapping(pc uint64) *pprof.Mapping {
+	image := pb.bi.PCToImage(pc)
+	if image == nil {
+		return nil
+	}
+	if m := pb.mappings[image]; m != nil {
+		return m
+	}
+	m := &pprof.Mapping{
+		ID:              uint64(len(pb.p.Mapping) + 1),
+		File:            image.Path,
+		HasFunctions:    true,
+		HasFilenames:    true,
+		HasLineNumbers:  true,
+		HasInlineFrames: true,
+	}
+	for i := range pb.bi.Functions {
+		fn := &pb.bi.Functions[i]
+		if fn.cu == nil || fn.cu.image != image {
+			continue
+		}
+		if m.Start == 0 || fn.Entry < m.Start {
+			m.Start = fn.Entry
+		}
+		if fn.End > m.Limit {
+			m.Limit = fn.End
+		}
+	}
+	pb.mappings[image] = m
+	pb.p.Mapping = append(pb.p.Mapping, m)
+	return m
+}
+
+// GoroutineProfile returns a pprof goroutine profile containing one sample
+// for the stack of each goroutine of the target.
+// Each sample is labeled with the pprof labels of the goroutine and, for
+// parked goroutines, with its wait reason.
+func GoroutineProfile(t *Target) (*pprof.Profile, error) {
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pprof.Profile{
+		SampleType: []pprof.ValueType{{Type: "goroutine", Unit: "count"}},
+		PeriodType: pprof.ValueType{Type: "goroutine", Unit: "count"},
+		Period:     1,
+		TimeNanos:  time.Now().UnixNano(),
+	}
+	pb := newProfileBuilder(t.BinInfo(), p)
+
+	for _, g := range gs {
+		frames, err := g.Stacktrace(profileStackDepth, 0)
+		if err != nil {
+			p.Comments = append(p.Comments, fmt.Sprintf("could not read stack of goroutine %d: %v", g.ID, err))
+			continue
+		}
+		labels := make(map[string][]string)
+		for k, v := range g.Labels() {
+			labels[k] = []string{v}
+		}
+		if wr := g.WaitReasonString(); wr != "" {
+			labels["waitreason"] = []string{wr}
+		}
+		pb.addSample(frames, []int64{1}, labels)
+	}
+
+	return p, nil
+}
+
+// CPUProfileConfig describes how CPUProfile samples the target.
+type CPUProfileConfig struct {
+	Duration  time.Duration   // how long the target should be profiled for
+	Rate      int             // number of samples per second
+	Interrupt <-chan struct{} // closed to end profiling early
+}
+
+// CPUProfileStats describes the overhead of profiling the target with
+// CPUProfile.
+type CPUProfileStats struct {
+	Samples int           // number of stacks recorded
+	Stops   int           // number of times the target was stopped
+	Elapsed time.Duration // wall clock duration of the profile
+	Stopped time.Duration // time from each stop request of the profiler to the following resume
+}
+
+// CPUProfile profiles the target by periodically stopping it, recording
+// the stack of every thread running a goroutine and resuming it.
+// The returned profile can be read by 'go tool pprof', each sample is
+// labeled with the pprof labels of the goroutine it was collected from.
+// Profiling terminates early, without an error, if the target exits,
+// stops at a breakpoint, is stopped by a manual stop request not made by
+// the profiler or cfg.Interrupt is closed.
+func CPUProfile(t *Target, cfg CPUProfileConfig) (*pprof.Profile, *CPUProfileStats, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, nil, err
+	}
+	if cfg.Rate <= 0 {
+		return nil, nil, fmt.Errorf("invalid sampling rate %d", cfg.Rate)
+	}
+	interval := time.Second / time.Duration(cfg.Rate)
+
+	p := &pprof.Profile{
+		SampleType:        []pprof.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		DefaultSampleType: "cpu",
+		PeriodType:        pprof.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:            interval.Nanoseconds(),
+	}
+	pb := newProfileBuilder(t.BinInfo(), p)
+	stats := &CPUProfileStats{}
+
+	start := time.Now()
+	p.TimeNanos = start.UnixNano()
+
+	sampler := &cpuSampler{t: t, interval: interval}
+	var stoppedSince time.Time
+
+	for time.Since(start) < cfg.Duration {
+		if interrupted(cfg.Interrupt) {
+			p.Comments = append(p.Comments, "profiling interrupted")
+			break
+		}
+		if t.CheckAndClearManualStopRequest() {
+			// the stop requests of the sampler are cleared by continueOnce, this
+			// one was made by someone else while the target was stopped.
+			p.Comments = append(p.Comments, "profiling interrupted by a manual stop")
+			break
+		}
+		if !stoppedSince.IsZero() {
+			stats.Stopped += time.Since(stoppedSince)
+		}
+		requested, err := sampler.continueOnce()
+		stoppedSince = requested
+		if stoppedSince.IsZero() {
+			stoppedSince = time.Now()
+		}
+		if err != nil {
+			if _, exited := err.(ErrProcessExited); exited {
+				p.Comments = append(p.Comments, "target process exited")
+				stoppedSince = time.Time{}
+				break
+			}
+			return nil, nil, err
+		}
+		stats.Stops++
+
+		for _, th := range t.ThreadList() {
+			g, _ := GetG(th)
+			if g == nil || g.Status != Grunning {
+				// Threads that aren't executing a goroutine are either idle or
+				// blocked in a system call.
+				continue
+			}
+			frames, err := ThreadStacktrace(th, profileStackDepth)
+			if err != nil {
+				continue
+			}
+			labels := make(map[string][]string)
+			for k, v := range g.Labels() {
+				labels[k] = []string{v}
+			}
+			pb.addSample(frames, []int64{1, interval.Nanoseconds()}, labels)
+			stats.Samples++
+		}
+
+		if t.StopReason != StopManual {
+			p.Comments = append(p.Comments, "target stopped at a breakpoint")
+			break
+		}
+		if requested.IsZero() && !interrupted(cfg.Interrupt) {
+			p.Comments = append(p.Comments, "profiling interrupted by a manual stop")
+			break
+		}
+	}
+	if !stoppedSince.IsZero() {
+		stats.Stopped += time.Since(stoppedSince)
+	}
+
+	stats.Elapsed = time.Since(start)
+	p.DurationNanos = stats.Elapsed.Nanoseconds()
+	return p, stats, nil
+}
+
+// interrupted returns true if ch is closed.
+func interrupted(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// cpuSampler resumes the target and stops it again after a fixed
+// interval.
+type cpuSampler struct {
+	t        *Target
+	interval time.Duration
+
+	mu        sync.Mutex
+	running   bool      // Continue has not returned yet
+	requested time.Time // time of the first stop request, zero if none
+}
+
+// continueOnce resumes the target and stops it again after s.interval.
+// It returns the time at which the sampler asked the target to stop, or
+// the zero time if the target stopped for a different reason before that.
+// Stop requests are only made while Continue is executing, a request that
+// is still pending when Continue returns, because the target stopped for
+// a different reason, is cleared so that it does not end the next call to
+// Continue.
+func (s *cpuSampler) continueOnce() (time.Time, error) {
+	s.mu.Lock()
+	s.running = true
+	s.requested = time.Time{}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		timer := time.NewTimer(s.interval)
+		defer timer.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-timer.C:
+				s.mu.Lock()
+				if s.running {
+					if s.requested.IsZero() {
+						s.requested = time.Now()
+					}
+					// The stop request can be lost if it arrives before Continue has
+					// resumed the target, keep sending them until Continue returns.
+					s.t.RequestManualStop()
+				}
+				s.mu.Unlock()
+				timer.Reset(s.interval)
+			}
+		}
+	}()
+
+	err := s.t.Continue()
+
+	s.mu.Lock()
+	s.running = false
+	requested := s.requested
+	s.mu.Unlock()
+	close(done)
+	<-exited
+
+	if !requested.IsZero() {
+		s.t.CheckAndClearManualStopRequest()
+		if s.t.StopReason != StopManual {
+			// the target stopped for a different reason before the request was
+			// served.
+			requested = time.Time{}
+		}
+	}
+	return requested, err
+}
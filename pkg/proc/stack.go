@@ -321,7 +321,7 @@ func (it *stackIterator) newStackframe(ret, retaddr uint64) Stackframe {
 			// instruction to look for at pc - 1
 		default:
 			r.lastpc = it.pc - 1
-			r.Call.File, r.Call.Line = r.Current.Fn.cu.lineInfo.PCToLine(r.Current.Fn.Entry, it.pc-1)
+			r.Call.File, r.Call.Line = r.Current.Fn.cu.lineInfo().PCToLine(r.Current.Fn.Entry, it.pc-1)
 		}
 	}
 	return r
@@ -355,7 +355,7 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 	if frame.Call.Fn == nil {
 		return append(frames, frame)
 	}
-	if frame.Call.Fn.cu.lineInfo == nil {
+	if frame.Call.Fn.cu.lineTable == nil {
 		return append(frames, frame)
 	}
 
@@ -377,7 +377,7 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 		if !okname || !okfileidx || !okline {
 			break
 		}
-		if fileidx-1 < 0 || fileidx-1 >= int64(len(frame.Current.Fn.cu.lineInfo.FileNames)) {
+		if fileidx-1 < 0 || fileidx-1 >= int64(len(frame.Current.Fn.cu.lineTable.fileNames)) {
 			break
 		}
 
@@ -400,7 +400,7 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 			lastpc:      frame.lastpc,
 		})
 
-		frame.Call.File = frame.Current.Fn.cu.lineInfo.FileNames[fileidx-1].Path
+		frame.Call.File = frame.Current.Fn.cu.lineTable.fileNames[fileidx-1].Path
 		frame.Call.Line = int(line)
 	}
 
@@ -412,6 +412,11 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 // it.regs.CallFrameCFA is updated.
 func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uint64, retaddr uint64) {
 	fde, err := it.bi.frameEntries.FDEForPC(it.pc)
+	if _, nofde := err.(*frame.ErrNoFDEForPC); nofde {
+		// Functions written in C or assembly are often only described by
+		// .eh_frame.
+		fde, err = it.bi.ehFrameFDEForPC(it.pc)
+	}
 	var framectx *frame.FrameContext
 	if _, nofde := err.(*frame.ErrNoFDEForPC); nofde {
 		framectx = it.bi.Arch.fixFrameUnwindContext(nil, it.pc, it.bi)
@@ -41,6 +41,9 @@ type Target struct {
 	// fncallForG stores a mapping of current active function calls.
 	fncallForG map[int]*callInjection
 
+	// frozenGoroutines stores the goroutines suspended by FreezeGoroutine.
+	frozenGoroutines map[int]*frozenGoroutine
+
 	asyncPreemptChanged bool  // runtime/debug.asyncpreemptoff was changed
 	asyncPreemptOff     int64 // cached value of runtime/debug.asyncpreemptoff
 
@@ -84,6 +87,8 @@ type NewTargetConfig struct {
 	DebugInfoDirs       []string   // Directories to search for split debug info
 	DisableAsyncPreempt bool       // Go 1.14 asynchronous preemption should be disabled
 	StopReason          StopReason // Initial stop reason
+
+	BinaryInfoCache BinaryInfoCacheConfig // On-disk cache of debug information, disabled if Dir is empty
 }
 
 // DisableAsyncPreemptEnv returns a process environment (like os.Environ)
@@ -107,6 +112,7 @@ func NewTarget(p Process, cfg NewTargetConfig) (*Target, error) {
 		return nil, err
 	}
 
+	p.BinInfo().cacheConfig = cfg.BinaryInfoCache
 	err = p.BinInfo().LoadBinaryInfo(cfg.Path, entryPoint, cfg.DebugInfoDirs)
 	if err != nil {
 		return nil, err
@@ -216,6 +222,9 @@ func (p *Target) SwitchThread(tid int) error {
 // If kill is true then the process will be killed when we detach.
 func (t *Target) Detach(kill bool) error {
 	if !kill {
+		if err := t.ThawAllGoroutines(); err != nil {
+			return err
+		}
 		if t.asyncPreemptChanged {
 			setAsyncPreemptOff(t, t.asyncPreemptOff)
 		}
@@ -232,6 +241,50 @@ func (t *Target) Detach(kill bool) error {
 	return t.proc.Detach(kill)
 }
 
+// nanotimer is implemented by backends that can read the clock used by
+// runtime.nanotime in the target process.
+type nanotimer interface {
+	Nanotime() (int64, error)
+}
+
+// ErrNanotimeUnsupported is returned by Nanotime if the backend can not read
+// the clock of the target process.
+var ErrNanotimeUnsupported = errors.New("reading the target's clock is not supported by this backend")
+
+// Nanotime returns the value runtime.nanotime would return if it was
+// called by the target now. It can be compared with G.WaitSince.
+func (t *Target) Nanotime() (int64, error) {
+	if _, err := t.Valid(); err != nil {
+		return 0, err
+	}
+	if nt, ok := t.proc.(nanotimer); ok {
+		return nt.Nanotime()
+	}
+	return 0, ErrNanotimeUnsupported
+}
+
+// procFileReader is implemented by backends that can read the files in
+// /proc describing the target process.
+type procFileReader interface {
+	ReadProcFile(name string) ([]byte, error)
+}
+
+// ErrProcFileUnsupported is returned by ReadProcFile if the backend can not
+// read the /proc files of the target process.
+var ErrProcFileUnsupported = errors.New("reading the target's /proc files is not supported by this backend")
+
+// ReadProcFile returns the contents of /proc/<pid>/name for the target
+// process. Only supported by the native linux backend.
+func (t *Target) ReadProcFile(name string) ([]byte, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	if pr, ok := t.proc.(procFileReader); ok {
+		return pr.ReadProcFile(name)
+	}
+	return nil, ErrProcFileUnsupported
+}
+
 // setAsyncPreemptOff enables or disables async goroutine preemption by
 // writing the value 'v' to runtime.debug.asyncpreemptoff.
 // A value of '1' means off, a value of '0' means on.
@@ -2,6 +2,7 @@ package proc
 
 import (
 	"bytes"
+	"debug/dwarf"
 	"errors"
 	"fmt"
 	"go/ast"
@@ -10,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/go-delve/delve/pkg/astutil"
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
 )
 
@@ -33,6 +35,9 @@ func (dbp *Target) Next() (err error) {
 	if dbp.Breakpoints().HasInternalBreakpoints() {
 		return fmt.Errorf("next while nexting")
 	}
+	if err := dbp.checkNotFrozen(dbp.SelectedGoroutine()); err != nil {
+		return err
+	}
 
 	if err = next(dbp, false, false); err != nil {
 		dbp.ClearInternalBreakpoints()
@@ -90,6 +95,10 @@ func (dbp *Target) Continue() error {
 
 		threads := dbp.ThreadList()
 
+		if err := dbp.holdFrozenGoroutines(threads); err != nil {
+			return err
+		}
+
 		callInjectionDone, callErr := callInjectionProtocol(dbp, threads)
 		// callErr check delayed until after pickCurrentThread, which must always
 		// happen, otherwise the debugger could be left in an inconsistent
@@ -150,7 +159,7 @@ func (dbp *Target) Continue() error {
 				return conditionErrors(threads)
 			}
 		case curbp.Active && curbp.Internal:
-			switch curbp.Kind {
+			switch curbp.Kind &^ FreezeBreakpoint {
 			case StepBreakpoint:
 				// See description of proc.(*Process).next for the meaning of StepBreakpoints
 				if err := conditionErrors(threads); err != nil {
@@ -288,6 +297,9 @@ func (dbp *Target) Step() (err error) {
 	if dbp.Breakpoints().HasInternalBreakpoints() {
 		return fmt.Errorf("next while nexting")
 	}
+	if err := dbp.checkNotFrozen(dbp.SelectedGoroutine()); err != nil {
+		return err
+	}
 
 	if err = next(dbp, true, false); err != nil {
 		switch err.(type) {
@@ -329,6 +341,9 @@ func (dbp *Target) StepOut() error {
 	if dbp.Breakpoints().HasInternalBreakpoints() {
 		return fmt.Errorf("next while nexting")
 	}
+	if err := dbp.checkNotFrozen(dbp.SelectedGoroutine()); err != nil {
+		return err
+	}
 
 	selg := dbp.SelectedGoroutine()
 	curthread := dbp.CurrentThread()
@@ -401,6 +416,9 @@ func (dbp *Target) StepOut() error {
 func (dbp *Target) StepInstruction() (err error) {
 	thread := dbp.CurrentThread()
 	g := dbp.SelectedGoroutine()
+	if err := dbp.checkNotFrozen(g); err != nil {
+		return err
+	}
 	if g != nil {
 		if g.Thread == nil {
 			// Step called on parked goroutine
@@ -539,6 +557,10 @@ func next(dbp *Target, stepInto, inlinedStepOut bool) error {
 		if err != nil {
 			return err
 		}
+		err = setStepIntoInlinedBreakpoints(dbp, topframe, sameFrameCond)
+		if err != nil {
+			return err
+		}
 	}
 
 	if !backward {
@@ -549,7 +571,7 @@ func next(dbp *Target, stepInto, inlinedStepOut bool) error {
 	}
 
 	// Add breakpoints on all the lines in the current function
-	pcs, err := topframe.Current.Fn.cu.lineInfo.AllPCsBetween(topframe.Current.Fn.Entry, topframe.Current.Fn.End-1, topframe.Current.File, topframe.Current.Line)
+	pcs, err := topframe.Current.Fn.cu.lineInfo().AllPCsBetween(topframe.Current.Fn.Entry, topframe.Current.Fn.End-1, topframe.Current.File, topframe.Current.Line)
 	if err != nil {
 		return err
 	}
@@ -667,6 +689,77 @@ func setStepIntoBreakpoints(dbp *Target, curfn *Function, text []AsmInstruction,
 	return nil
 }
 
+// setStepIntoInlinedBreakpoints sets a breakpoint on the first instruction
+// of every inlined call made from the current line of topframe, so that
+// stepping into it stops at its entry point, as if it was a real call.
+func setStepIntoInlinedBreakpoints(dbp *Target, topframe Stackframe, cond ast.Expr) error {
+	dwarfTree, err := topframe.Current.Fn.cu.image.getDwarfTree(topframe.Current.Fn.offset)
+	if err != nil {
+		return err
+	}
+	if topframe.Inlined {
+		// Only look at the calls made by the inlined call topframe is part of.
+		found := false
+		for _, e := range reader.InlineStack(dwarfTree, topframe.lastpc) {
+			if e.Offset == topframe.Call.Fn.offset {
+				dwarfTree = e
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	fileNames := topframe.Current.Fn.cu.lineTable.fileNames
+	staticBase := topframe.Current.Fn.cu.image.StaticBase
+	var visit func(*godwarf.Tree) error
+	visit = func(n *godwarf.Tree) error {
+		for _, e := range n.Children {
+			switch e.Tag {
+			case dwarf.TagLexDwarfBlock:
+				if err := visit(e); err != nil {
+					return err
+				}
+			case dwarf.TagInlinedSubroutine:
+				fileidx, okfileidx := e.Val(dwarf.AttrCallFile).(int64)
+				line, okline := e.Val(dwarf.AttrCallLine).(int64)
+				if !okfileidx || !okline || fileidx-1 < 0 || fileidx-1 >= int64(len(fileNames)) || len(e.Ranges) == 0 {
+					continue
+				}
+				if fileNames[fileidx-1].Path != topframe.Current.File || int(line) != topframe.Current.Line {
+					continue
+				}
+				if _, err := allowDuplicateBreakpoint(dbp.SetBreakpoint(inlinedCallEntryPC(e, staticBase), NextBreakpoint, cond)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return visit(dwarfTree)
+}
+
+// inlinedCallEntryPC returns the entry point of the inlined call described
+// by e. This is DW_AT_entry_pc if it is present, otherwise DW_AT_low_pc or
+// the start of the lowest address range of the call, which doesn't need to
+// be the first one executed.
+func inlinedCallEntryPC(e *godwarf.Tree, staticBase uint64) uint64 {
+	base := e.Ranges[0][0]
+	if lowpc, ok := e.Val(dwarf.AttrLowpc).(uint64); ok {
+		base = lowpc + staticBase
+	}
+	switch entrypc := e.Val(dwarf.AttrEntrypc).(type) {
+	case uint64:
+		return entrypc + staticBase
+	case int64:
+		// Since DWARFv5 DW_AT_entry_pc can be an offset from the base address
+		// of the entry.
+		return base + uint64(entrypc)
+	}
+	return base
+}
+
 func setStepIntoBreakpointsReverse(dbp *Target, text []AsmInstruction, topframe Stackframe, sameGCond ast.Expr) error {
 	// Set a breakpoint after every CALL instruction
 	for i, instr := range text {
@@ -701,16 +794,31 @@ func FindDeferReturnCalls(text []AsmInstruction) []uint64 {
 	return deferreturns
 }
 
-// Removes instructions belonging to inlined calls of topframe from pcs.
-// If includeCurrentFn is true it will also remove all instructions
-// belonging to the current function.
-func removeInlinedCalls(pcs []uint64, topframe Stackframe) ([]uint64, error) {
-	dwarfTree, err := topframe.Call.Fn.cu.image.getDwarfTree(topframe.Call.Fn.offset)
+// Removes instructions belonging to inlined calls from pcs, except for
+// the inlined calls that frame is part of: frame itself, if it is an
+// inlined call, and all the inlined calls containing it.
+// Instructions belonging to inlined calls made by frame and to inlined
+// calls that are siblings of frame (or of one of the calls containing it)
+// are removed.
+func removeInlinedCalls(pcs []uint64, frame Stackframe) ([]uint64, error) {
+	dwarfTree, err := frame.Current.Fn.cu.image.getDwarfTree(frame.Current.Fn.offset)
 	if err != nil {
 		return pcs, err
 	}
+	keep := make(map[dwarf.Offset]bool)
+	if frame.Inlined {
+		stack := reader.InlineStack(dwarfTree, frame.lastpc)
+		for i := range stack {
+			if stack[i].Offset == frame.Call.Fn.offset {
+				for _, e := range stack[i:] {
+					keep[e.Offset] = true
+				}
+				break
+			}
+		}
+	}
 	for _, e := range reader.InlineStack(dwarfTree, 0) {
-		if e.Offset == topframe.Call.Fn.offset {
+		if keep[e.Offset] {
 			continue
 		}
 		for _, rng := range e.Ranges {
@@ -891,7 +999,7 @@ func skipAutogeneratedWrappersOut(g *G, thread Thread, startTopframe, startRetfr
 		if frame.Current.Fn == nil {
 			return
 		}
-		file, line := frame.Current.Fn.cu.lineInfo.PCToLine(frame.Current.Fn.Entry, frame.Current.Fn.Entry)
+		file, line := frame.Current.Fn.cu.lineInfo().PCToLine(frame.Current.Fn.Entry, frame.Current.Fn.Entry)
 		if !isAutogenerated(Location{File: file, Line: line, Fn: frame.Current.Fn}) {
 			return &frames[i-1], &frames[i]
 		}
@@ -1007,7 +1115,7 @@ func stepOutReverse(p *Target, topframe, retframe Stackframe, sameGCond ast.Expr
 func onNextGoroutine(thread Thread, breakpoints *BreakpointMap) (bool, error) {
 	var bp *Breakpoint
 	for i := range breakpoints.M {
-		if breakpoints.M[i].Kind != UserBreakpoint && breakpoints.M[i].internalCond != nil {
+		if breakpoints.M[i].IsInternal() && breakpoints.M[i].internalCond != nil {
 			bp = breakpoints.M[i]
 			break
 		}
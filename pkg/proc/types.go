@@ -81,6 +81,22 @@ type loadDebugInfoMapsContext struct {
 	abstractOriginTable map[dwarf.Offset]int
 	knownPackageVars    map[string]struct{}
 	offsetToVersion     map[dwarf.Offset]uint8
+
+	runtimeTypeToDIE map[uint64]runtimeTypeDIE
+
+	// pendingAbstractOrigins lists the concrete subprograms and inlined calls
+	// whose abstract origin wasn't found in the compile unit being loaded.
+	pendingAbstractOrigins []pendingAbstractOrigin
+}
+
+// pendingAbstractOrigin is a reference to an abstract origin that couldn't
+// be resolved while loading a compile unit.
+type pendingAbstractOrigin struct {
+	originOffset dwarf.Offset
+	offset       dwarf.Offset // offset of the entry referencing originOffset
+	concrete     bool         // the entry is a concrete subprogram, instead of an inlined call
+	call         InlinedCall  // address range of the entry
+	callLine     fileLine     // call site of the inlined call
 }
 
 func newLoadDebugInfoMapsContext(bi *BinaryInfo, image *Image, offsetToVersion map[dwarf.Offset]uint8) *loadDebugInfoMapsContext {
@@ -98,6 +114,29 @@ func newLoadDebugInfoMapsContext(bi *BinaryInfo, image *Image, offsetToVersion m
 	return ctxt
 }
 
+// newUnitContext returns the context used to load a single compile unit
+// of image.
+func (ctxt *loadDebugInfoMapsContext) newUnitContext(image *Image) *loadDebugInfoMapsContext {
+	return &loadDebugInfoMapsContext{
+		ardr:                image.DwarfReader(),
+		abstractOriginTable: make(map[dwarf.Offset]int),
+		knownPackageVars:    ctxt.knownPackageVars,
+		offsetToVersion:     ctxt.offsetToVersion,
+		runtimeTypeToDIE:    make(map[uint64]runtimeTypeDIE),
+	}
+}
+
+// registerRuntimeTypeToDIE records the runtime._type of entry. The key is
+// the offset of the runtime._type in runtime.moduledata.types, which does
+// not depend on the static base of the image.
+func (ctxt *loadDebugInfoMapsContext) registerRuntimeTypeToDIE(entry *dwarf.Entry) {
+	if off, ok := entry.Val(godwarf.AttrGoRuntimeType).(uint64); ok {
+		if _, ok := ctxt.runtimeTypeToDIE[off]; !ok {
+			ctxt.runtimeTypeToDIE[off] = runtimeTypeDIE{entry.Offset, -1}
+		}
+	}
+}
+
 // runtimeTypeToDIE returns the DIE corresponding to the runtime._type.
 // This is done in three different ways depending on the version of go.
 // * Before go1.7 the type name is retrieved directly from the runtime._type
@@ -120,8 +159,8 @@ func runtimeTypeToDIE(_type *Variable, dataAddr uint64) (typ godwarf.Type, kind
 	}
 
 	md := findModuleDataForType(bi, mds, _type.Addr, _type.mem)
-	if md != nil {
-		so := bi.moduleDataToImage(md)
+	if md != nil && md.image != nil {
+		so := md.image
 		if rtdie, ok := so.runtimeTypeToDIE[uint64(_type.Addr-md.types)]; ok {
 			typ, err := godwarf.ReadType(so.dwarf, so.index, rtdie.offset, so.typeCache)
 			if err != nil {
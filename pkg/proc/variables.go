@@ -79,6 +79,9 @@ const (
 	VariableFakeAddress
 	// VariableCPrt means the variable is a C pointer
 	VariableCPtr
+	// VariableOptimizedOut means the value of this variable is not available
+	// at the current PC because it was optimized away by the compiler
+	VariableOptimizedOut
 )
 
 // Variable represents a variable. It contains the address, name,
@@ -119,6 +122,12 @@ type Variable struct {
 
 	Children []Variable
 
+	// value held by a reflect.Value, loaded by ReflectValue using
+	// reflectValueCfg at recursion level reflectValueRecurse
+	reflectValue        *Variable
+	reflectValueCfg     *LoadConfig
+	reflectValueRecurse int
+
 	loaded     bool
 	Unreadable error
 
@@ -186,6 +195,92 @@ const (
 	Gcopystack                    // 8 in this state when newstack is moving the stack
 )
 
+// waitReasonNames maps the names of the runtime.waitReason constants to
+// their description, as in runtime.waitReasonStrings. It is used when
+// runtime.waitReasonStrings can not be read from the target, the values of
+// the constants are read from debug_info since they change between
+// versions of Go.
+var waitReasonNames = map[string]string{
+	"waitReasonZero":                  "",
+	"waitReasonGCAssistMarking":       "GC assist marking",
+	"waitReasonIOWait":                "IO wait",
+	"waitReasonChanReceiveNilChan":    "chan receive (nil chan)",
+	"waitReasonChanSendNilChan":       "chan send (nil chan)",
+	"waitReasonDumpingHeap":           "dumping heap",
+	"waitReasonGarbageCollection":     "garbage collection",
+	"waitReasonGarbageCollectionScan": "garbage collection scan",
+	"waitReasonPanicWait":             "panicwait",
+	"waitReasonSelect":                "select",
+	"waitReasonSelectNoCases":         "select (no cases)",
+	"waitReasonGCAssistWait":          "GC assist wait",
+	"waitReasonGCSweepWait":           "GC sweep wait",
+	"waitReasonGCScavengeWait":        "GC scavenge wait",
+	"waitReasonChanReceive":           "chan receive",
+	"waitReasonChanSend":              "chan send",
+	"waitReasonFinalizerWait":         "finalizer wait",
+	"waitReasonForceGCIdle":           "force gc (idle)",
+	"waitReasonUpdateGOMAXPROCSIdle":  "GOMAXPROCS updater (idle)",
+	"waitReasonSemacquire":            "semacquire",
+	"waitReasonSleep":                 "sleep",
+	"waitReasonSyncCondWait":          "sync.Cond.Wait",
+	"waitReasonSyncMutexLock":         "sync.Mutex.Lock",
+	"waitReasonSyncRWMutexRLock":      "sync.RWMutex.RLock",
+	"waitReasonSyncRWMutexLock":       "sync.RWMutex.Lock",
+	"waitReasonSyncWaitGroupWait":     "sync.WaitGroup.Wait",
+	"waitReasonTraceReaderBlocked":    "trace reader (blocked)",
+	"waitReasonWaitForGCCycle":        "wait for GC cycle",
+	"waitReasonGCWorkerIdle":          "GC worker (idle)",
+	"waitReasonGCWorkerActive":        "GC worker (active)",
+	"waitReasonPreempted":             "preempted",
+	"waitReasonDebugCall":             "debug call",
+	"waitReasonGCMarkTermination":     "GC mark termination",
+	"waitReasonStoppingTheWorld":      "stopping the world",
+	"waitReasonFlushProcCaches":       "flushing proc caches",
+	"waitReasonTraceGoroutineStatus":  "trace goroutine status",
+	"waitReasonTraceProcStatus":       "trace proc status",
+	"waitReasonPageTraceFlush":        "page trace flush",
+	"waitReasonCoroutine":             "coroutine",
+	"waitReasonGCWeakToStrongWait":    "GC weak to strong wait",
+	"waitReasonSynctestRun":           "synctest.Run",
+	"waitReasonSynctestWait":          "synctest.Wait",
+	"waitReasonSynctestChanReceive":   "chan receive (durable)",
+	"waitReasonSynctestChanSend":      "chan send (durable)",
+	"waitReasonSynctestSelect":        "select (durable)",
+	"waitReasonSynctestWaitGroupWait": "sync.WaitGroup.Wait (durable)",
+	"waitReasonCleanupWait":           "cleanup wait",
+}
+
+// waitReasonStrings returns the contents of runtime.waitReasonStrings,
+// read from the target the first time it can be read, or nil if it can
+// not be read.
+func (bi *BinaryInfo) waitReasonStrings(mem MemoryReadWriter) []string {
+	bi.waitReasonsMu.Lock()
+	defer bi.waitReasonsMu.Unlock()
+	if bi.waitReasons != nil {
+		return bi.waitReasons
+	}
+	scope := globalScope(bi, bi.Images[0], mem)
+	v, err := scope.findGlobal("runtime", "waitReasonStrings")
+	if err != nil {
+		return nil
+	}
+	v.loadValue(LoadConfig{MaxStringLen: 64, MaxArrayValues: 256})
+	if v.Unreadable != nil || v.Kind != reflect.Array || int64(len(v.Children)) != v.Len {
+		return nil
+	}
+	r := make([]string, len(v.Children))
+	for i := range v.Children {
+		if v.Children[i].Unreadable != nil || v.Children[i].Value == nil {
+			return nil
+		}
+		r[i] = constant.StringVal(v.Children[i].Value)
+	}
+	// only successful reads are cached, the target could be unreadable the
+	// first time, for example before it is stopped
+	bi.waitReasons = r
+	return r
+}
+
 // G represents a runtime G (goroutine) structure (at least the
 // fields that Delve is interested in).
 type G struct {
@@ -201,6 +296,23 @@ type G struct {
 	stkbarPos int       // stkbarPos field of g struct
 	stack     stack     // value of stack
 
+	// ParentID is the ID of the goroutine that created this goroutine, it is
+	// recorded by the runtime starting with Go 1.21, on older versions it is
+	// only known if the target runs with GODEBUG=tracebackancestors=N and is
+	// zero otherwise.
+	ParentID int
+
+	// WaitReason is the reason why the goroutine is parked, only meaningful
+	// if Status is Gwaiting. See WaitReasonString.
+	WaitReason int64
+	// waitReasonName is the name of the runtime.waitReason constant with
+	// value WaitReason, if there is one.
+	waitReasonName string
+	// WaitSince is the approximate value of runtime.nanotime when the
+	// goroutine was parked, or 0 if unknown. The runtime only records it
+	// when a garbage collection finds the goroutine parked.
+	WaitSince int64
+
 	SystemStack bool // SystemStack is true if this goroutine is currently executing on a system stack.
 
 	// Information on goroutine location
@@ -522,6 +634,65 @@ func (g *G) StartLoc() Location {
 	return Location{PC: g.StartPC, File: f, Line: l, Fn: fn}
 }
 
+// WaitReasonString returns a description of the reason why the goroutine
+// is parked, or the empty string if the goroutine isn't waiting.
+func (g *G) WaitReasonString() string {
+	if g.Status != Gwaiting {
+		return ""
+	}
+	if g.variable != nil {
+		if strs := g.variable.bi.waitReasonStrings(g.variable.mem); g.WaitReason >= 0 && g.WaitReason < int64(len(strs)) {
+			return strs[g.WaitReason]
+		}
+	}
+	if s, ok := waitReasonNames[g.waitReasonName]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown wait reason %d", g.WaitReason)
+}
+
+// WaitObject describes the object a parked goroutine is blocked on.
+type WaitObject struct {
+	Kind string // "chan" for channel operations, "sema" for semaphores
+	Addr uint64 // address of the channel or of the semaphore
+}
+
+// BlockedOn returns the object g is blocked on, or nil if g isn't blocked
+// on a channel or a semaphore.
+// Goroutines blocked in a select statement wait on multiple channels, for
+// them nil is returned.
+func (g *G) BlockedOn() *WaitObject {
+	var kind, field string
+	switch g.WaitReasonString() {
+	case "chan receive", "chan send":
+		kind, field = "chan", "c"
+	case "semacquire", "sync.Mutex.Lock", "sync.RWMutex.RLock", "sync.RWMutex.Lock", "sync.WaitGroup.Wait":
+		kind, field = "sema", "elem"
+	default:
+		return nil
+	}
+	if g.variable == nil {
+		return nil
+	}
+	waitingVar, err := g.variable.structMember("waiting")
+	if err != nil {
+		return nil
+	}
+	sudogVar := waitingVar.maybeDereference()
+	if sudogVar.Unreadable != nil || sudogVar.Addr == 0 {
+		return nil
+	}
+	objVar, err := sudogVar.structMember(field)
+	if err != nil {
+		return nil
+	}
+	addr, err := readUintRaw(objVar.mem, objVar.Addr, objVar.RealType.Size())
+	if err != nil || addr == 0 {
+		return nil
+	}
+	return &WaitObject{Kind: kind, Addr: addr}
+}
+
 func (g *G) Labels() map[string]string {
 	if g.labels != nil {
 		return *g.labels
@@ -868,6 +1039,28 @@ func (v *Variable) parseG() (*G, error) {
 
 	status := loadInt64Maybe("atomicstatus")
 
+	var waitReason int64
+	var waitReasonName string
+	if wrVar := v.loadFieldNamed("waitreason"); wrVar != nil && wrVar.Value != nil && wrVar.Value.Kind() == constant.Int {
+		// Before Go 1.11 waitreason was a string, we ignore it.
+		waitReason, _ = constant.Int64Val(wrVar.Value)
+		if ctyp := v.bi.consts.Get(wrVar.DwarfType); ctyp != nil {
+			waitReasonName = ctyp.name(waitReason)
+		}
+	}
+
+	var waitSince int64
+	if wsVar := v.loadFieldNamed("waitsince"); wsVar != nil && wsVar.Value != nil {
+		waitSince, _ = constant.Int64Val(wsVar.Value)
+	}
+
+	var parentID int64
+	if pidVar := v.loadFieldNamed("parentGoid"); pidVar != nil && pidVar.Value != nil {
+		parentID, _ = constant.Int64Val(pidVar.Value)
+	} else {
+		parentID = v.firstAncestorID()
+	}
+
 	if unreadable {
 		return nil, ErrUnreadableG
 	}
@@ -877,23 +1070,51 @@ func (v *Variable) parseG() (*G, error) {
 	v.Name = "runtime.curg"
 
 	g := &G{
-		ID:         int(id),
-		GoPC:       uint64(gopc),
-		StartPC:    uint64(startpc),
-		PC:         uint64(pc),
-		SP:         uint64(sp),
-		BP:         uint64(bp),
-		LR:         uint64(lr),
-		Status:     uint64(status),
-		CurrentLoc: Location{PC: uint64(pc), File: f, Line: l, Fn: fn},
-		variable:   v,
-		stkbarVar:  stkbarVar,
-		stkbarPos:  int(stkbarPos),
-		stack:      stack{hi: stackhi, lo: stacklo},
+		ID:             int(id),
+		GoPC:           uint64(gopc),
+		StartPC:        uint64(startpc),
+		PC:             uint64(pc),
+		SP:             uint64(sp),
+		BP:             uint64(bp),
+		LR:             uint64(lr),
+		Status:         uint64(status),
+		WaitReason:     waitReason,
+		waitReasonName: waitReasonName,
+		WaitSince:      waitSince,
+		ParentID:       int(parentID),
+		CurrentLoc:     Location{PC: uint64(pc), File: f, Line: l, Fn: fn},
+		variable:       v,
+		stkbarVar:      stkbarVar,
+		stkbarPos:      int(stkbarPos),
+		stack:          stack{hi: stackhi, lo: stacklo},
 	}
 	return g, nil
 }
 
+// firstAncestorID returns the ID of the goroutine that created v, a
+// runtime.g variable, as recorded in its ancestors when the target runs
+// with GODEBUG=tracebackancestors=N. Returns 0 if it isn't recorded.
+func (v *Variable) firstAncestorID() int64 {
+	av, err := v.structMember("ancestors")
+	if err != nil {
+		return 0
+	}
+	av = av.maybeDereference()
+	if av.Unreadable != nil || av.Addr == 0 {
+		return 0
+	}
+	av.loadValue(LoadConfig{MaxArrayValues: 1, MaxVariableRecurse: 1, MaxStructFields: -1})
+	if av.Unreadable != nil || len(av.Children) == 0 {
+		return 0
+	}
+	goidv := av.Children[0].fieldVariable("goid")
+	if goidv == nil || goidv.Unreadable != nil || goidv.Value == nil {
+		return 0
+	}
+	id, _ := constant.Int64Val(goidv.Value)
+	return id
+}
+
 func (v *Variable) loadFieldNamed(name string) *Variable {
 	v, err := v.structMember(name)
 	if err != nil {
@@ -1000,7 +1221,7 @@ func (a *Ancestor) Stack(n int) ([]Stackframe, error) {
 		if pc2-1 >= fn.Entry {
 			pc2--
 		}
-		f, ln := fn.cu.lineInfo.PCToLine(fn.Entry, pc2)
+		f, ln := fn.cu.lineInfo().PCToLine(fn.Entry, pc2)
 		loc := Location{PC: uint64(pc), File: f, Line: ln, Fn: fn}
 		r[i] = Stackframe{Current: loc, Call: loc}
 	}
@@ -1155,6 +1376,9 @@ func extractVarInfoFromEntry(bi *BinaryInfo, image *Image, regs op.DwarfRegister
 	v.DeclLine, _ = entry.Val(dwarf.AttrDeclLine).(int64)
 	if err != nil {
 		v.Unreadable = err
+		if isOptimizedOut(err) {
+			v.Flags |= VariableOptimizedOut
+		}
 	}
 	return v, nil
 }
@@ -1269,6 +1493,12 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 				v.Children[i].Name = field.Name
 				v.Children[i].loadValueInternal(recurseLevel+1, cfg)
 			}
+			if t.StructName == "reflect.Value" {
+				// the value held by a reflect.Value is only loaded if
+				// somebody asks for it, see ReflectValue
+				v.reflectValueCfg = &cfg
+				v.reflectValueRecurse = recurseLevel + 1
+			}
 		}
 
 	case reflect.Interface:
@@ -1311,35 +1541,6 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 	}
 }
 
-// convertToEface converts srcv into an "interface {}" and writes it to
-// dstv.
-// Dstv must be a variable of type "inteface {}" and srcv must either be an
-// interface or a pointer shaped variable (map, channel, pointer or struct
-// containing a single pointer)
-func convertToEface(srcv, dstv *Variable) error {
-	if dstv.RealType.String() != "interface {}" {
-		return &typeConvErr{srcv.DwarfType, dstv.RealType}
-	}
-	if _, isiface := srcv.RealType.(*godwarf.InterfaceType); isiface {
-		// iface -> eface conversion
-		_type, data, _ := srcv.readInterface()
-		if srcv.Unreadable != nil {
-			return srcv.Unreadable
-		}
-		_type = _type.maybeDereference()
-		dstv.writeEmptyInterface(uint64(_type.Addr), data)
-		return nil
-	}
-	typeAddr, typeKind, runtimeTypeFound, err := dwarfToRuntimeType(srcv.bi, srcv.mem, srcv.RealType)
-	if err != nil {
-		return err
-	}
-	if !runtimeTypeFound || typeKind&kindDirectIface == 0 {
-		return &typeConvErr{srcv.DwarfType, dstv.RealType}
-	}
-	return dstv.writeEmptyInterface(typeAddr, srcv)
-}
-
 func readStringInfo(mem MemoryReadWriter, arch *Arch, addr uint64) (uint64, int64, error) {
 	// string data structure is always two ptrs in size. Addr, followed by len
 	// http://research.swtch.com/godata
@@ -1718,17 +1919,6 @@ func (v *Variable) writeZero() error {
 	return err
 }
 
-// writeInterface writes the empty interface of type typeAddr and data as the data field.
-func (v *Variable) writeEmptyInterface(typeAddr uint64, data *Variable) error {
-	dstType, dstData, _ := v.readInterface()
-	if v.Unreadable != nil {
-		return v.Unreadable
-	}
-	dstType.writeUint(typeAddr, dstType.RealType.Size())
-	dstData.writeCopy(data)
-	return nil
-}
-
 func (v *Variable) writeSlice(len, cap int64, base uint64) error {
 	for _, f := range v.RealType.(*godwarf.SliceType).Field {
 		switch f.Name {
@@ -2196,6 +2386,82 @@ func (v *Variable) loadInterface(recurseLevel int, loadData bool, cfg LoadConfig
 	}
 }
 
+// reflectFlagIndir is the flag of reflect.Value set when its ptr field
+// points to the value, see $GOROOT/src/reflect/value.go.
+const reflectFlagIndir = 1 << 7
+
+// ReflectValue returns the value held by v, a variable of type
+// reflect.Value, the returned variable has kind reflect.Invalid if v is
+// the zero reflect.Value.
+// The value is loaded the first time ReflectValue is called, using the
+// LoadConfig used to load v.
+// Returns nil if v was not loaded as a reflect.Value, because v is not a
+// reflect.Value or because of the limits of the LoadConfig used.
+func (v *Variable) ReflectValue() *Variable {
+	if v.reflectValue == nil && v.reflectValueCfg != nil {
+		v.loadReflectValue(v.reflectValueRecurse, *v.reflectValueCfg)
+		v.reflectValueCfg = nil
+	}
+	return v.reflectValue
+}
+
+// loadReflectValue loads the value held by v, a variable of type
+// reflect.Value.
+func (v *Variable) loadReflectValue(recurseLevel int, cfg LoadConfig) {
+	if v.Unreadable != nil || v.mem == nil || v.Addr == 0 || v.Flags&VariableFakeAddress != 0 {
+		return
+	}
+	r, err := v.reflectValueData()
+	if err != nil {
+		v.reflectValue = &Variable{Unreadable: err}
+		return
+	}
+	if r.Kind != reflect.Invalid {
+		r.loadValueInternal(recurseLevel, cfg)
+	}
+	v.reflectValue = r
+}
+
+// reflectValueData returns the variable held by v, a variable of type
+// reflect.Value, without loading it.
+func (v *Variable) reflectValueData() (*Variable, error) {
+	_type, err := v.structMember("typ_")
+	if err != nil {
+		// before go1.21 the field was called typ
+		_type, err = v.structMember("typ")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _type.maybeDereference().Addr == 0 {
+		return &Variable{Kind: reflect.Invalid}, nil
+	}
+	ptr, err := v.structMember("ptr")
+	if err != nil {
+		return nil, err
+	}
+	flag := v.loadFieldNamed("flag")
+	if flag == nil || flag.Value == nil {
+		return nil, errors.New("invalid reflect.Value flag")
+	}
+	dataAddr, err := readUintRaw(v.mem, ptr.Addr, int64(v.bi.Arch.PtrSize()))
+	if err != nil {
+		return nil, err
+	}
+	typ, _, err := runtimeTypeToDIE(_type, dataAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	flagv, _ := constant.Uint64Val(flag.Value)
+	addr := dataAddr
+	if flagv&reflectFlagIndir == 0 {
+		// the value is stored directly in the ptr field
+		addr = ptr.Addr
+	}
+	return v.newVariable("", addr, typ, v.mem), nil
+}
+
 // ConstDescr describes the value of v using constants.
 func (v *Variable) ConstDescr() string {
 	if v.bi == nil || (v.Flags&VariableConstant != 0) {
@@ -2291,6 +2557,16 @@ func (cm constantsMap) Get(typ godwarf.Type) *constantType {
 	return ctyp
 }
 
+// name returns the name of the constant with value n, or the empty string.
+func (ctyp *constantType) name(n int64) string {
+	for _, val := range ctyp.values {
+		if val.value == n {
+			return val.name
+		}
+	}
+	return ""
+}
+
 func (ctyp *constantType) describe(n int64) string {
 	for _, val := range ctyp.values {
 		if val.value == n {
@@ -5,11 +5,15 @@ package terminal
 import (
 	"bufio"
 	"bytes"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"go/ast"
 	"go/parser"
 	"go/scanner"
 	"io"
+	"io/ioutil"
 	"math"
 	"os"
 	"os/exec"
@@ -20,6 +24,7 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/cosiner/argv"
 	"github.com/go-delve/delve/pkg/locspec"
@@ -86,11 +91,6 @@ type Commands struct {
 }
 
 var (
-	// longLoadConfig loads more information:
-	// * Follows pointers
-	// * Loads more array values
-	// * Does not limit struct fields
-	longLoadConfig = api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
 	// ShortLoadConfig loads less information, not following pointers
 	// and limiting struct fields loaded to 3.
 	ShortLoadConfig = api.LoadConfig{MaxStringLen: 64, MaxStructFields: 3}
@@ -164,6 +164,10 @@ Optional [count] argument allows you to skip multiple lines.
 	
 	call [-unsafe] <function call expression>
 	
+Arguments can be composite literals, for example []string{"a", "b"} or map[string]int{"a": 1}, their values are allocated in the target. Trailing arguments of variadic functions are packed into a slice, unless the last argument is followed by '...'. Functions and method values can be passed as arguments of func type.
+
+Methods of interface values are resolved using the dynamic type of the interface, for example 'call err.Error()'. Methods with a pointer receiver can also be called on values that are not addressable, such as the return value of another call, in which case the method receives a pointer to a copy of the value.
+
 Current limitations:
 - only pointers to stack-allocated objects can be passed as argument.
 - only some automatic type conversions are supported.
@@ -191,6 +195,8 @@ If called with the linespec argument it will delete all the breakpoints matching
 		{aliases: []string{"goroutines", "grs"}, group: goroutineCmds, cmdFn: goroutines, helpMsg: `List program goroutines.
 
 	goroutines [-u (default: user location)|-r (runtime location)|-g (go statement location)|-s (start location)] [-t (stack trace)] [-l (labels)]
+	goroutines -pprof <file>
+	goroutines -tree
 
 Print out info for every goroutine. The flag controls what information is shown along with each goroutine:
 
@@ -201,7 +207,11 @@ Print out info for every goroutine. The flag controls what information is shown
 	-t	displays goroutine's stacktrace
 	-l	displays goroutine's labels
 
-If no flag is specified the default is -u.`},
+If no flag is specified the default is -u.
+
+When called with -pprof the stacks of all goroutines are written to <file> as a pprof goroutine profile, that can be opened with 'go tool pprof'. Each sample is labeled with the goroutine's labels and wait reason.
+
+When called with -tree goroutines are grouped by the go statement that created them and arranged in a tree, where the children of each node are the goroutines created by the goroutines of that node. Each node shows how many goroutines were created at its go statement, making it easy to find which one is leaking goroutines. The parent of a goroutine is recorded by Go 1.21 and later, for older versions of Go it is only available if the program is run with GODEBUG=tracebackancestors=N, otherwise all goroutines are shown as direct children of the root.`},
 		{aliases: []string{"goroutine", "gr"}, group: goroutineCmds, allowedPrefixes: onPrefix, cmdFn: c.goroutine, helpMsg: `Shows or changes current goroutine
 
 	goroutine
@@ -211,12 +221,29 @@ If no flag is specified the default is -u.`},
 Called without arguments it will show information about the current goroutine.
 Called with a single argument it will switch to the specified goroutine.
 Called with more arguments it will execute a command on the specified goroutine.`},
+		{aliases: []string{"freeze"}, group: goroutineCmds, cmdFn: freeze, helpMsg: `Suspends a goroutine while the rest of the program keeps running.
+
+	[goroutine <n>] freeze
+
+The goroutine will not execute when the program is resumed until it is released with the thaw command, frozen goroutines are marked in the output of the goroutines command and are automatically released when the debugger detaches.
+Note that a frozen goroutine keeps its thread stopped, if the garbage collector needs to stop the world while the goroutine is executing the whole program will eventually block.
+
+Only supported on linux's native backend.`},
+		{aliases: []string{"thaw"}, group: goroutineCmds, cmdFn: thaw, helpMsg: `Releases a goroutine suspended by freeze.
+
+	[goroutine <n>] thaw`},
 		{aliases: []string{"breakpoints", "bp"}, group: breakCmds, cmdFn: breakpoints, helpMsg: "Print out info for active breakpoints."},
 		{aliases: []string{"print", "p"}, group: dataCmds, allowedPrefixes: onPrefix | deferredPrefix, cmdFn: printVar, helpMsg: `Evaluate an expression.
 
-	[goroutine <n>] [frame <m>] print <expression>
+	[goroutine <n>] [frame <m>] print [-raw] <expression>
+
+See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions.
+
+If the expression is a function or method call, for example 'print err.Error()', the call is injected in the selected goroutine, like the call command does, and the values it returns are printed. Calls are only allowed in the topmost frame.
+
+Values with a type that has a pretty printer, registered by a starlark script with add_pretty_printer, are displayed using the pretty printer unless -raw is specified. See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/starlark.md for how to write pretty printers.
 
-See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions.`},
+Values of some standard library types (time.Time, time.Duration, net.IP, big.Int, big.Float, sync.Mutex, atomic.Value and reflect.Value) are displayed by built-in formatters, -raw also displays them without formatting. The built-in formatters can be disabled with the disable-builtin-formatters option of the configuration file.`},
 		{aliases: []string{"whatis"}, group: dataCmds, cmdFn: whatisCommand, helpMsg: `Prints type of an expression.
 
 	whatis <expression>`},
@@ -224,12 +251,25 @@ See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a descri
 
 	[goroutine <n>] [frame <m>] set <variable> = <value>
 
-See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions. Only numerical variables and pointers can be changed.`},
+See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions.
+
+Numerical variables, pointers, strings, slices, maps, structs and interfaces can be changed, as well as the elements of maps. Assignments that need to allocate memory in the target (string and composite literals, non-pointer values assigned to an interface, new map keys) call functions of the runtime and must be executed with the call command:
+
+	call m["key"] = main.T{X: 1}`},
+		{aliases: []string{"dump"}, cmdFn: dump, helpMsg: `Creates a core dump from the current process state
+
+	dump <output file>
+
+The core dump is written in the ELF format read by 'dlv core'. Every readable memory mapping of the target is included, for programs with a large heap the dump can take a long time, it can be canceled by pressing Ctrl-C. The target will not be resumed until the dump is finished.
+
+Only supported on linux's native backend, on amd64 and arm64.`},
 		{aliases: []string{"sources"}, cmdFn: sources, helpMsg: `Print list of source files.
 
-	sources [<regex>]
+	sources [-stale] [<regex>]
 
-If regex is specified only the source files matching it will be returned.`},
+If regex is specified only the source files matching it will be returned.
+
+With -stale only the source files that changed after the executable was built are listed. Files are compared using the size and MD5 checksum recorded by the compiler, when available, otherwise their modification time is compared with the one of the executable. The same check is done by 'list' and 'break', which print a warning when the file they refer to is stale.`},
 		{aliases: []string{"funcs"}, cmdFn: funcs, helpMsg: `Print list of functions.
 
 	funcs [<regex>]
@@ -376,6 +416,10 @@ Changes the value of a configuration parameter.
 
 Adds or removes a path substitution rule.
 
+	config substitute-path -infer
+
+Adds path substitution rules for the source files of the target that can not be found locally, by matching their paths with files in the local module root (found through go.mod), GOROOT, the module cache and GOPATH. Existing rules for the same directory are kept. The rules that would be added are printed at startup when dlv is started with --infer-substitute-path.
+
 	config alias <command> <alias>
 	config alias <alias>
 
@@ -667,7 +711,14 @@ func printGoroutines(t *Term, gs []*api.Goroutine, fgl formatGoroutineLoc, flags
 		if state.SelectedGoroutine != nil && g.ID == state.SelectedGoroutine.ID {
 			prefix = "* "
 		}
-		fmt.Printf("%sGoroutine %s\n", prefix, formatGoroutine(g, fgl))
+		var extra string
+		if desc := g.WaitDescription(); desc != "" {
+			extra += " [" + desc + "]"
+		}
+		if g.Frozen {
+			extra += " [frozen]"
+		}
+		fmt.Printf("%sGoroutine %s%s\n", prefix, formatGoroutine(g, fgl), extra)
 		if flags&printGoroutinesLabels != 0 {
 			writeGoroutineLabels(os.Stdout, g, "\t")
 		}
@@ -687,6 +738,19 @@ func goroutines(t *Term, ctx callContext, argstr string) error {
 	var fgl = fglUserCurrent
 	var flags printGoroutinesFlags
 
+	if args[0] == "-pprof" {
+		if len(args) != 2 || args[1] == "" {
+			return errors.New("-pprof requires a file name")
+		}
+		return goroutinesProfile(t, args[1])
+	}
+	if args[0] == "-tree" {
+		if len(args) != 1 {
+			return errors.New("too many arguments")
+		}
+		return goroutinesTree(t)
+	}
+
 	switch len(args) {
 	case 0:
 		// nothing to do
@@ -739,6 +803,128 @@ func goroutines(t *Term, ctx callContext, argstr string) error {
 	return nil
 }
 
+func goroutinesProfile(t *Term, path string) error {
+	buf, err := t.client.GoroutineProfile()
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, buf, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Goroutine profile written to %s\n", path)
+	return nil
+}
+
+func goroutinesTree(t *Term) error {
+	var gs []*api.Goroutine
+	for start := 0; start >= 0; {
+		var batch []*api.Goroutine
+		var err error
+		batch, start, err = t.client.ListGoroutines(start, goroutineBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, g := range batch {
+			if g.Unreadable == "" {
+				gs = append(gs, g)
+			}
+		}
+	}
+
+	// Recent versions of the runtime record the parent of each goroutine,
+	// on older versions it is only known if the target was started with
+	// GODEBUG=tracebackancestors=N.
+	parents := make(map[int]int)
+	for _, g := range gs {
+		if g.ParentID != 0 {
+			parents[g.ID] = g.ParentID
+		}
+	}
+
+	printGoroutineTree(os.Stdout, buildGoroutineTree(gs, parents), "")
+	fmt.Printf("[%d goroutines]\n", len(gs))
+	return nil
+}
+
+func dump(t *Term, ctx callContext, args string) error {
+	if args == "" {
+		return errors.New("not enough arguments")
+	}
+	dumpState, err := t.client.DumpStart(args)
+	if err != nil {
+		return err
+	}
+	t.longCommandStart()
+	defer t.longCommandEnd()
+	canceled := false
+	for {
+		if dumpState.ThreadsDone != dumpState.ThreadsTotal {
+			fmt.Printf("\rDumping threads %d / %d...", dumpState.ThreadsDone, dumpState.ThreadsTotal)
+		} else {
+			fmt.Printf("\rDumping memory %d / %d...", dumpState.MemDone, dumpState.MemTotal)
+		}
+		if dumpState.AllDone {
+			fmt.Printf("\n")
+			if dumpState.Err != "" {
+				fmt.Printf("error dumping: %s\n", dumpState.Err)
+			} else {
+				fmt.Printf("Core dump written to %s\n", args)
+			}
+			return nil
+		}
+		if !canceled && t.longCommandIsCanceled() {
+			if err := t.client.DumpCancel(); err != nil {
+				return err
+			}
+			canceled = true
+		}
+		dumpState, err = t.client.DumpWait(1000)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// scopeGID returns the ID of the goroutine specified by the goroutine
+// prefix of the command, or the ID of the selected goroutine.
+func scopeGID(t *Term, ctx callContext) (int, error) {
+	if ctx.Scope.GoroutineID > 0 {
+		return ctx.Scope.GoroutineID, nil
+	}
+	state, err := t.client.GetState()
+	if err != nil {
+		return 0, err
+	}
+	if state.SelectedGoroutine == nil {
+		return 0, errors.New("no selected goroutine")
+	}
+	return state.SelectedGoroutine.ID, nil
+}
+
+func freeze(t *Term, ctx callContext, argstr string) error {
+	gid, err := scopeGID(t, ctx)
+	if err != nil {
+		return err
+	}
+	if err := t.client.FreezeGoroutine(gid); err != nil {
+		return err
+	}
+	fmt.Printf("Goroutine %d frozen\n", gid)
+	return nil
+}
+
+func thaw(t *Term, ctx callContext, argstr string) error {
+	gid, err := scopeGID(t, ctx)
+	if err != nil {
+		return err
+	}
+	if err := t.client.ThawGoroutine(gid); err != nil {
+		return err
+	}
+	fmt.Printf("Goroutine %d thawed\n", gid)
+	return nil
+}
+
 func selectedGID(state *api.DebuggerState) int {
 	if state.SelectedGoroutine == nil {
 		return 0
@@ -1414,21 +1600,25 @@ func breakpoints(t *Term, ctx callContext, args string) error {
 			attrs = append(attrs, "\tgoroutine")
 		}
 		if bp.LoadArgs != nil {
-			if *(bp.LoadArgs) == longLoadConfig {
+			if *(bp.LoadArgs) == t.loadConfig() {
 				attrs = append(attrs, "\targs -v")
 			} else {
 				attrs = append(attrs, "\targs")
 			}
 		}
 		if bp.LoadLocals != nil {
-			if *(bp.LoadLocals) == longLoadConfig {
+			if *(bp.LoadLocals) == t.loadConfig() {
 				attrs = append(attrs, "\tlocals -v")
 			} else {
 				attrs = append(attrs, "\tlocals")
 			}
 		}
 		for i := range bp.Variables {
-			attrs = append(attrs, fmt.Sprintf("\tprint %s", bp.Variables[i]))
+			if t.rawBreakpointVariables[bp.ID][bp.Variables[i]] {
+				attrs = append(attrs, fmt.Sprintf("\tprint -raw %s", bp.Variables[i]))
+			} else {
+				attrs = append(attrs, fmt.Sprintf("\tprint %s", bp.Variables[i]))
+			}
 		}
 		if len(attrs) > 0 {
 			fmt.Printf("%s\n", strings.Join(attrs, "\n"))
@@ -1470,11 +1660,13 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) err
 			return err
 		}
 	}
+	staleChecked := map[string]bool{}
 	for _, loc := range locs {
 		requestedBp.Addr = loc.PC
 		requestedBp.Addrs = loc.PCs
 		if tracepoint {
-			requestedBp.LoadArgs = &ShortLoadConfig
+			cfg := t.shortLoadConfig()
+			requestedBp.LoadArgs = &cfg
 		}
 
 		bp, err := t.client.CreateBreakpoint(requestedBp)
@@ -1483,6 +1675,12 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) err
 		}
 
 		fmt.Printf("%s set at %s\n", formatBreakpointName(bp, true), formatBreakpointLocation(bp))
+		if bp.File != "" && !staleChecked[bp.File] {
+			staleChecked[bp.File] = true
+			if reason := t.staleSource(t.sourceFileInfo(bp.File), t.client.LastModified()); reason != "" {
+				fmt.Printf("Warning: breakpoint may be on the wrong line: %s %s\n", bp.File, reason)
+			}
+		}
 	}
 
 	var shouldSetReturnBreakpoints bool
@@ -1506,11 +1704,12 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) err
 				return err
 			}
 			for j := range addrs {
+				cfg := t.shortLoadConfig()
 				_, err = t.client.CreateBreakpoint(&api.Breakpoint{
 					Addr:        addrs[j],
 					TraceReturn: true,
 					Line:        -1,
-					LoadArgs:    &ShortLoadConfig,
+					LoadArgs:    &cfg,
 				})
 				if err != nil {
 					return err
@@ -1639,19 +1838,80 @@ func printVar(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("not enough arguments")
 	}
+	const rawPrefix = "-raw "
+	raw := false
+	if strings.HasPrefix(args, rawPrefix) {
+		raw = true
+		args = strings.TrimSpace(args[len(rawPrefix):])
+	}
 	if ctx.Prefix == onPrefix {
 		ctx.Breakpoint.Variables = append(ctx.Breakpoint.Variables, args)
+		if raw {
+			if t.rawBreakpointVariables == nil {
+				t.rawBreakpointVariables = make(map[int]map[string]bool)
+			}
+			if t.rawBreakpointVariables[ctx.Breakpoint.ID] == nil {
+				t.rawBreakpointVariables[ctx.Breakpoint.ID] = make(map[string]bool)
+			}
+			t.rawBreakpointVariables[ctx.Breakpoint.ID][args] = true
+		}
 		return nil
 	}
 	val, err := t.client.EvalVariable(ctx.Scope, args, t.loadConfig())
 	if err != nil {
+		if isCallExpr(args) && ctx.Scope.Frame == 0 && ctx.Scope.DeferredCall == 0 && strings.Contains(err.Error(), "not allowed without using 'call'") {
+			return printCall(t, ctx, args, raw)
+		}
 		return err
 	}
 
+	if raw {
+		val.ClearPretty()
+	} else {
+		val = t.starlarkEnv.PrettyPrint(val)
+	}
 	fmt.Println(val.MultilineString(""))
 	return nil
 }
 
+func isCallExpr(expr string) bool {
+	t, err := parser.ParseExpr(expr)
+	if err != nil {
+		return false
+	}
+	_, iscall := t.(*ast.CallExpr)
+	return iscall
+}
+
+// printCall prints the values returned by expr, a call expression, by
+// injecting a function call in the selected goroutine. If the call is
+// interrupted by a breakpoint, or doesn't return any value, it behaves
+// like the call command.
+func printCall(t *Term, ctx callContext, expr string, raw bool) error {
+	if err := scopePrefixSwitch(t, ctx); err != nil {
+		return err
+	}
+	state, err := exitedToError(t.client.Call(ctx.Scope.GoroutineID, expr, false))
+	if err != nil {
+		printcontextNoState(t)
+		return err
+	}
+	if state.CurrentThread == nil || state.CurrentThread.ReturnValues == nil {
+		printcontext(t, state)
+		return continueUntilCompleteNext(t, state, "call", true)
+	}
+	for i := range state.CurrentThread.ReturnValues {
+		val := &state.CurrentThread.ReturnValues[i]
+		if raw {
+			val.ClearPretty()
+		} else {
+			val = t.starlarkEnv.PrettyPrint(val)
+		}
+		fmt.Println(val.MultilineString(""))
+	}
+	return nil
+}
+
 func whatisCommand(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("not enough arguments")
@@ -1692,7 +1952,7 @@ func setVar(t *Term, ctx callContext, args string) error {
 	return t.client.SetVariable(ctx.Scope, lexpr, rexpr)
 }
 
-func printFilteredVariables(varType string, vars []api.Variable, filter string, cfg api.LoadConfig) error {
+func (t *Term) printFilteredVariables(varType string, vars []api.Variable, filter string, cfg api.LoadConfig) error {
 	reg, err := regexp.Compile(filter)
 	if err != nil {
 		return err
@@ -1705,10 +1965,11 @@ func printFilteredVariables(varType string, vars []api.Variable, filter string,
 			if v.Flags&api.VariableShadowed != 0 {
 				name = "(" + name + ")"
 			}
-			if cfg == ShortLoadConfig {
-				fmt.Printf("%s = %s\n", name, v.SinglelineString())
+			pv := t.starlarkEnv.PrettyPrint(&v)
+			if cfg == t.shortLoadConfig() {
+				fmt.Printf("%s = %s\n", name, pv.SinglelineString())
 			} else {
-				fmt.Printf("%s = %s\n", name, v.MultilineString(""))
+				fmt.Printf("%s = %s\n", name, pv.MultilineString(""))
 			}
 		}
 	}
@@ -1730,7 +1991,21 @@ func printSortedStrings(v []string, err error) error {
 }
 
 func sources(t *Term, ctx callContext, args string) error {
-	return printSortedStrings(t.client.ListSources(args))
+	if args != "-stale" && !strings.HasPrefix(args, "-stale ") {
+		return printSortedStrings(t.client.ListSources(args))
+	}
+	files, err := t.client.ListSourcesInfo(strings.TrimSpace(args[len("-stale"):]))
+	if err != nil {
+		return err
+	}
+	lastModExe := t.client.LastModified()
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	for _, file := range files {
+		if reason := t.staleSource(file, lastModExe); reason != "" {
+			fmt.Printf("%s %s\n", file.Path, reason)
+		}
+	}
+	return nil
 }
 
 func funcs(t *Term, ctx callContext, args string) error {
@@ -1749,7 +2024,7 @@ func parseVarArguments(args string, t *Term) (filter string, cfg api.LoadConfig)
 			return "", t.loadConfig()
 		}
 	}
-	return args, ShortLoadConfig
+	return args, t.shortLoadConfig()
 }
 
 func args(t *Term, ctx callContext, args string) error {
@@ -1765,7 +2040,7 @@ func args(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("args", vars, filter, cfg)
+	return t.printFilteredVariables("args", vars, filter, cfg)
 }
 
 func locals(t *Term, ctx callContext, args string) error {
@@ -1781,7 +2056,7 @@ func locals(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("locals", locals, filter, cfg)
+	return t.printFilteredVariables("locals", locals, filter, cfg)
 }
 
 func vars(t *Term, ctx callContext, args string) error {
@@ -1790,7 +2065,7 @@ func vars(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("vars", vars, filter, cfg)
+	return t.printFilteredVariables("vars", vars, filter, cfg)
 }
 
 func regs(t *Term, ctx callContext, args string) error {
@@ -1823,7 +2098,8 @@ func stackCommand(t *Term, ctx callContext, args string) error {
 	}
 	var cfg *api.LoadConfig
 	if sa.full {
-		cfg = &ShortLoadConfig
+		shortCfg := t.shortLoadConfig()
+		cfg = &shortCfg
 	}
 	stack, err := t.client.Stacktrace(ctx.Scope.GoroutineID, sa.depth, sa.opts, cfg)
 	if err != nil {
@@ -2089,6 +2365,10 @@ func libraries(t *Term, ctx callContext, args string) error {
 	}
 	d := digits(len(libs))
 	for i := range libs {
+		if libs[i].PluginPath != "" {
+			fmt.Printf("%"+strconv.Itoa(d)+"d. %#x %s (plugin %s)\n", i, libs[i].Address, libs[i].Path, libs[i].PluginPath)
+			continue
+		}
 		fmt.Printf("%"+strconv.Itoa(d)+"d. %#x %s\n", i, libs[i].Address, libs[i].Path)
 	}
 	return nil
@@ -2125,7 +2405,11 @@ func printStack(out io.Writer, stack []api.Stackframe, ind string, offsets bool)
 			fmt.Fprintf(out, "%serror: %s\n", s, stack[i].Err)
 			continue
 		}
-		fmt.Fprintf(out, fmtstr, ind, i, stack[i].PC, stack[i].Function.Name())
+		fname := stack[i].Function.Name()
+		if stack[i].Inlined {
+			fname += " (inlined)"
+		}
+		fmt.Fprintf(out, fmtstr, ind, i, stack[i].PC, fname)
 		fmt.Fprintf(out, "%sat %s:%d\n", s, shortenFilePath(stack[i].File), stack[i].Line)
 
 		if offsets {
@@ -2234,7 +2518,7 @@ func printcontextThread(t *Term, th *api.Thread) {
 
 	args := ""
 	var hasReturnValue bool
-	if th.BreakpointInfo != nil && th.Breakpoint.LoadArgs != nil && *th.Breakpoint.LoadArgs == ShortLoadConfig {
+	if th.BreakpointInfo != nil && th.Breakpoint.LoadArgs != nil && *th.Breakpoint.LoadArgs == t.shortLoadConfig() {
 		var arg []string
 		for _, ar := range th.BreakpointInfo.Arguments {
 			// For AI compatibility return values are included in the
@@ -2258,7 +2542,7 @@ func printcontextThread(t *Term, th *api.Thread) {
 	}
 
 	if th.Breakpoint.Tracepoint || th.Breakpoint.TraceReturn {
-		printTracepoint(th, bpname, fn, args, hasReturnValue)
+		printTracepoint(t, th, bpname, fn, args, hasReturnValue)
 		return
 	}
 
@@ -2288,10 +2572,10 @@ func printcontextThread(t *Term, th *api.Thread) {
 	}
 
 	printReturnValues(th)
-	printBreakpointInfo(th, false)
+	printBreakpointInfo(t, th, false)
 }
 
-func printBreakpointInfo(th *api.Thread, tracepointOnNewline bool) {
+func printBreakpointInfo(t *Term, th *api.Thread, tracepointOnNewline bool) {
 	if th.BreakpointInfo == nil {
 		return
 	}
@@ -2316,21 +2600,31 @@ func printBreakpointInfo(th *api.Thread, tracepointOnNewline bool) {
 		writeGoroutineLong(os.Stdout, bpi.Goroutine, "\t")
 	}
 
-	for _, v := range bpi.Variables {
+	for i := range bpi.Variables {
 		tracepointnl()
+		v := &bpi.Variables[i]
+		raw := t.rawBreakpointVariables[bp.ID][v.Name]
+		if raw || t.loadConfig().DisableBuiltinFormatters {
+			// breakpoint variables are loaded by the server without a
+			// LoadConfig from the client
+			v.ClearPretty()
+		}
+		if !raw {
+			v = t.starlarkEnv.PrettyPrint(v)
+		}
 		fmt.Printf("\t%s: %s\n", v.Name, v.MultilineString("\t"))
 	}
 
 	for _, v := range bpi.Locals {
 		tracepointnl()
-		if *bp.LoadLocals == longLoadConfig {
+		if *bp.LoadLocals == t.loadConfig() {
 			fmt.Printf("\t%s: %s\n", v.Name, v.MultilineString("\t"))
 		} else {
 			fmt.Printf("\t%s: %s\n", v.Name, v.SinglelineString())
 		}
 	}
 
-	if bp.LoadArgs != nil && *bp.LoadArgs == longLoadConfig {
+	if bp.LoadArgs != nil && *bp.LoadArgs == t.loadConfig() {
 		for _, v := range bpi.Arguments {
 			tracepointnl()
 			fmt.Printf("\t%s: %s\n", v.Name, v.MultilineString("\t"))
@@ -2344,13 +2638,13 @@ func printBreakpointInfo(th *api.Thread, tracepointOnNewline bool) {
 	}
 }
 
-func printTracepoint(th *api.Thread, bpname string, fn *api.Function, args string, hasReturnValue bool) {
+func printTracepoint(t *Term, th *api.Thread, bpname string, fn *api.Function, args string, hasReturnValue bool) {
 	if th.Breakpoint.Tracepoint {
 		fmt.Fprintf(os.Stderr, "> goroutine(%d): %s%s(%s)", th.GoroutineID, bpname, fn.Name(), args)
 		if !hasReturnValue {
 			fmt.Println()
 		}
-		printBreakpointInfo(th, !hasReturnValue)
+		printBreakpointInfo(t, th, !hasReturnValue)
 	}
 	if th.Breakpoint.TraceReturn {
 		retVals := make([]string, 0, len(th.ReturnValues))
@@ -2371,21 +2665,27 @@ func printfile(t *Term, filename string, line int, showArrow bool) error {
 	if filename == "" {
 		return nil
 	}
+	var src io.Reader
 	file, err := os.Open(t.substitutePath(filename))
 	if err != nil {
-		return err
-	}
-	defer file.Close()
+		// The file could be available on a debuginfod server.
+		contents, ferr := t.client.FetchSource(filename)
+		if ferr != nil {
+			return err
+		}
+		src = bytes.NewReader(contents)
+	} else {
+		defer file.Close()
 
-	fi, _ := file.Stat()
-	lastModExe := t.client.LastModified()
-	if fi.ModTime().After(lastModExe) {
-		fmt.Println("Warning: listing may not match stale executable")
+		if reason := t.staleSource(t.sourceFileInfo(filename), t.client.LastModified()); reason != "" {
+			fmt.Printf("Warning: listing may not match stale executable: %s %s\n", filename, reason)
+		}
+		src = file
 	}
 
 	lineCount := t.conf.GetSourceListLineCount()
 
-	buf := bufio.NewScanner(file)
+	buf := bufio.NewScanner(src)
 	l := line
 	for i := 1; i < l-lineCount; i++ {
 		if !buf.Scan() {
@@ -2417,6 +2717,53 @@ func printfile(t *Term, filename string, line int, showArrow bool) error {
 	return nil
 }
 
+// sourceFileInfo returns the size and checksum that filename had when the
+// executable was built, as far as the debug information records them.
+func (t *Term) sourceFileInfo(filename string) api.SourceFile {
+	files, err := t.client.ListSourcesInfo("^" + regexp.QuoteMeta(filename) + "$")
+	if err != nil || len(files) == 0 {
+		return api.SourceFile{Path: filename}
+	}
+	return files[0]
+}
+
+// staleSource compares the local copy of file with the size and checksum
+// recorded when the executable was built, falling back to comparing its
+// modification time with the one of the executable. It returns a
+// description of the difference or the empty string if the file matches,
+// or can not be read.
+func (t *Term) staleSource(file api.SourceFile, lastModExe time.Time) string {
+	fh, err := os.Open(t.substitutePath(file.Path))
+	if err != nil {
+		return ""
+	}
+	defer fh.Close()
+	fi, err := fh.Stat()
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case file.MD5 != "":
+		h := md5.New()
+		if _, err := io.Copy(h, fh); err != nil {
+			return ""
+		}
+		if hex.EncodeToString(h.Sum(nil)) != file.MD5 {
+			return "has changed since the executable was built (MD5 mismatch)"
+		}
+	case file.Size != 0:
+		if uint64(fi.Size()) != file.Size {
+			return fmt.Sprintf("has changed since the executable was built (size is %d bytes, was %d)", fi.Size(), file.Size)
+		}
+	default:
+		if fi.ModTime().After(lastModExe) {
+			return "was modified after the executable was built"
+		}
+	}
+	return ""
+}
+
 // ExitRequestError is returned when the user
 // exits Delve.
 type ExitRequestError struct{}
@@ -1,6 +1,7 @@
 package terminal
 
 import (
+	"crypto/md5"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -1081,6 +1082,21 @@ func TestIssue1598(t *testing.T) {
 	})
 }
 
+func TestPrintCall(t *testing.T) {
+	if runtime.GOARCH == "arm64" || runtime.GOARCH == "386" {
+		t.Skip(fmt.Errorf("%s does not support FunctionCall for now", runtime.GOARCH))
+	}
+	test.MustSupportFunctionCalls(t, testBackend)
+	withTestTerminal("fncall", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		r := term.MustExec("print err.Error()")
+		t.Logf("result %q", r)
+		if strings.TrimSpace(r) != `"some error"` {
+			t.Fatalf("wrong value returned: %q", r)
+		}
+	})
+}
+
 func TestExamineMemoryCmd(t *testing.T) {
 	withTestTerminal("examinememory", t, func(term *FakeTerminal) {
 		term.MustExec("break examinememory.go:19")
@@ -1185,3 +1201,69 @@ func TestParseNewArgv(t *testing.T) {
 		}
 	}
 }
+
+func TestGoroutineTree(t *testing.T) {
+	loc := func(pc uint64, fn string) api.Location {
+		return api.Location{PC: pc, File: "/tmp/main.go", Line: int(pc), Function: &api.Function{Name_: fn}}
+	}
+	gs := []*api.Goroutine{
+		{ID: 1, GoStatementLoc: loc(1, "runtime.main")},
+		{ID: 2, GoStatementLoc: loc(10, "main.main")},
+		{ID: 3, GoStatementLoc: loc(10, "main.main")},
+		{ID: 4, GoStatementLoc: loc(20, "main.worker")},
+		{ID: 5, GoStatementLoc: loc(20, "main.worker")},
+		{ID: 6, GoStatementLoc: loc(20, "main.worker")},
+		{ID: 7, GoStatementLoc: loc(30, "main.orphan")},
+	}
+	parents := map[int]int{2: 1, 3: 1, 4: 2, 5: 2, 6: 3, 7: 100}
+
+	var buf strings.Builder
+	printGoroutineTree(&buf, buildGoroutineTree(gs, parents), "")
+	tgt := `1 created at /tmp/main.go:1 runtime.main (0x1) [1] (6 total)
+  2 created at /tmp/main.go:10 main.main (0xa) [2 3] (5 total)
+    3 created at /tmp/main.go:20 main.worker (0x14) [4 5 6]
+1 created at /tmp/main.go:30 main.orphan (0x1e) [7]
+`
+	if out := buf.String(); out != tgt {
+		t.Fatalf("wrong output:\n%s\nexpected:\n%s", out, tgt)
+	}
+}
+
+func TestStaleSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stalesource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "main.go")
+	contents := []byte("package main\n\nfunc main() {\n}\n")
+	if err := ioutil.WriteFile(path, contents, 0600); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	term := &Term{}
+	const md5sum = "1c0b5baa7db33ed3a3c3a2e1bae54f14"
+
+	for _, tc := range []struct {
+		file       api.SourceFile
+		lastModExe time.Time
+		stale      bool
+	}{
+		{api.SourceFile{Path: path}, fi.ModTime().Add(time.Second), false},
+		{api.SourceFile{Path: path}, fi.ModTime().Add(-time.Second), true},
+		{api.SourceFile{Path: path, Size: uint64(len(contents))}, fi.ModTime().Add(-time.Second), false},
+		{api.SourceFile{Path: path, Size: uint64(len(contents)) + 1}, fi.ModTime().Add(time.Second), true},
+		{api.SourceFile{Path: path, Size: uint64(len(contents)), MD5: fmt.Sprintf("%x", md5.Sum(contents))}, fi.ModTime().Add(-time.Second), false},
+		{api.SourceFile{Path: path, Size: uint64(len(contents)), MD5: md5sum}, fi.ModTime().Add(time.Second), true},
+		{api.SourceFile{Path: filepath.Join(dir, "missing.go")}, fi.ModTime().Add(-time.Second), false},
+	} {
+		reason := term.staleSource(tc.file, tc.lastModExe)
+		if (reason != "") != tc.stale {
+			t.Errorf("staleSource(%#v, %v): got %q", tc.file, tc.lastModExe, reason)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package terminal
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -157,6 +160,9 @@ func configureSetSubstitutePath(t *Term, rest string) error {
 	argv := config.SplitQuotedFields(rest, '"')
 	switch len(argv) {
 	case 1: // delete substitute-path rule
+		if argv[0] == "-infer" {
+			return t.applyInferredSubstitutePath()
+		}
 		for i := range t.conf.SubstitutePath {
 			if t.conf.SubstitutePath[i].From == argv[0] {
 				copy(t.conf.SubstitutePath[i:], t.conf.SubstitutePath[i+1:])
@@ -179,6 +185,84 @@ func configureSetSubstitutePath(t *Term, rest string) error {
 	return nil
 }
 
+// inferSubstitutePath returns the substitute-path rules proposed by
+// config.InferSubstitutePath for the source files of the target that can
+// not be found using the current rules.
+func (t *Term) inferSubstitutePath() (config.SubstitutePathRules, error) {
+	files, err := t.client.ListSources("")
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, file := range files {
+		if _, err := os.Stat(t.substitutePath(file)); err != nil {
+			missing = append(missing, file)
+		}
+	}
+	return config.InferSubstitutePath(missing, localSourceRoots(), t.conf.SubstitutePath), nil
+}
+
+// applyInferredSubstitutePath adds the rules returned by
+// inferSubstitutePath to the configuration.
+func (t *Term) applyInferredSubstitutePath() error {
+	rules, err := t.inferSubstitutePath()
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		fmt.Println("No substitute-path rules inferred")
+		return nil
+	}
+	for _, rule := range rules {
+		fmt.Printf("Adding substitute-path rule %q -> %q\n", rule.From, rule.To)
+		t.conf.SubstitutePath = append(t.conf.SubstitutePath, rule)
+	}
+	return nil
+}
+
+// localSourceRoots returns the local directories that can contain the
+// source files of the target: the root of the module containing the
+// current directory, GOROOT, the module cache and the src directory of
+// each GOPATH entry.
+func localSourceRoots() []string {
+	var roots []string
+	if wd, err := os.Getwd(); err == nil {
+		for dir := wd; ; dir = filepath.Dir(dir) {
+			if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+				roots = append(roots, dir)
+				break
+			}
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+
+	goroot, gomodcache, gopath := runtime.GOROOT(), "", ""
+	if out, err := exec.Command("go", "env", "GOROOT", "GOMODCACHE", "GOPATH").Output(); err == nil {
+		v := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+		if len(v) == 3 {
+			goroot, gomodcache, gopath = v[0], v[1], v[2]
+		}
+	}
+	if goroot != "" {
+		roots = append(roots, goroot)
+	}
+	if gomodcache != "" {
+		roots = append(roots, gomodcache)
+	}
+	for _, dir := range filepath.SplitList(gopath) {
+		if dir == "" {
+			continue
+		}
+		if gomodcache == "" {
+			roots = append(roots, filepath.Join(dir, "pkg", "mod"))
+		}
+		roots = append(roots, filepath.Join(dir, "src"))
+	}
+	return roots
+}
+
 func configureSetAlias(t *Term, rest string) error {
 	argv := config.SplitQuotedFields(rest, '"')
 	switch len(argv) {
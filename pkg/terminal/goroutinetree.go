@@ -0,0 +1,111 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// maxTreeNodeGoroutines is the maximum number of goroutine IDs listed for
+// each node of the goroutine creation tree.
+const maxTreeNodeGoroutines = 5
+
+// goroutineTreeNode groups the goroutines created by the same go
+// statement, whose parent goroutines belong to the same parent node.
+type goroutineTreeNode struct {
+	loc      api.Location // location of the go statement
+	gids     []int
+	total    int // number of goroutines in this node and in its descendants
+	children map[uint64]*goroutineTreeNode
+}
+
+func newGoroutineTreeNode(loc api.Location) *goroutineTreeNode {
+	return &goroutineTreeNode{loc: loc, children: make(map[uint64]*goroutineTreeNode)}
+}
+
+// buildGoroutineTree returns the creation tree of gs. The parent of each
+// goroutine is looked up in parents, goroutines whose parent is unknown or
+// has exited are children of the returned root node.
+func buildGoroutineTree(gs []*api.Goroutine, parents map[int]int) *goroutineTreeNode {
+	byID := make(map[int]*api.Goroutine, len(gs))
+	for _, g := range gs {
+		byID[g.ID] = g
+	}
+
+	root := newGoroutineTreeNode(api.Location{})
+	for _, g := range gs {
+		// Collect the chain of go statements that lead to g, outermost first.
+		chain := []*api.Goroutine{g}
+		seen := map[int]bool{g.ID: true}
+		for cur := g; ; {
+			parent := byID[parents[cur.ID]]
+			if parent == nil || seen[parent.ID] {
+				break
+			}
+			seen[parent.ID] = true
+			chain = append(chain, parent)
+			cur = parent
+		}
+
+		node := root
+		node.total++
+		for i := len(chain) - 1; i >= 0; i-- {
+			loc := chain[i].GoStatementLoc
+			child := node.children[loc.PC]
+			if child == nil {
+				child = newGoroutineTreeNode(loc)
+				node.children[loc.PC] = child
+			}
+			child.total++
+			node = child
+		}
+		node.gids = append(node.gids, g.ID)
+	}
+	return root
+}
+
+// sortedChildren returns the children of node, the ones containing the
+// most goroutines first.
+func (node *goroutineTreeNode) sortedChildren() []*goroutineTreeNode {
+	r := make([]*goroutineTreeNode, 0, len(node.children))
+	for _, child := range node.children {
+		r = append(r, child)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].total != r[j].total {
+			return r[i].total > r[j].total
+		}
+		return r[i].loc.PC < r[j].loc.PC
+	})
+	return r
+}
+
+func printGoroutineTree(w io.Writer, node *goroutineTreeNode, indent string) {
+	for _, child := range node.sortedChildren() {
+		sort.Ints(child.gids)
+		ids := make([]string, 0, maxTreeNodeGoroutines+1)
+		for i, gid := range child.gids {
+			if i >= maxTreeNodeGoroutines {
+				ids = append(ids, "...")
+				break
+			}
+			ids = append(ids, fmt.Sprint(gid))
+		}
+		site := "<unknown>"
+		if child.loc.PC != 0 {
+			site = formatLocation(child.loc)
+		}
+		fmt.Fprintf(w, "%s%d created at %s", indent, len(child.gids), site)
+		if len(ids) > 0 {
+			fmt.Fprintf(w, " [%s]", strings.Join(ids, " "))
+		}
+		if child.total != len(child.gids) {
+			fmt.Fprintf(w, " (%d total)", child.total)
+		}
+		fmt.Fprintln(w)
+		printGoroutineTree(w, child, indent+"  ")
+	}
+}
@@ -245,15 +245,25 @@ func (env *Env) variableValueToStarlarkValue(v *api.Variable, top bool) (starlar
 		}
 	case reflect.Ptr, reflect.Interface:
 		if len(v.Children) > 0 {
-			v.Children[0] = *env.autoLoad(varAddrExpr(&v.Children[0]))
+			v.Children[0] = *env.autoLoad(varAddrExpr(&v.Children[0]), &v.Children[0])
 		}
 		return ptrVariableAsStarlarkValue{v, env}, nil
 	}
 	return nil, nil
 }
 
-func (env *Env) autoLoad(expr string) *api.Variable {
-	v, err := env.ctx.Client().EvalVariable(api.EvalScope{GoroutineID: -1}, expr, autoLoadConfig)
+// autoLoad evaluates expr to load more of a variable. Loaded is the part
+// of the variable that was already loaded, if any, it's returned as is
+// when there is no client to evaluate expr with.
+func (env *Env) autoLoad(expr string, loaded *api.Variable) *api.Variable {
+	client := env.ctx.Client()
+	if client == nil {
+		if loaded == nil {
+			return &api.Variable{Unreadable: "value not loaded"}
+		}
+		return loaded
+	}
+	v, err := client.EvalVariable(api.EvalScope{GoroutineID: -1}, expr, autoLoadConfig)
 	if err != nil {
 		return &api.Variable{Unreadable: err.Error()}
 	}
@@ -303,7 +313,7 @@ func (v structVariableAsStarlarkValue) Type() string {
 func (v structVariableAsStarlarkValue) Attr(name string) (starlark.Value, error) {
 	for i := range v.v.Children {
 		if v.v.Children[i].Name == name {
-			v2 := v.env.autoLoad(varAddrExpr(&v.v.Children[i]))
+			v2 := v.env.autoLoad(varAddrExpr(&v.v.Children[i]), &v.v.Children[i])
 			return v.env.variableValueToStarlarkValue(v2, false)
 		}
 	}
@@ -364,7 +374,11 @@ func (v sliceVariableAsStarlarkValue) Index(i int) starlark.Value {
 	if i >= v.Len() {
 		return nil
 	}
-	v2 := v.env.autoLoad(fmt.Sprintf("%s[%d]", varAddrExpr(v.v), i))
+	var loaded *api.Variable
+	if i < len(v.v.Children) {
+		loaded = &v.v.Children[i]
+	}
+	v2 := v.env.autoLoad(fmt.Sprintf("%s[%d]", varAddrExpr(v.v), i), loaded)
 	r, err := v.env.variableValueToStarlarkValue(v2, false)
 	if err != nil {
 		return starlark.String(err.Error())
@@ -438,7 +452,7 @@ func (v ptrVariableAsStarlarkValue) Attr(name string) (starlark.Value, error) {
 		// allow double-autodereference for iface to ptr to struct
 		vchild := &v.v.Children[0]
 		if len(vchild.Children) > 0 {
-			vchild.Children[0] = *v.env.autoLoad(varAddrExpr(&vchild.Children[0]))
+			vchild.Children[0] = *v.env.autoLoad(varAddrExpr(&vchild.Children[0]), &vchild.Children[0])
 		}
 		v2 := ptrVariableAsStarlarkValue{vchild, v.env}
 		return v2.Attr(name)
@@ -522,7 +536,7 @@ func (v mapVariableAsStarlarkValue) Get(key starlark.Value) (starlark.Value, boo
 		return starlark.None, false, fmt.Errorf("key type not supported %T", key)
 	}
 
-	v2 := v.env.autoLoad(fmt.Sprintf("%s[%s]", varAddrExpr(v.v), keyExpr))
+	v2 := v.env.autoLoad(fmt.Sprintf("%s[%s]", varAddrExpr(v.v), keyExpr), nil)
 	r, err := v.env.variableValueToStarlarkValue(v2, false)
 	if err != nil {
 		if err.Error() == "key not found" {
@@ -542,12 +556,12 @@ func (v mapVariableAsStarlarkValue) Items() []starlark.Tuple {
 }
 
 func mapStarlarkTupleAt(v *api.Variable, env *Env, i int) starlark.Tuple {
-	keyv := env.autoLoad(varAddrExpr(&v.Children[i]))
+	keyv := env.autoLoad(varAddrExpr(&v.Children[i]), &v.Children[i])
 	key, err := env.variableValueToStarlarkValue(keyv, false)
 	if err != nil {
 		key = starlark.None
 	}
-	valv := env.autoLoad(varAddrExpr(&v.Children[i+1]))
+	valv := env.autoLoad(varAddrExpr(&v.Children[i+1]), &v.Children[i+1])
 	val, err := env.variableValueToStarlarkValue(valv, false)
 	if err != nil {
 		val = starlark.None
@@ -573,14 +587,14 @@ func (it *mapVariableAsStarlarkValueIterator) Next(p *starlark.Value) bool {
 		return false
 	}
 	if it.cur >= len(it.v.Children) {
-		v2 := it.env.autoLoad(fmt.Sprintf("%s[%d:]", varAddrExpr(it.v), len(it.v.Children)/2))
+		v2 := it.env.autoLoad(fmt.Sprintf("%s[%d:]", varAddrExpr(it.v), len(it.v.Children)/2), nil)
 		it.v.Children = append(it.v.Children, v2.Children...)
 	}
 	if it.cur >= len(it.v.Children) {
 		return false
 	}
 
-	keyv := it.env.autoLoad(varAddrExpr(&it.v.Children[it.cur]))
+	keyv := it.env.autoLoad(varAddrExpr(&it.v.Children[it.cur]), &it.v.Children[it.cur])
 	key, err := it.env.variableValueToStarlarkValue(keyv, false)
 	if err != nil {
 		key = starlark.None
@@ -0,0 +1,227 @@
+package starbind
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"go.starlark.net/starlark"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// prettyPrinters is the set of pretty printers registered by scripts with
+// add_pretty_printer.
+type prettyPrinters struct {
+	byType  map[string]starlark.Callable
+	byRegex []regexPrettyPrinter
+}
+
+type regexPrettyPrinter struct {
+	re *regexp.Regexp
+	fn starlark.Callable
+}
+
+func (pps *prettyPrinters) add(typ string, fn starlark.Callable, isRegex bool) error {
+	if !isRegex {
+		if pps.byType == nil {
+			pps.byType = make(map[string]starlark.Callable)
+		}
+		pps.byType[typ] = fn
+		return nil
+	}
+	re, err := regexp.Compile(typ)
+	if err != nil {
+		return err
+	}
+	pps.byRegex = append(pps.byRegex, regexPrettyPrinter{re, fn})
+	return nil
+}
+
+func (pps *prettyPrinters) empty() bool {
+	return len(pps.byType) == 0 && len(pps.byRegex) == 0
+}
+
+// find returns the pretty printer for typ. Printers registered for the
+// exact type name take precedence over the ones registered with a regular
+// expression, which are tried in the order they were registered.
+func (pps *prettyPrinters) find(typ string) starlark.Callable {
+	if fn := pps.byType[typ]; fn != nil {
+		return fn
+	}
+	for _, pp := range pps.byRegex {
+		if pp.re.MatchString(typ) {
+			return pp.fn
+		}
+	}
+	return nil
+}
+
+// PrettyPrint returns a copy of v where v, and each of its children, is
+// replaced by the output of the pretty printer registered for its type, if
+// there is one.
+// A pretty printer is called with the variable as its only argument and
+// returns either a string, which is displayed instead of the value of the
+// variable, the synthetic children of the variable, as a dict or a list,
+// or a tuple containing both.
+func (env *Env) PrettyPrint(v *api.Variable) *api.Variable {
+	if env == nil || env.prettyPrinters.empty() {
+		return v
+	}
+	return env.prettyPrint(v, nil)
+}
+
+func (env *Env) prettyPrint(v, parent *api.Variable) *api.Variable {
+	r := *v
+	// a pretty printer returning the variable it was called with as one of
+	// its children should not be called again on it.
+	if parent == nil || parent.Addr != v.Addr || parent.Type != v.Type {
+		if value, children, ok := env.CallPrettyPrinter(v.Type, v); ok {
+			r.Pretty = value
+			if children != nil {
+				// synthetic children are displayed as if they were the fields
+				// of a struct
+				r.Kind = reflect.Struct
+				r.Children = children
+				r.Len = int64(len(children))
+				r.Cap = 0
+			}
+		}
+	}
+	if len(r.Children) > 0 {
+		children := make([]api.Variable, len(r.Children))
+		for i := range r.Children {
+			children[i] = *env.prettyPrint(&r.Children[i], v)
+		}
+		r.Children = children
+	}
+	return &r
+}
+
+// HasPrettyPrinter returns true if a pretty printer is registered for typ.
+func (env *Env) HasPrettyPrinter(typ string) bool {
+	return env != nil && env.prettyPrinters.find(typ) != nil
+}
+
+// CallPrettyPrinter calls the pretty printer registered for typ, the type
+// of v.
+// It returns the string to display instead of the value of v, which can
+// be empty, and the synthetic children of v, nil if the pretty printer
+// didn't return any.
+// Returns false if there is no pretty printer for typ.
+func (env *Env) CallPrettyPrinter(typ string, v *api.Variable) (value string, children []api.Variable, ok bool) {
+	if env == nil {
+		return "", nil, false
+	}
+	fn := env.prettyPrinters.find(typ)
+	if fn == nil {
+		return "", nil, false
+	}
+	value, children, err := env.callPrettyPrinter(fn, v)
+	if err != nil {
+		return fmt.Sprintf("(pretty printer error: %v)", err), nil, true
+	}
+	return value, children, true
+}
+
+func (env *Env) callPrettyPrinter(fn starlark.Callable, v *api.Variable) (value string, children []api.Variable, err error) {
+	defer func() {
+		if ierr := recover(); ierr != nil {
+			err = fmt.Errorf("%v", ierr)
+		}
+	}()
+
+	ret, err := starlark.Call(env.newThread(), fn, starlark.Tuple{env.interfaceToStarlarkValue(*v)}, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	retValue, retChildren := ret, starlark.Value(starlark.None)
+	if t, ok := ret.(starlark.Tuple); ok {
+		if len(t) != 2 {
+			return "", nil, fmt.Errorf("pretty printer returned a tuple of %d elements, expected (value, children)", len(t))
+		}
+		retValue, retChildren = t[0], t[1]
+	}
+	switch retValue := retValue.(type) {
+	case starlark.String:
+		value = string(retValue)
+	case starlark.NoneType:
+	case *starlark.Dict, *starlark.List:
+		retChildren = retValue
+	default:
+		return "", nil, fmt.Errorf("pretty printer returned a value of type %s", retValue.Type())
+	}
+
+	switch retChildren := retChildren.(type) {
+	case starlark.NoneType:
+	case *starlark.Dict:
+		children = make([]api.Variable, 0, retChildren.Len())
+		for _, item := range retChildren.Items() {
+			name, ok := item[0].(starlark.String)
+			if !ok {
+				return "", nil, fmt.Errorf("name of child is not a string: %s", item[0])
+			}
+			children = append(children, starlarkToVariable(string(name), item[1]))
+		}
+	case *starlark.List:
+		children = make([]api.Variable, 0, retChildren.Len())
+		for i := 0; i < retChildren.Len(); i++ {
+			children = append(children, starlarkToVariable(fmt.Sprintf("[%d]", i), retChildren.Index(i)))
+		}
+	default:
+		return "", nil, fmt.Errorf("children of a pretty printer must be a dict or a list, not %s", retChildren.Type())
+	}
+	return value, children, nil
+}
+
+// starlarkToVariable converts val, a child returned by a pretty printer,
+// into an api.Variable. Variables of the target are returned as they are,
+// other values are converted into constants.
+func starlarkToVariable(name string, val starlark.Value) api.Variable {
+	var r api.Variable
+	switch val := val.(type) {
+	case structAsStarlarkValue:
+		if v, ok := val.v.Interface().(api.Variable); ok {
+			v.Name = name
+			return v
+		}
+		r = stringConstant(val.String())
+	case structVariableAsStarlarkValue:
+		r = *val.v
+		r.Name = name
+		return r
+	case sliceVariableAsStarlarkValue:
+		r = *val.v
+		r.Name = name
+		return r
+	case ptrVariableAsStarlarkValue:
+		r = *val.v
+		r.Name = name
+		return r
+	case mapVariableAsStarlarkValue:
+		r = *val.v
+		r.Name = name
+		return r
+	case starlark.String:
+		r = stringConstant(string(val))
+	case starlark.Int:
+		r = api.Variable{Kind: reflect.Int, Type: "int", Value: val.String()}
+	case starlark.Float:
+		r = api.Variable{Kind: reflect.Float64, Type: "float64", Value: strconv.FormatFloat(float64(val), 'g', -1, 64)}
+	case starlark.Bool:
+		r = api.Variable{Kind: reflect.Bool, Type: "bool", Value: strconv.FormatBool(bool(val))}
+	case starlark.NoneType:
+		r = api.Variable{Kind: reflect.Ptr, Type: "void"}
+	default:
+		r = stringConstant(val.String())
+	}
+	r.Name = name
+	r.Flags |= api.VariableConstant
+	return r
+}
+
+func stringConstant(s string) api.Variable {
+	return api.Variable{Kind: reflect.String, Type: "string", Value: s, Len: int64(len(s))}
+}
@@ -26,6 +26,7 @@ const (
 	dlvContextName               = "dlv_context"
 	curScopeBuiltinName          = "cur_scope"
 	defaultLoadConfigBuiltinName = "default_load_config"
+	addPrettyPrinterBuiltinName  = "add_pretty_printer"
 )
 
 func init() {
@@ -40,6 +41,8 @@ func init() {
 
 // Context is the context in which starlark scripts are evaluated.
 // It contains methods to call API functions, command line commands, etc.
+// If Client returns nil the builtins that call API functions are not
+// defined.
 type Context interface {
 	Client() service.Client
 	RegisterCommand(name, helpMsg string, cmdfn func(args string) error)
@@ -56,6 +59,8 @@ type Env struct {
 	cancelfn  context.CancelFunc
 
 	ctx Context
+
+	prettyPrinters prettyPrinters
 }
 
 // New creates a new starlark binding environment.
@@ -64,7 +69,11 @@ func New(ctx Context) *Env {
 
 	env.ctx = ctx
 
-	env.env = env.starlarkPredeclare()
+	if ctx.Client() != nil {
+		env.env = env.starlarkPredeclare()
+	} else {
+		env.env = starlark.StringDict{}
+	}
 	env.env[dlvCommandBuiltinName] = starlark.NewBuiltin(dlvCommandBuiltinName, func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, err
@@ -114,6 +123,15 @@ func New(ctx Context) *Env {
 	env.env[defaultLoadConfigBuiltinName] = starlark.NewBuiltin(defaultLoadConfigBuiltinName, func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		return env.interfaceToStarlarkValue(env.ctx.LoadConfig()), nil
 	})
+	env.env[addPrettyPrinterBuiltinName] = starlark.NewBuiltin(addPrettyPrinterBuiltinName, func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var typ string
+		var fn starlark.Callable
+		var isRegex bool
+		if err := starlark.UnpackArgs(addPrettyPrinterBuiltinName, args, kwargs, "type", &typ, "fn", &fn, "regex?", &isRegex); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		return starlark.None, decorateError(thread, env.prettyPrinters.add(typ, fn, isRegex))
+	})
 	return env
 }
 
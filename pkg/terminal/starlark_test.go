@@ -162,3 +162,43 @@ func TestStarlarkVariable(t *testing.T) {
 		}
 	})
 }
+
+func TestStarlarkPrettyPrinter(t *testing.T) {
+	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		term.MustExec("source " + findStarFile("pretty_printer"))
+		for _, tc := range []struct{ cmd, tgt string }{
+			{"print as1", "main.astruct A=1 B=1\n"},
+			{"print -raw as1", "main.astruct {A: 1, B: 1}\n"},
+			{"print c1.pb", "*main.bstruct {first: main.astruct A=1 B=2, sum: 3}\n"},
+		} {
+			out := term.MustExec(tc.cmd)
+			if out != tc.tgt {
+				t.Errorf("for %q\nexpected %q\ngot %q", tc.cmd, tc.tgt, out)
+			}
+		}
+	})
+}
+
+func TestStarlarkPrettyPrinterOnBreakpoint(t *testing.T) {
+	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
+		term.MustExec("source " + findStarFile("pretty_printer"))
+		term.MustExec("break bp1 testvariables2.go:322")
+		term.MustExec("on bp1 print as1")
+		term.MustExec("on bp1 print -raw c1.pb")
+		out := term.MustExec("breakpoints")
+		if !strings.Contains(out, "\tprint as1\n\tprint -raw c1.pb\n") {
+			t.Errorf("breakpoint attributes not listed: %q", out)
+		}
+		term.MustExec("continue")
+		out = term.MustExec("continue")
+		for _, tgt := range []string{"\tas1: main.astruct A=1 B=1\n", "\tc1.pb: *main.bstruct {", "a: main.astruct {A: 1, B: 2}"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("expected %q in %q", tgt, out)
+			}
+		}
+		if strings.Contains(out, "first:") {
+			t.Errorf("pretty printer used for a variable printed with -raw: %q", out)
+		}
+	})
+}
@@ -56,16 +56,34 @@ type Term struct {
 	InitFile string
 	displays []string
 
+	// InferSubstitutePath, if set, prints the substitute-path rules that
+	// 'config substitute-path -infer' would add, before the init file is
+	// executed.
+	InferSubstitutePath bool
+
 	historyFile *os.File
 
 	starlarkEnv *starbind.Env
 
+	// rawBreakpointVariables are the expressions added to breakpoints with
+	// 'on <breakpoint> print -raw', indexed by breakpoint ID. They are
+	// printed without pretty printers and built-in formatters when the
+	// breakpoint is hit.
+	rawBreakpointVariables map[int]map[string]bool
+
 	// quitContinue is set to true by exitCommand to signal that the process
 	// should be resumed before quitting.
 	quitContinue bool
 
 	quittingMutex sync.Mutex
 	quitting      bool
+
+	// longCommandMu protects longCommandRunning and longCommandCanceled.
+	// A long running command (for example dump) can be interrupted with
+	// SIGINT without stopping the target.
+	longCommandMu       sync.Mutex
+	longCommandRunning  bool
+	longCommandCanceled bool
 }
 
 // New returns a new Term.
@@ -121,6 +139,10 @@ func (t *Term) Close() {
 
 func (t *Term) sigintGuard(ch <-chan os.Signal, multiClient bool) {
 	for range ch {
+		if t.longCommandCancel() {
+			fmt.Printf("received SIGINT, canceling command\n")
+			continue
+		}
 		t.starlarkEnv.Cancel()
 		state, err := t.client.GetStateNonBlocking()
 		if err == nil && state.Recording {
@@ -168,6 +190,42 @@ func (t *Term) sigintGuard(ch <-chan os.Signal, multiClient bool) {
 	}
 }
 
+// longCommandStart signals that a long running command, that can be
+// canceled by SIGINT, is starting.
+func (t *Term) longCommandStart() {
+	t.longCommandMu.Lock()
+	defer t.longCommandMu.Unlock()
+	t.longCommandRunning = true
+	t.longCommandCanceled = false
+}
+
+// longCommandEnd signals that the long running command has terminated.
+func (t *Term) longCommandEnd() {
+	t.longCommandMu.Lock()
+	defer t.longCommandMu.Unlock()
+	t.longCommandRunning = false
+}
+
+// longCommandCancel cancels the running long command, it returns false if
+// no long command is running.
+func (t *Term) longCommandCancel() bool {
+	t.longCommandMu.Lock()
+	defer t.longCommandMu.Unlock()
+	if !t.longCommandRunning {
+		return false
+	}
+	t.longCommandCanceled = true
+	return true
+}
+
+// longCommandIsCanceled returns true if the running long command was
+// canceled.
+func (t *Term) longCommandIsCanceled() bool {
+	t.longCommandMu.Lock()
+	defer t.longCommandMu.Unlock()
+	return t.longCommandCanceled
+}
+
 // Run begins running dlv in the terminal.
 func (t *Term) Run() (int, error) {
 	defer t.Close()
@@ -213,6 +271,20 @@ func (t *Term) Run() (int, error) {
 
 	fmt.Println("Type 'help' for list of commands.")
 
+	if t.InferSubstitutePath {
+		rules, err := t.inferSubstitutePath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not infer substitute-path rules: %v\n", err)
+		}
+		if len(rules) > 0 {
+			fmt.Println("Inferred substitute-path rules:")
+			for _, rule := range rules {
+				fmt.Printf("\t%q -> %q\n", rule.From, rule.To)
+			}
+			fmt.Println("Use 'config substitute-path -infer' to add them.")
+		}
+	}
+
 	if t.InitFile != "" {
 		err := t.cmds.executeFile(t, t.InitFile)
 		if err != nil {
@@ -438,7 +510,20 @@ func (t *Term) loadConfig() api.LoadConfig {
 	if t.conf != nil && t.conf.MaxVariableRecurse != nil {
 		r.MaxVariableRecurse = *t.conf.MaxVariableRecurse
 	}
+	if t.conf != nil {
+		r.DisableBuiltinFormatters = t.conf.DisableBuiltinFormatters
+	}
+
+	return r
+}
 
+// shortLoadConfig returns ShortLoadConfig with the built-in formatters
+// disabled if they are disabled in the configuration file.
+func (t *Term) shortLoadConfig() api.LoadConfig {
+	r := ShortLoadConfig
+	if t.conf != nil {
+		r.DisableBuiltinFormatters = t.conf.DisableBuiltinFormatters
+	}
 	return r
 }
 
@@ -463,7 +548,7 @@ func (t *Term) addDisplay(expr string) {
 
 func (t *Term) printDisplay(i int) {
 	expr := t.displays[i]
-	val, err := t.client.EvalVariable(api.EvalScope{GoroutineID: -1}, expr, ShortLoadConfig)
+	val, err := t.client.EvalVariable(api.EvalScope{GoroutineID: -1}, expr, t.shortLoadConfig())
 	if err != nil {
 		if isErrProcessExited(err) {
 			return
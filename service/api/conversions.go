@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/op"
@@ -148,7 +149,15 @@ func convertFloatValue(v *proc.Variable, sz int) string {
 }
 
 // ConvertVar converts from proc.Variable to api.Variable.
-func ConvertVar(v *proc.Variable) *Variable {
+// If formatters is true the values of some standard library types are
+// formatted by the built-in formatters, see FormatVariable.
+func ConvertVar(v *proc.Variable, formatters bool) *Variable {
+	return convertVar(v, formatters, 0)
+}
+
+// convertVar is like ConvertVar, formatDepth is the number of values of
+// type atomic.Value or reflect.Value containing v, see formatContent.
+func convertVar(v *proc.Variable, formatters bool, formatDepth int) *Variable {
 	r := Variable{
 		Addr:     v.Addr,
 		OnlyAddr: v.OnlyAddr,
@@ -171,6 +180,9 @@ func ConvertVar(v *proc.Variable) *Variable {
 	}
 
 	r.Value = VariableValueAsString(v)
+	if formatters {
+		r.Pretty = formatVariable(v, formatDepth)
+	}
 
 	switch v.Kind {
 	case reflect.Complex64:
@@ -219,7 +231,7 @@ func ConvertVar(v *proc.Variable) *Variable {
 		r.Children = make([]Variable, len(v.Children))
 
 		for i := range v.Children {
-			r.Children[i] = *ConvertVar(&v.Children[i])
+			r.Children[i] = *convertVar(&v.Children[i], formatters, formatDepth)
 		}
 	}
 
@@ -246,14 +258,15 @@ func VariableValueAsString(v *proc.Variable) string {
 	}
 }
 
-// ConvertVars converts from []*proc.Variable to []api.Variable.
-func ConvertVars(pv []*proc.Variable) []Variable {
+// ConvertVars converts from []*proc.Variable to []api.Variable, see
+// ConvertVar.
+func ConvertVars(pv []*proc.Variable, formatters bool) []Variable {
 	if pv == nil {
 		return nil
 	}
 	vars := make([]Variable, 0, len(pv))
 	for _, v := range pv {
-		vars = append(vars, *ConvertVar(v))
+		vars = append(vars, *ConvertVar(v, formatters))
 	}
 	return vars
 }
@@ -279,6 +292,8 @@ func ConvertFunction(fn *proc.Function) *Function {
 }
 
 // ConvertGoroutine converts from proc.G to api.Goroutine.
+// Fields that need the target, like Frozen and WaitDuration, are not
+// set, see ConvertTargetGoroutine.
 func ConvertGoroutine(g *proc.G) *Goroutine {
 	th := g.Thread
 	tid := 0
@@ -288,15 +303,24 @@ func ConvertGoroutine(g *proc.G) *Goroutine {
 	if g.Unreadable != nil {
 		return &Goroutine{Unreadable: g.Unreadable.Error()}
 	}
-	return &Goroutine{
+	r := &Goroutine{
 		ID:             g.ID,
 		CurrentLoc:     ConvertLocation(g.CurrentLoc),
 		UserCurrentLoc: ConvertLocation(g.UserCurrent()),
 		GoStatementLoc: ConvertLocation(g.Go()),
+		ParentID:       g.ParentID,
 		StartLoc:       ConvertLocation(g.StartLoc()),
 		ThreadID:       tid,
 		Labels:         g.Labels(),
+		WaitReason:     g.WaitReasonString(),
+	}
+	if r.WaitReason != "" {
+		r.WaitSince = g.WaitSince
+		if obj := g.BlockedOn(); obj != nil {
+			r.WaitObject = &WaitObject{Kind: obj.Kind, Addr: obj.Addr}
+		}
 	}
+	return r
 }
 
 // ConvertGoroutines converts from []*proc.G to []*api.Goroutine.
@@ -308,6 +332,33 @@ func ConvertGoroutines(gs []*proc.G) []*Goroutine {
 	return goroutines
 }
 
+// ConvertTargetGoroutine is like ConvertGoroutine but also sets the
+// fields of api.Goroutine that depend on the state of tgt.
+// The caller must hold the lock of the target.
+func ConvertTargetGoroutine(tgt *proc.Target, g *proc.G) *Goroutine {
+	r := ConvertGoroutine(g)
+	if g.Unreadable != nil {
+		return r
+	}
+	r.Frozen = tgt.IsGoroutineFrozen(g.ID)
+	if r.WaitReason != "" && g.WaitSince != 0 {
+		if now, err := tgt.Nanotime(); err == nil && now > g.WaitSince {
+			r.WaitDuration = time.Duration(now - g.WaitSince)
+		}
+	}
+	return r
+}
+
+// ConvertTargetGoroutines converts from []*proc.G to []*api.Goroutine
+// using ConvertTargetGoroutine.
+func ConvertTargetGoroutines(tgt *proc.Target, gs []*proc.G) []*Goroutine {
+	goroutines := make([]*Goroutine, len(gs))
+	for i := range gs {
+		goroutines[i] = ConvertTargetGoroutine(tgt, gs[i])
+	}
+	return goroutines
+}
+
 // ConvertLocation converts from proc.Location to api.Location.
 func ConvertLocation(loc proc.Location) Location {
 	return Location{
@@ -416,5 +467,5 @@ func ConvertRegisters(in *op.DwarfRegisters, dwarfRegisterToString func(int, *op
 }
 
 func ConvertImage(image *proc.Image) Image {
-	return Image{Path: image.Path, Address: image.StaticBase}
+	return Image{Path: image.Path, Address: image.StaticBase, BuildID: image.BuildID, PluginPath: image.PluginPath}
 }
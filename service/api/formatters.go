@@ -0,0 +1,358 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"go/constant"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+// builtinFormatter returns the function used to format values of type
+// typ, or nil.
+// A formatter returns the empty string if the value can not be formatted,
+// for example because the fields it needs were not loaded.
+// Values of type atomic.Value and reflect.Value are formatted by
+// formatVariable.
+func builtinFormatter(typ string) func(v *proc.Variable) string {
+	switch typ {
+	case "time.Time":
+		return formatTime
+	case "time.Duration":
+		return formatDuration
+	case "net.IP":
+		return formatIP
+	case "math/big.Int":
+		return formatBigInt
+	case "math/big.Float":
+		return formatBigFloat
+	case "sync.Mutex":
+		return formatMutex
+	}
+	return nil
+}
+
+// maxFormatContentDepth is the maximum number of values of type
+// atomic.Value or reflect.Value nested inside each other that are
+// formatted, see formatContent.
+const maxFormatContentDepth = 3
+
+// FormatVariable returns the value of v formatted by the built-in
+// formatter for its type, for example time.Time values are formatted as
+// RFC3339 timestamps. Pointers are formatted using the formatter of the
+// type they point to.
+// Returns the empty string if there is no formatter for the type of v.
+func FormatVariable(v *proc.Variable) string {
+	return formatVariable(v, 0)
+}
+
+// formatVariable is like FormatVariable, depth is the number of values
+// of type atomic.Value or reflect.Value containing v.
+func formatVariable(v *proc.Variable, depth int) string {
+	if v.Unreadable != nil || v.Flags&proc.VariableOptimizedOut != 0 {
+		return ""
+	}
+	if v.Kind == reflect.Ptr && len(v.Children) == 1 {
+		if v.Children[0].Addr == 0 {
+			return ""
+		}
+		v = &v.Children[0]
+	}
+	if v.Unreadable != nil {
+		return ""
+	}
+	switch typ := PrettyTypeName(v.DwarfType); typ {
+	case "sync/atomic.Value":
+		return formatAtomicValue(v, depth)
+	case "reflect.Value":
+		return formatReflectValue(v, depth)
+	default:
+		fn := builtinFormatter(typ)
+		if fn == nil {
+			return ""
+		}
+		return fn(v)
+	}
+}
+
+// ClearPretty removes from v, and its children, the values formatted by
+// the built-in formatters.
+func (v *Variable) ClearPretty() {
+	v.Pretty = ""
+	for i := range v.Children {
+		v.Children[i].ClearPretty()
+	}
+}
+
+// fieldNamed returns the loaded field of v called name, or nil.
+func fieldNamed(v *proc.Variable, name string) *proc.Variable {
+	for i := range v.Children {
+		if v.Children[i].Name == name {
+			if v.Children[i].Unreadable != nil {
+				return nil
+			}
+			return &v.Children[i]
+		}
+	}
+	return nil
+}
+
+func int64Field(v *proc.Variable, name string) (int64, bool) {
+	f := fieldNamed(v, name)
+	if f == nil || f.Value == nil {
+		return 0, false
+	}
+	return constant.Int64Val(f.Value)
+}
+
+func uint64Field(v *proc.Variable, name string) (uint64, bool) {
+	f := fieldNamed(v, name)
+	if f == nil || f.Value == nil {
+		return 0, false
+	}
+	return constant.Uint64Val(constant.ToInt(f.Value))
+}
+
+// Conversion between the internal representation of time.Time and Unix
+// time, see $GOROOT/src/time/time.go.
+const (
+	timeHasMonotonic         = 1 << 63
+	timeNsecMask             = 1<<30 - 1
+	timeNsecShift            = 30
+	timeWallToInternal int64 = (1884*365 + 1884/4 - 1884/100 + 1884/400) * 86400
+	timeUnixToInternal int64 = (1969*365 + 1969/4 - 1969/100 + 1969/400) * 86400
+)
+
+// formatTime formats a time.Time value as an RFC3339 timestamp followed by
+// the name of its location. The offset of the location is read from the
+// zone cached by the target, if the timestamp is outside of the cached
+// zone the time zone database of the host is used. If neither is
+// available the timestamp is displayed in UTC.
+func formatTime(v *proc.Variable) string {
+	wall, ok1 := uint64Field(v, "wall")
+	ext, ok2 := int64Field(v, "ext")
+	loc := fieldNamed(v, "loc")
+	if !ok1 || !ok2 || loc == nil {
+		return ""
+	}
+
+	var sec int64
+	if wall&timeHasMonotonic != 0 {
+		sec = timeWallToInternal + int64(wall<<1>>(timeNsecShift+1))
+	} else {
+		sec = ext
+	}
+	sec -= timeUnixToInternal
+	t := time.Unix(sec, int64(wall&timeNsecMask)).UTC()
+
+	if len(loc.Children) == 0 || loc.Children[0].Addr == 0 {
+		return t.Format(time.RFC3339Nano) + " UTC"
+	}
+	locv := &loc.Children[0]
+	name := fieldNamed(locv, "name")
+	if name == nil || name.Value == nil {
+		return ""
+	}
+	locName := constant.StringVal(name.Value)
+
+	if zname, offset, ok := cachedZone(locv, sec); ok {
+		t = t.In(time.FixedZone(zname, offset))
+	} else if locName != "Local" {
+		if l, err := time.LoadLocation(locName); err == nil {
+			t = t.In(l)
+		}
+	}
+	return t.Format(time.RFC3339Nano) + " " + locName
+}
+
+// cachedZone returns the zone cached by the time.Location locv if it
+// applies to sec.
+func cachedZone(locv *proc.Variable, sec int64) (name string, offset int, ok bool) {
+	start, ok1 := int64Field(locv, "cacheStart")
+	end, ok2 := int64Field(locv, "cacheEnd")
+	zonep := fieldNamed(locv, "cacheZone")
+	if !ok1 || !ok2 || zonep == nil || len(zonep.Children) == 0 || zonep.Children[0].Addr == 0 || sec < start || sec >= end {
+		return "", 0, false
+	}
+	zone := &zonep.Children[0]
+	zoneName := fieldNamed(zone, "name")
+	zoneOffset, ok := int64Field(zone, "offset")
+	if zoneName == nil || zoneName.Value == nil || !ok {
+		return "", 0, false
+	}
+	return constant.StringVal(zoneName.Value), int(zoneOffset), true
+}
+
+func formatDuration(v *proc.Variable) string {
+	if v.Value == nil || v.Value.Kind() != constant.Int {
+		return ""
+	}
+	n, _ := constant.Int64Val(v.Value)
+	return time.Duration(n).String()
+}
+
+func formatIP(v *proc.Variable) string {
+	if v.Kind != reflect.Slice || v.Len == 0 || int64(len(v.Children)) != v.Len {
+		return ""
+	}
+	ip := make(net.IP, len(v.Children))
+	for i := range v.Children {
+		if v.Children[i].Value == nil {
+			return ""
+		}
+		b, _ := constant.Uint64Val(v.Children[i].Value)
+		ip[i] = byte(b)
+	}
+	return ip.String()
+}
+
+// natToBigInt converts v, a math/big.nat value, to a big.Int.
+func natToBigInt(v *proc.Variable) (*big.Int, bool) {
+	if v == nil || int64(len(v.Children)) != v.Len {
+		return nil, false
+	}
+	r := new(big.Int)
+	for i := len(v.Children) - 1; i >= 0; i-- {
+		w := &v.Children[i]
+		if w.Value == nil || w.RealType == nil {
+			return nil, false
+		}
+		n, _ := constant.Uint64Val(w.Value)
+		r.Lsh(r, uint(w.RealType.Size()*8))
+		r.Or(r, new(big.Int).SetUint64(n))
+	}
+	return r, true
+}
+
+func wordBits(v *proc.Variable) int64 {
+	if len(v.Children) == 0 || v.Children[0].RealType == nil {
+		return 0
+	}
+	return v.Children[0].RealType.Size() * 8
+}
+
+func formatBigInt(v *proc.Variable) string {
+	neg := fieldNamed(v, "neg")
+	abs, ok := natToBigInt(fieldNamed(v, "abs"))
+	if neg == nil || neg.Value == nil || !ok {
+		return ""
+	}
+	if constant.BoolVal(neg.Value) {
+		abs.Neg(abs)
+	}
+	return abs.String()
+}
+
+// Values of the form field of math/big.Float, see $GOROOT/src/math/big/float.go.
+const (
+	bigFloatZero = iota
+	bigFloatFinite
+	bigFloatInf
+)
+
+func formatBigFloat(v *proc.Variable) string {
+	prec, ok1 := uint64Field(v, "prec")
+	form, ok2 := uint64Field(v, "form")
+	exp, ok3 := int64Field(v, "exp")
+	neg := fieldNamed(v, "neg")
+	if !ok1 || !ok2 || !ok3 || neg == nil || neg.Value == nil {
+		return ""
+	}
+	sign := ""
+	if constant.BoolVal(neg.Value) {
+		sign = "-"
+	}
+	switch form {
+	case bigFloatZero:
+		return sign + "0"
+	case bigFloatInf:
+		if sign == "" {
+			sign = "+"
+		}
+		return sign + "Inf"
+	case bigFloatFinite:
+		// the value is 0.mant * 2**exp
+		mantv := fieldNamed(v, "mant")
+		mant, ok := natToBigInt(mantv)
+		if !ok {
+			return ""
+		}
+		f := new(big.Float).SetInt(mant)
+		f.SetMantExp(f, int(exp-int64(len(mantv.Children))*wordBits(mantv)))
+		f.SetPrec(uint(prec))
+		return sign + f.Text('g', -1)
+	}
+	return ""
+}
+
+// Bits of the state field of sync.Mutex, see $GOROOT/src/sync/mutex.go.
+const (
+	mutexLocked      = 1
+	mutexStarving    = 4
+	mutexWaiterShift = 3
+)
+
+func formatMutex(v *proc.Variable) string {
+	if mu := fieldNamed(v, "mu"); mu != nil {
+		// since go1.24 sync.Mutex wraps internal/sync.Mutex
+		v = mu
+	}
+	state, ok := int64Field(v, "state")
+	if !ok {
+		return ""
+	}
+	var buf strings.Builder
+	if state&mutexLocked != 0 {
+		buf.WriteString("locked")
+	} else {
+		buf.WriteString("unlocked")
+	}
+	if state&mutexStarving != 0 {
+		buf.WriteString(", starving")
+	}
+	if waiters := state >> mutexWaiterShift; waiters != 0 {
+		fmt.Fprintf(&buf, ", %d waiters", waiters)
+	}
+	return buf.String()
+}
+
+func formatAtomicValue(v *proc.Variable, depth int) string {
+	iface := fieldNamed(v, "v")
+	if iface == nil || len(iface.Children) == 0 {
+		return ""
+	}
+	data := &iface.Children[0]
+	if data.Kind == reflect.Invalid {
+		return "nil"
+	}
+	return formatContent(data, depth)
+}
+
+func formatReflectValue(v *proc.Variable, depth int) string {
+	data := v.ReflectValue()
+	if data == nil || data.Unreadable != nil {
+		return ""
+	}
+	if data.Kind == reflect.Invalid {
+		return "<invalid reflect.Value>"
+	}
+	return formatContent(data, depth)
+}
+
+// formatContent formats the value held by a variable of type
+// atomic.Value or reflect.Value, depth is the number of those values
+// containing data.
+func formatContent(data *proc.Variable, depth int) string {
+	if depth >= maxFormatContentDepth {
+		return ""
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "(%s) ", PrettyTypeName(data.DwarfType))
+	convertVar(data, true, depth+1).writeTo(&buf, true, false, false, "")
+	return buf.String()
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"go/constant"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+func namedType(name string) godwarf.Type {
+	return &godwarf.StructType{CommonType: godwarf.CommonType{Name: name}}
+}
+
+var wordType = &godwarf.UintType{BasicType: godwarf.BasicType{CommonType: godwarf.CommonType{Name: "uint", ByteSize: 8}}}
+
+func intField(name string, n int64) proc.Variable {
+	return proc.Variable{Name: name, Kind: reflect.Int64, Value: constant.MakeInt64(n)}
+}
+
+func uintField(name string, n uint64) proc.Variable {
+	return proc.Variable{Name: name, Kind: reflect.Uint64, Value: constant.MakeUint64(n)}
+}
+
+func boolField(name string, b bool) proc.Variable {
+	return proc.Variable{Name: name, Kind: reflect.Bool, Value: constant.MakeBool(b)}
+}
+
+func natField(name string, words ...uint64) proc.Variable {
+	v := proc.Variable{Name: name, Kind: reflect.Slice, Len: int64(len(words))}
+	for _, w := range words {
+		v.Children = append(v.Children, proc.Variable{Kind: reflect.Uint, Value: constant.MakeUint64(w), RealType: wordType})
+	}
+	return v
+}
+
+func ptrField(name string, child *proc.Variable) proc.Variable {
+	v := proc.Variable{Name: name, Kind: reflect.Ptr, Len: 1}
+	if child == nil {
+		child = &proc.Variable{}
+	}
+	v.Children = []proc.Variable{*child}
+	return v
+}
+
+func structVar(typ string, fields ...proc.Variable) *proc.Variable {
+	return &proc.Variable{Addr: 0x1000, Kind: reflect.Struct, DwarfType: namedType(typ), Children: fields, Len: int64(len(fields))}
+}
+
+func TestBuiltinFormatters(t *testing.T) {
+	const sec = 1600000000 // 2020-09-13T12:26:40Z
+	timeInternal := uint64(sec + timeUnixToInternal)
+
+	rome := structVar("time.Location",
+		proc.Variable{Name: "name", Kind: reflect.String, Value: constant.MakeString("Europe/Rome")},
+		intField("cacheStart", sec-100),
+		intField("cacheEnd", sec+100),
+		ptrField("cacheZone", structVar("time.zone",
+			proc.Variable{Name: "name", Kind: reflect.String, Value: constant.MakeString("CEST")},
+			intField("offset", 2*60*60))))
+
+	mant := new(big.Int).Lsh(big.NewInt(3), 62) // 0.11 in binary, i.e. 0.75
+	// -0.75 * 2**2
+	bigf := structVar("math/big.Float", uintField("prec", 53), uintField("form", bigFloatFinite), boolField("neg", true), natField("mant", mant.Uint64()), intField("exp", 2))
+
+	mu := *structVar("internal/sync.Mutex", intField("state", 1|2<<mutexWaiterShift))
+	mu.Name = "mu"
+
+	for _, tc := range []struct {
+		v   *proc.Variable
+		tgt string
+	}{
+		{structVar("time.Time", uintField("wall", 500), intField("ext", int64(timeInternal)), ptrField("loc", nil)), "2020-09-13T12:26:40.0000005Z UTC"},
+		{structVar("time.Time", uintField("wall", 0), intField("ext", int64(timeInternal)), ptrField("loc", rome)), "2020-09-13T14:26:40+02:00 Europe/Rome"},
+		{&proc.Variable{Kind: reflect.Int64, DwarfType: namedType("time.Duration"), Value: constant.MakeInt64(int64(1500 * time.Millisecond))}, "1.5s"},
+		{&proc.Variable{Kind: reflect.Slice, DwarfType: namedType("net.IP"), Len: 4, Children: []proc.Variable{uintField("", 127), uintField("", 0), uintField("", 0), uintField("", 1)}}, "127.0.0.1"},
+		{structVar("math/big.Int", boolField("neg", true), natField("abs", 0, 1)), "-18446744073709551616"},
+		{&proc.Variable{Kind: reflect.Ptr, Children: []proc.Variable{*structVar("math/big.Int", boolField("neg", false), natField("abs", 42))}}, "42"},
+		{bigf, "-3"},
+		{structVar("sync.Mutex", mu), "locked, 2 waiters"},
+		{structVar("sync.Mutex", intField("state", 0)), "unlocked"},
+		{structVar("sync/atomic.Value", proc.Variable{Name: "v", Kind: reflect.Interface, Children: []proc.Variable{{}}}), "nil"},
+		{structVar("main.T", intField("state", 0)), ""},
+	} {
+		if out := FormatVariable(tc.v); out != tc.tgt {
+			t.Errorf("formatting %s: expected %q got %q", PrettyTypeName(tc.v.DwarfType), tc.tgt, out)
+		}
+	}
+
+	// formatting is skipped at the source when the formatters are disabled
+	d := &proc.Variable{Kind: reflect.Int64, DwarfType: namedType("time.Duration"), Value: constant.MakeInt64(1)}
+	if out := ConvertVar(d, false).Pretty; out != "" {
+		t.Errorf("formatters disabled: expected no output got %q", out)
+	}
+	if out := ConvertVar(d, true).Pretty; out != "1ns" {
+		t.Errorf("formatters enabled: expected %q got %q", "1ns", out)
+	}
+}
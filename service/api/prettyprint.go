@@ -32,12 +32,25 @@ func (v *Variable) MultilineString(indent string) string {
 }
 
 func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, indent string) {
+	if v.Flags&VariableOptimizedOut != 0 {
+		fmt.Fprint(buf, "(optimized out)")
+		return
+	}
 	if v.Unreadable != "" {
 		fmt.Fprintf(buf, "(unreadable %s)", v.Unreadable)
 		return
 	}
 
-	if !top && v.Addr == 0 && v.Value == "" {
+	if v.Pretty != "" {
+		if includeType {
+			fmt.Fprintf(buf, "%s %s", v.Type, v.Pretty)
+		} else {
+			fmt.Fprint(buf, v.Pretty)
+		}
+		return
+	}
+
+	if !top && v.Addr == 0 && v.Value == "" && v.Flags&VariableConstant == 0 {
 		if includeType && v.Type != "void" {
 			fmt.Fprintf(buf, "%s nil", v.Type)
 		} else {
@@ -288,6 +301,10 @@ func (v *Variable) recursiveKind() (reflect.Kind, bool) {
 	hasptr := false
 	var kind reflect.Kind
 	for {
+		if v.Pretty != "" {
+			// pretty printed values are displayed like strings
+			return reflect.String, hasptr
+		}
 		kind = v.Kind
 		if kind == reflect.Ptr {
 			hasptr = true
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"time"
 	"unicode"
 
 	"github.com/go-delve/delve/pkg/proc"
@@ -154,7 +155,8 @@ type Stackframe struct {
 
 	Defers []Defer
 
-	Bottom bool `json:"Bottom,omitempty"` // Bottom is true if this is the bottom frame of the stack
+	Bottom  bool `json:"Bottom,omitempty"`  // Bottom is true if this is the bottom frame of the stack
+	Inlined bool `json:"Inlined,omitempty"` // Inlined is true if this frame is an inlined call
 
 	Err string
 }
@@ -233,6 +235,14 @@ const (
 	// the variable is the return value of a function call and allocated on a
 	// frame that no longer exists)
 	VariableFakeAddress
+
+	// VariableCPtr means the variable is a C pointer
+	VariableCPtr
+
+	// VariableOptimizedOut means the value of this variable is not available
+	// at the current PC because it was optimized away by the compiler, the
+	// Unreadable field contains a description of why it is not available.
+	VariableOptimizedOut
 )
 
 // Variable describes a variable.
@@ -277,6 +287,11 @@ type Variable struct {
 	// Unreadable addresses will have this field set
 	Unreadable string `json:"unreadable"`
 
+	// Pretty is the value of the variable as formatted by a pretty printer
+	// or by the built-in formatter for its type, when it is set it is
+	// displayed instead of the value of the variable.
+	Pretty string `json:"pretty,omitempty"`
+
 	// LocationExpr describes the location expression of this variable's address
 	LocationExpr string
 	// DeclLine is the line number of this variable's declaration
@@ -295,6 +310,10 @@ type LoadConfig struct {
 	MaxArrayValues int
 	// MaxStructFields is the maximum number of fields read from a struct, -1 will read all fields.
 	MaxStructFields int
+	// DisableBuiltinFormatters disables the formatters used to display
+	// values of standard library types, like time.Time and sync.Mutex, see
+	// Variable.Pretty.
+	DisableBuiltinFormatters bool
 }
 
 // Goroutine represents the information relevant to Delve from the runtime's
@@ -308,6 +327,8 @@ type Goroutine struct {
 	UserCurrentLoc Location `json:"userCurrentLoc"`
 	// Location of the go instruction that started this goroutine
 	GoStatementLoc Location `json:"goStatementLoc"`
+	// ID of the goroutine that executed the go instruction, zero if unknown
+	ParentID int `json:"parentID,omitempty"`
 	// Location of the starting function
 	StartLoc Location `json:"startLoc"`
 	// ID of the associated thread for running goroutines
@@ -315,6 +336,55 @@ type Goroutine struct {
 	Unreadable string `json:"unreadable"`
 	// Goroutine's pprof labels
 	Labels map[string]string `json:"labels,omitempty"`
+	// Frozen is true if the goroutine was suspended by FreezeGoroutine
+	Frozen bool `json:"frozen,omitempty"`
+	// WaitReason is the reason why the goroutine is parked, empty if the
+	// goroutine isn't parked.
+	WaitReason string `json:"waitReason,omitempty"`
+	// WaitSince is the approximate value of runtime.nanotime when the
+	// goroutine was parked, zero if unknown.
+	WaitSince int64 `json:"waitSince,omitempty"`
+	// WaitDuration is how long the goroutine has been parked, zero if
+	// unknown.
+	WaitDuration time.Duration `json:"waitDuration,omitempty"`
+	// WaitObject is the object the goroutine is blocked on, nil if unknown.
+	WaitObject *WaitObject `json:"waitObject,omitempty"`
+}
+
+// WaitObject describes the object a parked goroutine is blocked on.
+type WaitObject struct {
+	// Kind is "chan" for channel operations and "sema" for semaphores,
+	// including the ones used by sync.Mutex and sync.RWMutex.
+	Kind string `json:"kind"`
+	// Addr is the address of the channel or semaphore.
+	Addr uint64 `json:"addr"`
+}
+
+// WaitDescription returns a description of why g is parked, for example
+// "blocked 4m12s on chan 0xc000123 (recv)", or an empty string if g isn't
+// parked.
+func (g *Goroutine) WaitDescription() string {
+	if g.WaitReason == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("blocked")
+	if d := g.WaitDuration.Round(time.Second); d > 0 {
+		fmt.Fprintf(&buf, " %v", d)
+	}
+	if g.WaitObject == nil {
+		fmt.Fprintf(&buf, " (%s)", g.WaitReason)
+		return buf.String()
+	}
+	op := g.WaitReason
+	switch op {
+	case "chan receive":
+		op = "recv"
+	case "chan send":
+		op = "send"
+	}
+	fmt.Fprintf(&buf, " on %s %#x (%s)", g.WaitObject.Kind, g.WaitObject.Addr, op)
+	return buf.String()
 }
 
 // DebuggerCommand is a command which changes the debugger's execution state.
@@ -499,6 +569,19 @@ type Checkpoint struct {
 type Image struct {
 	Path    string
 	Address uint64
+	BuildID string `json:"buildID,omitempty"`
+	// PluginPath is the package path of the main package of a Go plugin,
+	// empty for executables and shared libraries.
+	PluginPath string `json:"pluginPath,omitempty"`
+}
+
+// SourceFile describes a source file referenced by the debug information
+// of the target and, when the compiler recorded them, the size and MD5
+// checksum it had when the target was built.
+type SourceFile struct {
+	Path string
+	Size uint64 `json:"size,omitempty"`
+	MD5  string `json:"md5,omitempty"` // hex encoded
 }
 
 // Ancestor represents a goroutine ancestor
@@ -534,3 +617,14 @@ type PackageBuildInfo struct {
 	DirectoryPath string
 	Files         []string
 }
+
+// DumpState describes the state of a core dump in progress.
+type DumpState struct {
+	Dumping bool // a dump is in progress
+	AllDone bool // the dump finished, Err is set if it failed
+
+	ThreadsDone, ThreadsTotal int
+	MemDone, MemTotal         uint64
+
+	Err string
+}
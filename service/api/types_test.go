@@ -0,0 +1,24 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitDescription(t *testing.T) {
+	tests := []struct {
+		g    Goroutine
+		want string
+	}{
+		{Goroutine{}, ""},
+		{Goroutine{WaitReason: "select"}, "blocked (select)"},
+		{Goroutine{WaitReason: "chan receive", WaitDuration: 4*time.Minute + 12*time.Second + 300*time.Millisecond, WaitObject: &WaitObject{Kind: "chan", Addr: 0xc000123}}, "blocked 4m12s on chan 0xc000123 (recv)"},
+		{Goroutine{WaitReason: "chan send", WaitObject: &WaitObject{Kind: "chan", Addr: 0xc000456}}, "blocked on chan 0xc000456 (send)"},
+		{Goroutine{WaitReason: "semacquire", WaitDuration: 2 * time.Second, WaitObject: &WaitObject{Kind: "sema", Addr: 0xc000789}}, "blocked 2s on sema 0xc000789 (semacquire)"},
+	}
+	for _, tc := range tests {
+		if got := tc.g.WaitDescription(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
@@ -93,6 +93,9 @@ type Client interface {
 
 	// ListSources lists all source files in the process matching filter.
 	ListSources(filter string) ([]string, error)
+	// ListSourcesInfo lists all source files in the process matching filter
+	// with the size and checksum they had when the target was built.
+	ListSourcesInfo(filter string) ([]api.SourceFile, error)
 	// ListFunctions lists all functions in the process matching filter.
 	ListFunctions(filter string) ([]string, error)
 	// ListTypes lists all types in the process matching filter.
@@ -108,6 +111,13 @@ type Client interface {
 
 	// ListGoroutines lists all goroutines.
 	ListGoroutines(start, count int) ([]*api.Goroutine, int, error)
+	// GoroutineProfile returns a pprof profile of the stacks of all goroutines.
+	GoroutineProfile() ([]byte, error)
+	// FreezeGoroutine suspends a goroutine, the rest of the target keeps
+	// running when it is resumed.
+	FreezeGoroutine(gid int) error
+	// ThawGoroutine releases a goroutine suspended by FreezeGoroutine.
+	ThawGoroutine(gid int) error
 
 	// Returns stacktrace
 	Stacktrace(goroutineID int, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error)
@@ -165,6 +175,18 @@ type Client interface {
 	// StopRecording stops a recording if one is in progress.
 	StopRecording() error
 
+	// DumpStart starts a core dump to dest.
+	DumpStart(dest string) (api.DumpState, error)
+	// DumpWait waits for the core dump to finish or for the specified number
+	// of milliseconds to elapse.
+	DumpWait(wait int) (api.DumpState, error)
+	// DumpCancel cancels a core dump in progress.
+	DumpCancel() error
+
+	// FetchSource returns the contents of a source file downloaded from the
+	// debuginfod servers.
+	FetchSource(path string) ([]byte, error)
+
 	// Disconnect closes the connection to the server without sending a Detach request first.
 	// If cont is true a continue command will be sent instead.
 	Disconnect(cont bool) error
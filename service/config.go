@@ -36,4 +36,10 @@ type Config struct {
 
 	// DisconnectChan will be closed by the server when the client disconnects
 	DisconnectChan chan<- struct{}
+
+	// DisableBuiltinFormatters disables the formatters used by the DAP
+	// server to display values of standard library types, like time.Time
+	// and sync.Mutex. Clients of the JSON-RPC API disable them with
+	// api.LoadConfig.
+	DisableBuiltinFormatters bool
 }
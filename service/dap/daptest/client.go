@@ -132,6 +132,11 @@ func (c *Client) ExpectLaunchResponse(t *testing.T) *dap.LaunchResponse {
 	return c.expectReadProtocolMessage(t).(*dap.LaunchResponse)
 }
 
+func (c *Client) ExpectAttachResponse(t *testing.T) *dap.AttachResponse {
+	t.Helper()
+	return c.expectReadProtocolMessage(t).(*dap.AttachResponse)
+}
+
 func (c *Client) ExpectSetExceptionBreakpointsResponse(t *testing.T) *dap.SetExceptionBreakpointsResponse {
 	t.Helper()
 	return c.expectReadProtocolMessage(t).(*dap.SetExceptionBreakpointsResponse)
@@ -207,6 +212,11 @@ func (c *Client) ExpectRestartFrameResponse(t *testing.T) *dap.RestartFrameRespo
 	return c.expectReadProtocolMessage(t).(*dap.RestartFrameResponse)
 }
 
+func (c *Client) ExpectSetVariableResponse(t *testing.T) *dap.SetVariableResponse {
+	t.Helper()
+	return c.expectReadProtocolMessage(t).(*dap.SetVariableResponse)
+}
+
 func (c *Client) ExpectSetExpressionResponse(t *testing.T) *dap.SetExpressionResponse {
 	t.Helper()
 	return c.expectReadProtocolMessage(t).(*dap.SetExpressionResponse)
@@ -317,7 +327,17 @@ func (c *Client) LaunchRequestWithArgs(arguments map[string]interface{}) {
 // AttachRequest sends an 'attach' request.
 func (c *Client) AttachRequest() {
 	request := &dap.AttachRequest{Request: *c.newRequest("attach")}
-	// TODO(polina): populate meaningful arguments
+	c.send(request)
+}
+
+// AttachRequestWithArgs takes a map of untyped implementation-specific
+// arguments to send an 'attach' request. This may be used to send attach
+// requests with arguments that dap.AttachRequestArguments can not hold.
+func (c *Client) AttachRequestWithArgs(arguments map[string]interface{}) {
+	request := &struct {
+		dap.Request
+		Arguments map[string]interface{} `json:"arguments"`
+	}{Request: *c.newRequest("attach"), Arguments: arguments}
 	c.send(request)
 }
 
@@ -455,8 +475,12 @@ func (c *Client) ReverseContinueRequest() {
 }
 
 // SetVariableRequest sends a 'setVariable' request.
-func (c *Client) SetVariableRequest() {
-	c.send(&dap.ReverseContinueRequest{Request: *c.newRequest("setVariable")})
+func (c *Client) SetVariableRequest(variablesReference int, name, value string) {
+	request := &dap.SetVariableRequest{Request: *c.newRequest("setVariable")}
+	request.Arguments.VariablesReference = variablesReference
+	request.Arguments.Name = name
+	request.Arguments.Value = value
+	c.send(request)
 }
 
 // RestartFrameRequest sends a 'restartFrame' request.
@@ -470,8 +494,12 @@ func (c *Client) GotoRequest() {
 }
 
 // SetExpressionRequest sends a 'setExpression' request.
-func (c *Client) SetExpressionRequest() {
-	c.send(&dap.SetExpressionRequest{Request: *c.newRequest("setExpression")})
+func (c *Client) SetExpressionRequest(frameID int, expression, value string) {
+	request := &dap.SetExpressionRequest{Request: *c.newRequest("setExpression")}
+	request.Arguments.FrameId = frameID
+	request.Arguments.Expression = expression
+	request.Arguments.Value = value
+	c.send(request)
 }
 
 // SourceRequest sends a 'source' request.
@@ -19,5 +19,6 @@ const (
 	UnableToListArgs          = 2006
 	UnableToListGlobals       = 2007
 	UnableToLookupVariable    = 2008
+	UnableToSetVariable       = 2012
 	// Add more codes as we support more requests
 )
@@ -35,6 +35,24 @@ func (hs *handlesMap) get(handle int) (interface{}, bool) {
 	return v, ok
 }
 
+// fullyQualifiedVariable is a variable returned to the client together with
+// the information needed to refer to it, and to its children, in a later
+// request.
+type fullyQualifiedVariable struct {
+	*proc.Variable
+	// fullyQualifiedNameOrExpr is an expression that evaluates to Variable
+	// in frame. It is empty if Variable can not be referred to by an
+	// expression. For scopes it is the prefix of the names of their children.
+	fullyQualifiedNameOrExpr string
+	// isScope is true if Variable is one of the scopes of frame.
+	isScope bool
+	// isSynthetic is true if the children of Variable were returned by a
+	// pretty printer, see prettyPrintVariable.
+	isSynthetic bool
+	// frame is the stack frame in which fullyQualifiedNameOrExpr is evaluated.
+	frame stackFrame
+}
+
 type variablesHandlesMap struct {
 	m *handlesMap
 }
@@ -43,16 +61,16 @@ func newVariablesHandlesMap() *variablesHandlesMap {
 	return &variablesHandlesMap{newHandlesMap()}
 }
 
-func (hs *variablesHandlesMap) create(value *proc.Variable) int {
+func (hs *variablesHandlesMap) create(value *fullyQualifiedVariable) int {
 	return hs.m.create(value)
 }
 
-func (hs *variablesHandlesMap) get(handle int) (*proc.Variable, bool) {
+func (hs *variablesHandlesMap) get(handle int) (*fullyQualifiedVariable, bool) {
 	v, ok := hs.m.get(handle)
 	if !ok {
 		return nil, false
 	}
-	return v.(*proc.Variable), true
+	return v.(*fullyQualifiedVariable), true
 }
 
 func (hs *variablesHandlesMap) reset() {
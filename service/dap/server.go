@@ -18,11 +18,13 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/terminal/starbind"
 	"github.com/go-delve/delve/service"
 	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/debugger"
@@ -64,6 +66,9 @@ type Server struct {
 	variableHandles *variablesHandlesMap
 	// args tracks special settings for handling debug session requests.
 	args launchAttachArgs
+	// starlarkEnv runs the pretty printers loaded from the scripts in the
+	// prettyPrinters launch or attach argument, nil if there are none.
+	starlarkEnv *starbind.Env
 }
 
 // launchAttachArgs captures arguments from launch/attach request that
@@ -84,6 +89,11 @@ var defaultArgs = launchAttachArgs{
 	showGlobalVariables: false,
 }
 
+// defaultLoadConfig is the configuration used to load the values of
+// variables returned to the client.
+// TODO(polina): Support setting config via launch/attach args
+var defaultLoadConfig = proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+
 // NewServer creates a new DAP Server. It takes an opened Listener
 // via config and assumes its ownership. config.disconnectChan has to be set;
 // it will be closed by the server when the client disconnects or requests
@@ -185,7 +195,7 @@ func (s *Server) serveDAPCodec() {
 	defer s.signalDisconnect()
 	s.reader = bufio.NewReader(s.conn)
 	for {
-		request, err := dap.ReadProtocolMessage(s.reader)
+		request, err := s.readRequest()
 		// TODO(polina): Differentiate between errors and handle them
 		// gracefully. For example,
 		// -- "Request command 'foo' is not supported" means we
@@ -209,6 +219,34 @@ func (s *Server) serveDAPCodec() {
 	}
 }
 
+// attachRequest is a dap.AttachRequest with the attributes of the debug
+// configuration, which dap.AttachRequestArguments doesn't have.
+type attachRequest struct {
+	dap.Request
+
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// readRequest reads and decodes the next request from the client.
+func (s *Server) readRequest() (dap.Message, error) {
+	content, err := dap.ReadBaseMessage(s.reader)
+	if err != nil {
+		return nil, err
+	}
+	request, err := dap.DecodeProtocolMessage(content)
+	if err != nil {
+		return nil, err
+	}
+	if _, isattach := request.(*dap.AttachRequest); isattach {
+		attach := &attachRequest{}
+		if err := json.Unmarshal(content, attach); err != nil {
+			return nil, err
+		}
+		return attach, nil
+	}
+	return request, nil
+}
+
 func (s *Server) handleRequest(request dap.Message) {
 	defer func() {
 		// In case a handler panics, we catch the panic and send an error response
@@ -228,9 +266,8 @@ func (s *Server) handleRequest(request dap.Message) {
 	case *dap.LaunchRequest:
 		// Required
 		s.onLaunchRequest(request)
-	case *dap.AttachRequest:
+	case *attachRequest:
 		// Required
-		// TODO: implement this request in V0
 		s.onAttachRequest(request)
 	case *dap.DisconnectRequest:
 		// Required
@@ -299,11 +336,9 @@ func (s *Server) handleRequest(request dap.Message) {
 	case *dap.SetVariableRequest:
 		// Optional (capability ‘supportsSetVariable’)
 		// Supported by vscode-go
-		// TODO: implement this request in V0
 		s.onSetVariableRequest(request)
 	case *dap.SetExpressionRequest:
 		// Optional (capability ‘supportsSetExpression’)
-		// TODO: implement this request in V1
 		s.onSetExpressionRequest(request)
 	case *dap.SourceRequest:
 		// Required
@@ -381,14 +416,13 @@ func (s *Server) onInitializeRequest(request *dap.InitializeRequest) {
 	response := &dap.InitializeResponse{Response: *newResponse(request.Request)}
 	response.Body.SupportsConfigurationDoneRequest = true
 	response.Body.SupportsConditionalBreakpoints = true
-	// TODO(polina): support this to match vscode-go functionality
-	response.Body.SupportsSetVariable = false
+	response.Body.SupportsSetVariable = true
 	// TODO(polina): support these requests in addition to vscode-go feature parity
 	response.Body.SupportsTerminateRequest = false
 	response.Body.SupportsRestartRequest = false
 	response.Body.SupportsFunctionBreakpoints = false
 	response.Body.SupportsStepBack = false
-	response.Body.SupportsSetExpression = false
+	response.Body.SupportsSetExpression = true
 	response.Body.SupportsLoadedSourcesRequest = false
 	response.Body.SupportsReadMemoryRequest = false
 	response.Body.SupportsDisassembleRequest = false
@@ -399,6 +433,48 @@ func (s *Server) onInitializeRequest(request *dap.InitializeRequest) {
 // Output path for the compiled binary in debug or test modes.
 const debugBinary string = "./__debug_bin"
 
+// setLaunchAttachArgs overwrites the defaults for the optional arguments
+// of a launch or attach request that the user specified.
+func (s *Server) setLaunchAttachArgs(args map[string]interface{}) {
+	stop, ok := args["stopOnEntry"].(bool)
+	if ok {
+		s.args.stopOnEntry = stop
+	}
+	depth, ok := args["stackTraceDepth"].(float64)
+	if ok && depth > 0 {
+		s.args.stackTraceDepth = int(depth)
+	}
+	globals, ok := args["showGlobalVariables"].(bool)
+	if ok {
+		s.args.showGlobalVariables = globals
+	}
+}
+
+// loadPrettyPrinters executes the starlark scripts listed in the
+// prettyPrinters argument of a launch or attach request.
+func (s *Server) loadPrettyPrinters(args map[string]interface{}) error {
+	printers, ok := args["prettyPrinters"]
+	if !ok {
+		return nil
+	}
+	printersParsed, ok := printers.([]interface{})
+	if !ok {
+		return fmt.Errorf("'prettyPrinters' attribute '%v' in debug configuration is not an array.", printers)
+	}
+	env := starbind.New(starlarkContext{})
+	for _, printer := range printersParsed {
+		path, ok := printer.(string)
+		if !ok {
+			return fmt.Errorf("value '%v' in 'prettyPrinters' attribute in debug configuration is not a string.", printer)
+		}
+		if _, err := env.Execute(path, nil, "", nil); err != nil {
+			return fmt.Errorf("could not load pretty printers from %s: %v", path, err)
+		}
+	}
+	s.starlarkEnv = env
+	return nil
+}
+
 func (s *Server) onLaunchRequest(request *dap.LaunchRequest) {
 	// TODO(polina): Respond with an error if debug session is in progress?
 
@@ -462,19 +538,7 @@ func (s *Server) onLaunchRequest(request *dap.LaunchRequest) {
 		return
 	}
 
-	// If user-specified, overwrite the defaults for optional args.
-	stop, ok := request.Arguments["stopOnEntry"].(bool)
-	if ok {
-		s.args.stopOnEntry = stop
-	}
-	depth, ok := request.Arguments["stackTraceDepth"].(float64)
-	if ok && depth > 0 {
-		s.args.stackTraceDepth = int(depth)
-	}
-	globals, ok := request.Arguments["showGlobalVariables"].(bool)
-	if ok {
-		s.args.showGlobalVariables = globals
-	}
+	s.setLaunchAttachArgs(request.Arguments)
 
 	var targetArgs []string
 	args, ok := request.Arguments["args"]
@@ -498,6 +562,12 @@ func (s *Server) onLaunchRequest(request *dap.LaunchRequest) {
 		}
 	}
 
+	if err := s.loadPrettyPrinters(request.Arguments); err != nil {
+		s.sendErrorResponse(request.Request,
+			FailedToLaunch, "Failed to launch", err.Error())
+		return
+	}
+
 	s.config.ProcessArgs = append([]string{program}, targetArgs...)
 	s.config.Debugger.WorkingDir = filepath.Dir(program)
 
@@ -655,6 +725,9 @@ func (s *Server) onThreadsRequest(request *dap.ThreadsRequest) {
 			} else {
 				threads[i].Name = fmt.Sprintf("%s@%d", loc.File, loc.Line)
 			}
+			if desc := waitDescription(s.debugger.Target(), g); desc != "" {
+				threads[i].Name += " [" + desc + "]"
+			}
 		}
 	}
 	response := &dap.ThreadsResponse{
@@ -664,10 +737,63 @@ func (s *Server) onThreadsRequest(request *dap.ThreadsRequest) {
 	s.send(response)
 }
 
+// waitDescription describes why g is parked, see
+// api.Goroutine.WaitDescription. The caller must hold the target mutex.
+func waitDescription(tgt *proc.Target, g *proc.G) string {
+	if g == nil || g.WaitReasonString() == "" {
+		return ""
+	}
+	return api.ConvertTargetGoroutine(tgt, g).WaitDescription()
+}
+
 // onAttachRequest sends a not-yet-implemented error response.
 // This is a mandatory request to support.
-func (s *Server) onAttachRequest(request *dap.AttachRequest) { // TODO V0
-	s.sendNotYetImplementedErrorResponse(request.Request)
+// onAttachRequest handles 'attach' requests, only the "local" mode, that
+// attaches to the process with the given processId, is supported.
+func (s *Server) onAttachRequest(request *attachRequest) {
+	// TODO(polina): Respond with an error if debug session is in progress?
+
+	mode, ok := request.Arguments["mode"]
+	if !ok || mode == "" {
+		mode = "local"
+	}
+	if mode != "local" {
+		s.sendErrorResponse(request.Request,
+			FailedtoAttach, "Failed to attach",
+			fmt.Sprintf("Unsupported 'mode' value %q in debug configuration.", mode))
+		return
+	}
+
+	pid, ok := request.Arguments["processId"].(float64)
+	if !ok || pid <= 0 {
+		s.sendErrorResponse(request.Request,
+			FailedtoAttach, "Failed to attach",
+			"The 'processId' attribute is missing in debug configuration.")
+		return
+	}
+
+	s.setLaunchAttachArgs(request.Arguments)
+
+	if err := s.loadPrettyPrinters(request.Arguments); err != nil {
+		s.sendErrorResponse(request.Request,
+			FailedtoAttach, "Failed to attach", err.Error())
+		return
+	}
+
+	s.config.Debugger.AttachPid = int(pid)
+
+	var err error
+	if s.debugger, err = debugger.New(&s.config.Debugger, nil); err != nil {
+		s.sendErrorResponse(request.Request,
+			FailedtoAttach, "Failed to attach", err.Error())
+		return
+	}
+
+	// Notify the client that the debugger is ready to start accepting
+	// configuration requests for setting breakpoints, etc. The client
+	// will end the configuration sequence with 'configurationDone'.
+	s.send(&dap.InitializedEvent{Event: *newEvent("initialized")})
+	s.send(&dap.AttachResponse{Response: *newResponse(request.Request)})
 }
 
 // onNextRequest handles 'next' request.
@@ -730,11 +856,26 @@ func (s *Server) onStackTraceRequest(request *dap.StackTraceRequest) {
 		} else {
 			stackFrames[i].Name = loc.Fn.Name
 		}
+		if frame.Inlined {
+			// Inlined calls don't have a frame of their own on the stack, keep
+			// their function name intact and let the client de-emphasize them.
+			stackFrames[i].PresentationHint = "subtle"
+		}
 		if loc.File != "<autogenerated>" {
 			stackFrames[i].Source = dap.Source{Name: filepath.Base(loc.File), Path: loc.File}
 		}
 		stackFrames[i].Column = 0
 	}
+	if len(stackFrames) > 0 {
+		// Show why a parked goroutine is blocked next to its topmost frame.
+		s.debugger.LockTarget()
+		g, _ := proc.FindGoroutine(s.debugger.Target(), goroutineID)
+		desc := waitDescription(s.debugger.Target(), g)
+		s.debugger.UnlockTarget()
+		if desc != "" {
+			stackFrames[0].Name += " [" + desc + "]"
+		}
+	}
 	if request.Arguments.StartFrame > 0 {
 		stackFrames = stackFrames[min(request.Arguments.StartFrame, len(stackFrames)):]
 	}
@@ -759,8 +900,7 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 
 	goid := sf.(stackFrame).goroutineID
 	frame := sf.(stackFrame).frameIndex
-	// TODO(polina): Support setting config via launch/attach args
-	cfg := proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+	cfg := defaultLoadConfig
 
 	// Retrieve arguments
 	args, err := s.debugger.FunctionArguments(goid, frame, 0, cfg)
@@ -768,7 +908,7 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 		s.sendErrorResponse(request.Request, UnableToListArgs, "Unable to list args", err.Error())
 		return
 	}
-	argScope := &proc.Variable{Name: "Arguments", Children: slicePtrVarToSliceVar(args)}
+	argScope := &fullyQualifiedVariable{&proc.Variable{Name: "Arguments", Children: slicePtrVarToSliceVar(args)}, "", true, false, sf.(stackFrame)}
 
 	// Retrieve local variables
 	locals, err := s.debugger.LocalVariables(goid, frame, 0, cfg)
@@ -776,7 +916,7 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 		s.sendErrorResponse(request.Request, UnableToListLocals, "Unable to list locals", err.Error())
 		return
 	}
-	locScope := &proc.Variable{Name: "Locals", Children: slicePtrVarToSliceVar(locals)}
+	locScope := &fullyQualifiedVariable{&proc.Variable{Name: "Locals", Children: slicePtrVarToSliceVar(locals)}, "", true, false, sf.(stackFrame)}
 
 	// TODO(polina): Annotate shadowed variables
 
@@ -811,10 +951,10 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 			globals[i].Name = strings.TrimPrefix(g.Name, currPkg+".")
 		}
 
-		globScope := &proc.Variable{
+		globScope := &fullyQualifiedVariable{&proc.Variable{
 			Name:     fmt.Sprintf("Globals (package %s)", currPkg),
 			Children: slicePtrVarToSliceVar(globals),
-		}
+		}, packagePrefix(currPkg), true, false, sf.(stackFrame)}
 		scopeGlobals := dap.Scope{Name: globScope.Name, VariablesReference: s.variableHandles.create(globScope)}
 		scopes = append(scopes, scopeGlobals)
 	}
@@ -825,6 +965,15 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 	s.send(response)
 }
 
+// packagePrefix returns the prefix used in expressions to refer to the
+// package variables of pkg.
+func packagePrefix(pkg string) string {
+	if strings.Contains(pkg, "/") {
+		return strconv.Quote(pkg) + "."
+	}
+	return pkg + "."
+}
+
 func slicePtrVarToSliceVar(vars []*proc.Variable) []proc.Variable {
 	r := make([]proc.Variable, len(vars))
 	for i := range vars {
@@ -841,6 +990,16 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 		s.sendErrorResponse(request.Request, UnableToLookupVariable, "Unable to lookup variable", fmt.Sprintf("unknown reference %d", request.Arguments.VariablesReference))
 		return
 	}
+	children := s.childrenToDAPVariables(v)
+	response := &dap.VariablesResponse{
+		Response: *newResponse(request.Request),
+		Body:     dap.VariablesResponseBody{Variables: children},
+	}
+	s.send(response)
+}
+
+// childrenToDAPVariables converts the children of v to dap.Variables.
+func (s *Server) childrenToDAPVariables(v *fullyQualifiedVariable) []dap.Variable {
 	children := make([]dap.Variable, 0)
 	// TODO(polina): check and handle if variable loaded incompletely
 	// https://github.com/go-delve/delve/blob/master/Documentation/api/ClientHowto.md#looking-into-variables
@@ -851,8 +1010,15 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 			// A map will have twice as many children as there are key-value elements.
 			kvIndex := i / 2
 			// Process children in pairs: even indices are map keys, odd indices are values.
-			key, keyref := s.convertVariable(&v.Children[i])
-			val, valref := s.convertVariable(&v.Children[i+1])
+			keyv, valv := &v.Children[i], &v.Children[i+1]
+			key, keyref := s.convertVariable(v.child(keyv, ""))
+			valexpr := ""
+			if keyref == 0 && v.fullyQualifiedNameOrExpr != "" && isExprKey(keyv) {
+				// the key could have been formatted by a pretty printer or a built-in formatter
+				rawkey, _ := s.convertVariableRaw(v.child(keyv, ""))
+				valexpr = fmt.Sprintf("%s[%s]", v.fullyQualifiedNameOrExpr, rawkey)
+			}
+			val, valref := s.convertVariable(v.child(valv, valexpr))
 			// If key or value or both are scalars, we can use
 			// a single variable to represet key:value format.
 			// Otherwise, we must return separate variables for both.
@@ -866,12 +1032,14 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 					Name:               fmt.Sprintf("[val %d]", kvIndex),
 					Value:              val,
 					VariablesReference: valref,
+					EvaluateName:       valexpr,
 				}
 				children = append(children, keyvar, valvar)
 			} else { // At least one is a scalar
 				kvvar := dap.Variable{
-					Name:  key,
-					Value: val,
+					Name:         key,
+					Value:        val,
+					EvaluateName: valexpr,
 				}
 				if keyref != 0 { // key is a type to be expanded
 					kvvar.Name = fmt.Sprintf("%s[%d]", kvvar.Name, kvIndex) // Make the name unique
@@ -885,32 +1053,124 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 	case reflect.Slice, reflect.Array:
 		children = make([]dap.Variable, len(v.Children))
 		for i := range v.Children {
-			c := &v.Children[i]
-			value, varref := s.convertVariable(c)
+			cv := v.child(&v.Children[i], v.elemExpr(i))
+			value, varref := s.convertVariable(cv)
 			children[i] = dap.Variable{
 				Name:               fmt.Sprintf("[%d]", i),
 				Value:              value,
 				VariablesReference: varref,
+				EvaluateName:       cv.fullyQualifiedNameOrExpr,
 			}
 		}
 	default:
 		children = make([]dap.Variable, len(v.Children))
 		for i := range v.Children {
 			c := &v.Children[i]
-			value, variablesReference := s.convertVariable(c)
+			cv := v.child(c, v.childExpr(c))
+			value, variablesReference := s.convertVariable(cv)
 			children[i] = dap.Variable{
 				Name:               c.Name,
 				Value:              value,
 				VariablesReference: variablesReference,
+				EvaluateName:       cv.fullyQualifiedNameOrExpr,
 			}
 		}
 	}
-	response := &dap.VariablesResponse{
-		Response: *newResponse(request.Request),
-		Body:     dap.VariablesResponseBody{Variables: children},
-		// TODO(polina): support evaluateName field
+	return children
+}
+
+// childEvaluateName returns the expression for the child of v called name
+// by childrenToDAPVariables, or an empty string if there is none.
+// Unlike childrenToDAPVariables it doesn't create variable handles.
+func (s *Server) childEvaluateName(v *fullyQualifiedVariable, name string) string {
+	switch v.Kind {
+	case reflect.Map:
+		if v.fullyQualifiedNameOrExpr == "" {
+			return ""
+		}
+		for i := 0; i < len(v.Children); i += 2 {
+			keyv := &v.Children[i]
+			if !isExprKey(keyv) {
+				continue
+			}
+			// scalar keys do not have a variables reference, the name of the
+			// child is the key as shown to the client but the expression must
+			// use its raw value
+			if key, _ := s.convertVariable(v.child(keyv, "")); key == name {
+				rawkey, _ := s.convertVariableRaw(v.child(keyv, ""))
+				return fmt.Sprintf("%s[%s]", v.fullyQualifiedNameOrExpr, rawkey)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Children {
+			if fmt.Sprintf("[%d]", i) == name {
+				return v.elemExpr(i)
+			}
+		}
+	default:
+		for i := range v.Children {
+			if c := &v.Children[i]; c.Name == name {
+				return v.childExpr(c)
+			}
+		}
 	}
-	s.send(response)
+	return ""
+}
+
+// child returns c, a child of v, as a fullyQualifiedVariable that can be
+// referred to with expr.
+func (v *fullyQualifiedVariable) child(c *proc.Variable, expr string) *fullyQualifiedVariable {
+	return &fullyQualifiedVariable{c, expr, false, false, v.frame}
+}
+
+// childExpr returns the expression for c, a child of v that is not an
+// element of a map, slice or array, or an empty string if there is none.
+func (v *fullyQualifiedVariable) childExpr(c *proc.Variable) string {
+	if v.isSynthetic {
+		// children of the target returned by a pretty printer are referred
+		// to using their address
+		if c.Flags&proc.VariableConstant != 0 || c.Addr == 0 || c.Unreadable != nil {
+			return ""
+		}
+		return varAddrExpr(api.PrettyTypeName(c.DwarfType), c.Addr)
+	}
+	if v.isScope {
+		return v.fullyQualifiedNameOrExpr + c.Name
+	}
+	if v.fullyQualifiedNameOrExpr == "" {
+		return ""
+	}
+	switch v.Kind {
+	case reflect.Struct:
+		return v.fullyQualifiedNameOrExpr + "." + c.Name
+	case reflect.Ptr:
+		return "(*" + v.fullyQualifiedNameOrExpr + ")"
+	case reflect.Interface:
+		return fmt.Sprintf("%s.(%s)", v.fullyQualifiedNameOrExpr, c.TypeString())
+	}
+	return ""
+}
+
+// elemExpr returns the expression for the i-th element of v, a slice or an
+// array, or an empty string if there is none.
+func (v *fullyQualifiedVariable) elemExpr(i int) string {
+	if v.fullyQualifiedNameOrExpr == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s[%d]", v.fullyQualifiedNameOrExpr, i)
+}
+
+// isExprKey returns true if the value of the map key k returned by
+// convertVariable is also an expression evaluating to k.
+func isExprKey(k *proc.Variable) bool {
+	switch k.Kind {
+	case reflect.String:
+		return k.Len == int64(len(constant.StringVal(k.Value)))
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
 }
 
 // convertVariable converts api.Variable to dap.Variable value and reference.
@@ -921,7 +1181,31 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 // can be issued to get the elements of the compound variable. As a custom, a zero
 // reference, reminiscent of a zero pointer, is used to indicate that a scalar
 // variable cannot be "dereferenced" to get its elements (as there are none).
-func (s *Server) convertVariable(v *proc.Variable) (value string, variablesReference int) {
+// Variables with a type that has a pretty printer are converted using it,
+// values of some standard library types are formatted by the built-in
+// formatters of package api.
+func (s *Server) convertVariable(v *fullyQualifiedVariable) (value string, variablesReference int) {
+	if value, variablesReference, ok := s.prettyPrintVariable(v); ok {
+		return value, variablesReference
+	}
+	value, variablesReference = s.convertVariableRaw(v)
+	if s.config.DisableBuiltinFormatters {
+		return value, variablesReference
+	}
+	if pretty := api.FormatVariable(v.Variable); pretty != "" {
+		// values formatted by the built-in formatters keep their children
+		value = pretty
+	}
+	return value, variablesReference
+}
+
+// convertVariableRaw is like convertVariable but ignores pretty printers
+// and built-in formatters.
+func (s *Server) convertVariableRaw(v *fullyQualifiedVariable) (value string, variablesReference int) {
+	if v.Flags&proc.VariableOptimizedOut != 0 {
+		value = "<optimized out>"
+		return
+	}
 	if v.Unreadable != nil {
 		value = fmt.Sprintf("unreadable <%v>", v.Unreadable)
 		return
@@ -1025,7 +1309,7 @@ func (s *Server) convertVariable(v *proc.Variable) (value string, variablesRefer
 		}
 		fallthrough
 	default: // Struct, complex, scalar
-		vvalue := api.VariableValueAsString(v)
+		vvalue := api.VariableValueAsString(v.Variable)
 		if vvalue != "" {
 			value = vvalue
 		} else {
@@ -1074,16 +1358,108 @@ func (s *Server) onReverseContinueRequest(request *dap.ReverseContinueRequest) {
 	s.sendNotYetImplementedErrorResponse(request.Request)
 }
 
-// onSetVariableRequest sends a not-yet-implemented error response.
-// Capability 'supportsSetVariable' is not set 'initialize' response.
-func (s *Server) onSetVariableRequest(request *dap.SetVariableRequest) { // TODO V0
-	s.sendNotYetImplementedErrorResponse(request.Request)
+// onSetVariableRequest handles 'setVariable' requests.
+// Capability 'supportsSetVariable' is set in 'initialize' response.
+// Assignments that need to call functions in the target, to allocate
+// memory for string and composite literals, for non-pointer values
+// assigned to interfaces and for new map keys, are done by injecting
+// function calls, see assignWithCalls.
+func (s *Server) onSetVariableRequest(request *dap.SetVariableRequest) {
+	arg := request.Arguments
+	v, ok := s.variableHandles.get(arg.VariablesReference)
+	if !ok {
+		s.sendErrorResponse(request.Request, UnableToSetVariable, "Unable to set variable", fmt.Sprintf("unknown reference %d", arg.VariablesReference))
+		return
+	}
+	expr := s.childEvaluateName(v, arg.Name)
+	if expr == "" {
+		s.sendErrorResponse(request.Request, UnableToSetVariable, "Unable to set variable", fmt.Sprintf("can not set %s", arg.Name))
+		return
+	}
+
+	value, typ, ref, err := s.setAndReload(v.frame, expr, arg.Value)
+	if err != nil {
+		s.sendErrorResponse(request.Request, UnableToSetVariable, "Unable to set variable", err.Error())
+		return
+	}
+	response := &dap.SetVariableResponse{Response: *newResponse(request.Request)}
+	response.Body.Value = value
+	response.Body.Type = typ
+	response.Body.VariablesReference = ref
+	s.send(response)
 }
 
-// onSetExpression sends a not-yet-implemented error response.
-// Capability 'supportsSetExpression' is not set 'initialize' response.
+// onSetExpressionRequest handles 'setExpression' requests.
+// Capability 'supportsSetExpression' is set in 'initialize' response.
 func (s *Server) onSetExpressionRequest(request *dap.SetExpressionRequest) {
-	s.sendNotYetImplementedErrorResponse(request.Request)
+	arg := request.Arguments
+	// Without a frame the expression is evaluated in the topmost frame of
+	// the current goroutine.
+	frame := stackFrame{-1, 0}
+	if arg.FrameId != 0 {
+		sf, ok := s.stackFrameHandles.get(arg.FrameId)
+		if !ok {
+			s.sendErrorResponse(request.Request, UnableToSetVariable, "Unable to set expression", fmt.Sprintf("unknown frame id %d", arg.FrameId))
+			return
+		}
+		frame = sf.(stackFrame)
+	}
+
+	value, typ, ref, err := s.setAndReload(frame, arg.Expression, arg.Value)
+	if err != nil {
+		s.sendErrorResponse(request.Request, UnableToSetVariable, "Unable to set expression", err.Error())
+		return
+	}
+	response := &dap.SetExpressionResponse{Response: *newResponse(request.Request)}
+	response.Body.Value = value
+	response.Body.Type = typ
+	response.Body.VariablesReference = ref
+	s.send(response)
+}
+
+// setAndReload assigns value to expr, evaluated in frame, and returns its
+// new value, type and variables reference.
+func (s *Server) setAndReload(frame stackFrame, expr, value string) (string, string, int, error) {
+	err := s.debugger.SetVariableInScope(frame.goroutineID, frame.frameIndex, 0, expr, value)
+	if proc.IsFuncCallNotAllowed(err) {
+		err = s.assignWithCalls(frame, expr, value)
+	}
+	if err != nil {
+		return "", "", 0, err
+	}
+	v, err := s.debugger.EvalVariableInScope(frame.goroutineID, frame.frameIndex, 0, expr, defaultLoadConfig)
+	if err != nil {
+		return "", "", 0, err
+	}
+	newValue, ref := s.convertVariable(&fullyQualifiedVariable{v, expr, false, false, frame})
+	return newValue, api.PrettyTypeName(v.DwarfType), ref, nil
+}
+
+// assignWithCalls assigns value to expr, evaluated in frame, injecting
+// the function calls needed to allocate memory in the target, like the
+// 'call expr = value' command of the terminal client. Function calls can
+// only be injected in the topmost frame of a goroutine.
+// If the target stops for a different reason while running the injected
+// calls the corresponding event is sent to the client.
+func (s *Server) assignWithCalls(frame stackFrame, expr, value string) error {
+	if frame.frameIndex != 0 {
+		return fmt.Errorf("assigning %s to %s needs to call functions in the target, which is only supported in the topmost frame", value, expr)
+	}
+	goid := frame.goroutineID
+	if goid < 0 {
+		goid = 0 // selected goroutine
+	}
+	state, err := s.debugger.Command(&api.DebuggerCommand{
+		Name:                 api.Call,
+		Expr:                 expr + " = " + value,
+		ReturnInfoLoadConfig: api.LoadConfigFromProc(&defaultLoadConfig),
+		GoroutineID:          goid,
+	})
+	if _, isexited := err.(proc.ErrProcessExited); isexited || err == nil && state.Exited || err == nil && s.debugger.StopReason() != proc.StopCallReturned {
+		s.sendStopEvent(state, err)
+		return fmt.Errorf("the target stopped while assigning %s to %s", value, expr)
+	}
+	return err
 }
 
 // onLoadedSourcesRequest sends a not-yet-implemented error response.
@@ -1182,6 +1558,13 @@ func (s *Server) doCommand(command string) {
 	}
 
 	state, err := s.debugger.Command(&api.DebuggerCommand{Name: command})
+	s.sendStopEvent(state, err)
+}
+
+// sendStopEvent sends the event describing why the target stopped after
+// running a command, state and err are the values returned by
+// debugger.Command.
+func (s *Server) sendStopEvent(state *api.DebuggerState, err error) {
 	if _, isexited := err.(proc.ErrProcessExited); isexited || err == nil && state.Exited {
 		e := &dap.TerminatedEvent{Event: *newEvent("terminated")}
 		s.send(e)
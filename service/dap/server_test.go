@@ -1041,6 +1041,118 @@ func TestScopesAndVariablesRequests2(t *testing.T) {
 	})
 }
 
+// TestPrettyPrinters loads the pretty printers in _fixtures/pretty_printer.star
+// through the launch arguments and checks how they change the variables of
+// testvariables2, including the synthetic children returned by a printer.
+func TestPrettyPrinters(t *testing.T) {
+	runTest(t, "testvariables2", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client,
+			// Launch
+			func() {
+				client.LaunchRequestWithArgs(map[string]interface{}{
+					"mode": "exec", "program": fixture.Path,
+					"prettyPrinters": []string{filepath.Join(protest.FindFixturesDir(), "pretty_printer.star")}})
+			},
+			// Breakpoints are set within the program
+			fixture.Source, []int{},
+			[]onBreakpoint{{
+				// Stop at line 317
+				execute: func() {},
+			}, {
+				// Stop at line 322
+				execute: func() {
+					client.StackTraceRequest(1, 0, 20)
+					stack := client.ExpectStackTraceResponse(t)
+					expectStackFrames(t, stack, 322, 1000, 3, 3)
+
+					client.VariablesRequest(1001)
+					locals := client.ExpectVariablesResponse(t)
+
+					// Printer returning a string, the fields are still listed.
+					ref := expectVarExact(t, locals, -1, "as1", "A=1 B=1", hasChildren)
+					if ref > 0 {
+						client.VariablesRequest(ref)
+						as1 := client.ExpectVariablesResponse(t)
+						expectChildren(t, as1, "as1", 2)
+						expectVarExact(t, as1, 0, "A", "1", noChildren)
+						expectVarExact(t, as1, 1, "B", "1", noChildren)
+					}
+
+					// Printer returning synthetic children.
+					ref = expectVarExact(t, locals, -1, "c1", "<main.cstruct>", hasChildren)
+					if ref <= 0 {
+						return
+					}
+					client.VariablesRequest(ref)
+					c1 := client.ExpectVariablesResponse(t)
+					ref = expectVarRegex(t, c1, 0, "pb", "<\\*main\\.bstruct>\\(0x[0-9a-f]+\\)", hasChildren)
+					if ref <= 0 {
+						return
+					}
+					client.VariablesRequest(ref)
+					pb := client.ExpectVariablesResponse(t)
+					expectChildren(t, pb, "c1.pb", 1)
+					ref = expectVarExact(t, pb, 0, "", "<main.bstruct>", hasChildren)
+					if ref <= 0 {
+						return
+					}
+					client.VariablesRequest(ref)
+					pbval := client.ExpectVariablesResponse(t)
+					expectChildren(t, pbval, "*c1.pb", 2)
+					expectVarExact(t, pbval, 0, "first", "A=1 B=2", hasChildren)
+					expectVarExact(t, pbval, 1, "sum", "3", noChildren)
+					if got := pbval.Body.Variables[0].EvaluateName; !regexp.MustCompile(`^\(\*\(\*"main\.astruct"\)\(0x[0-9a-f]+\)\)$`).MatchString(got) {
+						t.Errorf("\ngot  first.EvaluateName=%q\nwant (*(*\"main.astruct\")(0x...))", got)
+					}
+					if got := pbval.Body.Variables[1].EvaluateName; got != "" {
+						t.Errorf("\ngot  sum.EvaluateName=%q\nwant \"\"", got)
+					}
+				},
+				disconnect: true,
+			}})
+	})
+}
+
+// TestBuiltinFormatters executes to a breakpoint and tests that values of
+// standard library types are displayed by the built-in formatters and keep
+// their children.
+func TestBuiltinFormatters(t *testing.T) {
+	runTest(t, "stdlibtypes", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client,
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Breakpoints are set within the program
+			fixture.Source, []int{},
+			[]onBreakpoint{{
+				// Stop at line 38
+				execute: func() {
+					client.StackTraceRequest(1, 0, 20)
+					stack := client.ExpectStackTraceResponse(t)
+					expectStackFrames(t, stack, 38, 1000, 3, 3)
+
+					client.VariablesRequest(1001)
+					locals := client.ExpectVariablesResponse(t)
+
+					expectVarExact(t, locals, -1, "tm", "2020-09-13T12:26:40.0000005Z UTC", hasChildren)
+					expectVarExact(t, locals, -1, "d", "1.5s", noChildren)
+					expectVarExact(t, locals, -1, "ip4", "192.168.1.1", hasChildren)
+					expectVarExact(t, locals, -1, "bi", "-123456789012345678901234567890", hasChildren)
+					expectVarExact(t, locals, -1, "lockedmu", "locked", hasChildren)
+					expectVarExact(t, locals, -1, "av", "(main.astruct) {A: 1, B: 2}", hasChildren)
+					ref := expectVarExact(t, locals, -1, "rv", "(main.astruct) {A: 3, B: 4}", hasChildren)
+					if ref > 0 {
+						client.VariablesRequest(ref)
+						rv := client.ExpectVariablesResponse(t)
+						expectChildren(t, rv, "rv", 3)
+					}
+				},
+				disconnect: true,
+			}})
+	})
+}
+
 // TestGlobalScopeAndVariables launches the program with showGlobalVariables
 // arg set, executes to a breakpoint in the main package and tests that global
 // package main variables got loaded. It then steps into a function
@@ -1108,6 +1220,95 @@ func TestGlobalScopeAndVariables(t *testing.T) {
 	})
 }
 
+// TestSetVariable executes to a breakpoint and tests 'setVariable' and
+// 'setExpression' requests on arguments, locals and their children.
+func TestSetVariable(t *testing.T) {
+	runTest(t, "testvariables", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client,
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Breakpoints are set within the program
+			fixture.Source, []int{},
+			[]onBreakpoint{{
+				execute: func() {
+					client.StackTraceRequest(1, 0, 20)
+					stack := client.ExpectStackTraceResponse(t)
+					frameID := stack.Body.StackFrames[0].Id
+
+					client.ScopesRequest(frameID)
+					scopes := client.ExpectScopesResponse(t)
+					argsRef := scopes.Body.Scopes[0].VariablesReference
+					localsRef := scopes.Body.Scopes[1].VariablesReference
+
+					// Locals
+
+					client.SetVariableRequest(localsRef, "a2", "7")
+					setResp := client.ExpectSetVariableResponse(t)
+					if setResp.Body.Value != "7" || setResp.Body.Type != "int" {
+						t.Errorf("\ngot  %#v\nwant Value=7 Type=int", setResp)
+					}
+					// Variables are loaded when the scopes are requested
+					client.ScopesRequest(frameID)
+					scopes = client.ExpectScopesResponse(t)
+					argsRef = scopes.Body.Scopes[0].VariablesReference
+					localsRef = scopes.Body.Scopes[1].VariablesReference
+					client.VariablesRequest(localsRef)
+					locals := client.ExpectVariablesResponse(t)
+					expectVarExact(t, locals, -1, "a2", "7", noChildren)
+
+					client.SetVariableRequest(localsRef, "nosuchvar", "1")
+					errResp := client.ExpectErrorResponse(t)
+					if errResp.Message != "Unable to set variable" {
+						t.Errorf("\ngot  %#v\nwant Message=\"Unable to set variable\"", errResp)
+					}
+
+					// Assignments that allocate memory in the target inject function calls
+					if goversion.VersionAfterOrEqual(runtime.Version(), 1, 11) && runtime.GOARCH == "amd64" {
+						client.SetVariableRequest(localsRef, "a1", `"abc"`)
+						setResp = client.ExpectSetVariableResponse(t)
+						if setResp.Body.Value != `"abc"` || setResp.Body.Type != "string" {
+							t.Errorf("\ngot  %#v\nwant Value=\"abc\" Type=string", setResp)
+						}
+					}
+
+					// Children of arguments
+
+					client.VariablesRequest(argsRef)
+					args := client.ExpectVariablesResponse(t)
+					ref := expectVarExact(t, args, 1, "bar", `<main.FooBar>`, hasChildren)
+					if ref > 0 {
+						client.SetVariableRequest(ref, "Baz", "42")
+						setResp = client.ExpectSetVariableResponse(t)
+						if setResp.Body.Value != "42" {
+							t.Errorf("\ngot  %#v\nwant Value=42", setResp)
+						}
+					}
+
+					// Expressions
+
+					client.SetExpressionRequest(frameID, "bar.Baz", "11")
+					setExprResp := client.ExpectSetExpressionResponse(t)
+					if setExprResp.Body.Value != "11" || setExprResp.Body.Type != "int" {
+						t.Errorf("\ngot  %#v\nwant Value=11 Type=int", setExprResp)
+					}
+					client.ScopesRequest(frameID)
+					scopes = client.ExpectScopesResponse(t)
+					client.VariablesRequest(scopes.Body.Scopes[0].VariablesReference)
+					args = client.ExpectVariablesResponse(t)
+					ref = expectVarExact(t, args, 1, "bar", `<main.FooBar>`, hasChildren)
+					if ref > 0 {
+						client.VariablesRequest(ref)
+						bar := client.ExpectVariablesResponse(t)
+						expectVarExact(t, bar, 0, "Baz", "11", noChildren)
+					}
+				},
+				disconnect: true,
+			}})
+	})
+}
+
 // Tests that 'stackTraceDepth' from LaunchRequest is parsed and passed to
 // stacktrace requests handlers.
 func TestLaunchRequestWithStackTraceDepth(t *testing.T) {
@@ -1621,9 +1822,6 @@ func TestRequiredNotYetImplementedResponses(t *testing.T) {
 			seqCnt++
 		}
 
-		client.AttachRequest()
-		expectNotYetImplemented("attach")
-
 		client.PauseRequest()
 		expectNotYetImplemented("pause")
 
@@ -1660,12 +1858,6 @@ func TestOptionalNotYetImplementedResponses(t *testing.T) {
 		client.ReverseContinueRequest()
 		expectNotYetImplemented("reverseContinue")
 
-		client.SetVariableRequest()
-		expectNotYetImplemented("setVariable")
-
-		client.SetExpressionRequest()
-		expectNotYetImplemented("setExpression")
-
 		client.LoadedSourcesRequest()
 		expectNotYetImplemented("loadedSources")
 
@@ -1775,6 +1967,50 @@ func TestBadLaunchRequests(t *testing.T) {
 	})
 }
 
+func TestBadAttachRequest(t *testing.T) {
+	runTest(t, "increment", func(client *daptest.Client, fixture protest.Fixture) {
+		seqCnt := 1
+		expectFailedToAttachWithMessage := func(response *dap.ErrorResponse, errmsg string) {
+			t.Helper()
+			if response.RequestSeq != seqCnt {
+				t.Errorf("RequestSeq got %d, want %d", response.RequestSeq, seqCnt)
+			}
+			if response.Command != "attach" {
+				t.Errorf("Command got %q, want \"attach\"", response.Command)
+			}
+			if response.Message != "Failed to attach" {
+				t.Errorf("Message got %q, want \"Failed to attach\"", response.Message)
+			}
+			if response.Body.Error.Id != 3001 {
+				t.Errorf("Id got %d, want 3001", response.Body.Error.Id)
+			}
+			if response.Body.Error.Format != errmsg {
+				t.Errorf("\ngot  %q\nwant %q", response.Body.Error.Format, errmsg)
+			}
+			seqCnt++
+		}
+
+		client.AttachRequest()
+		expectFailedToAttachWithMessage(client.ExpectErrorResponse(t),
+			"Failed to attach: The 'processId' attribute is missing in debug configuration.")
+
+		client.AttachRequestWithArgs(map[string]interface{}{"mode": "remote", "processId": 1})
+		expectFailedToAttachWithMessage(client.ExpectErrorResponse(t),
+			"Failed to attach: Unsupported 'mode' value \"remote\" in debug configuration.")
+
+		client.AttachRequestWithArgs(map[string]interface{}{"mode": "local", "processId": 1, "prettyPrinters": "printers.star"})
+		expectFailedToAttachWithMessage(client.ExpectErrorResponse(t),
+			"Failed to attach: 'prettyPrinters' attribute 'printers.star' in debug configuration is not an array.")
+
+		client.AttachRequestWithArgs(map[string]interface{}{"mode": "local", "processId": 1, "prettyPrinters": []int{1}})
+		expectFailedToAttachWithMessage(client.ExpectErrorResponse(t),
+			"Failed to attach: value '1' in 'prettyPrinters' attribute in debug configuration is not a string.")
+
+		client.DisconnectRequest()
+		client.ExpectDisconnectResponse(t)
+	})
+}
+
 func TestBadlyFormattedMessageToServer(t *testing.T) {
 	runTest(t, "increment", func(client *daptest.Client, fixture protest.Fixture) {
 		// Send a badly formatted message to the server, and expect it to close the
@@ -0,0 +1,109 @@
+package dap
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"reflect"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/terminal/starbind"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
+)
+
+// starlarkContext is the context of the starlark scripts that define pretty
+// printers. The scripts run without a client connected to the debugger,
+// so the builtins that call API functions are not defined, and pretty
+// printers can only use the values of the variables they are called with.
+type starlarkContext struct{}
+
+var _ starbind.Context = starlarkContext{}
+
+func (ctx starlarkContext) Client() service.Client {
+	return nil
+}
+
+func (ctx starlarkContext) RegisterCommand(name, helpMsg string, fn func(args string) error) {
+}
+
+func (ctx starlarkContext) CallCommand(cmdstr string) error {
+	return errors.New("commands are not available in DAP mode")
+}
+
+func (ctx starlarkContext) Scope() api.EvalScope {
+	return api.EvalScope{GoroutineID: -1}
+}
+
+func (ctx starlarkContext) LoadConfig() api.LoadConfig {
+	return *api.LoadConfigFromProc(&defaultLoadConfig)
+}
+
+// prettyPrintVariable converts v using the pretty printer registered for
+// its type. Returns false if there is none.
+func (s *Server) prettyPrintVariable(v *fullyQualifiedVariable) (value string, variablesReference int, ok bool) {
+	typ := api.PrettyTypeName(v.DwarfType)
+	if s.starlarkEnv == nil || v.Unreadable != nil || v.Flags&proc.VariableOptimizedOut != 0 || !s.starlarkEnv.HasPrettyPrinter(typ) {
+		return "", 0, false
+	}
+	value, children, ok := s.starlarkEnv.CallPrettyPrinter(typ, api.ConvertVar(v.Variable, !s.config.DisableBuiltinFormatters))
+	if !ok {
+		return "", 0, false
+	}
+	if children == nil {
+		// only the value is replaced, the raw children are still available
+		pretty := value
+		value, variablesReference = s.convertVariableRaw(v)
+		if pretty != "" {
+			value = pretty
+		}
+		return value, variablesReference, true
+	}
+	if value == "" {
+		value = "<" + typ + ">"
+	}
+	if len(children) == 0 {
+		return value, 0, true
+	}
+	// The children are held by a placeholder without a type, its only use is
+	// to list them in a variables response.
+	synthetic := &proc.Variable{Name: v.Name, Kind: reflect.Struct, Len: int64(len(children))}
+	synthetic.Children = make([]proc.Variable, len(children))
+	for i := range children {
+		synthetic.Children[i] = *s.syntheticChild(v.frame, &children[i])
+	}
+	return value, s.variableHandles.create(&fullyQualifiedVariable{synthetic, "", false, true, v.frame}), true
+}
+
+// syntheticChild converts c, a child returned by a pretty printer, into a
+// proc.Variable. Variables of the target are loaded again using their
+// address, other values are constants.
+func (s *Server) syntheticChild(frame stackFrame, c *api.Variable) *proc.Variable {
+	if c.Flags&api.VariableConstant == 0 && c.Addr != 0 {
+		v, err := s.debugger.EvalVariableInScope(frame.goroutineID, frame.frameIndex, 0, varAddrExpr(c.Type, c.Addr), defaultLoadConfig)
+		if err != nil {
+			v = &proc.Variable{Unreadable: err}
+		}
+		v.Name = c.Name
+		return v
+	}
+	r := &proc.Variable{Name: c.Name, Kind: c.Kind, Flags: proc.VariableConstant}
+	switch c.Kind {
+	case reflect.String:
+		r.Value = constant.MakeString(c.Value)
+		r.Len = int64(len(c.Value))
+	case reflect.Bool:
+		r.Value = constant.MakeBool(c.Value == "true")
+	case reflect.Int:
+		r.Value = constant.MakeFromLiteral(c.Value, token.INT, 0)
+	case reflect.Float64:
+		r.Value = constant.MakeFromLiteral(c.Value, token.FLOAT, 0)
+	}
+	return r
+}
+
+// varAddrExpr returns an expression for the variable of type typ at addr.
+func varAddrExpr(typ string, addr uint64) string {
+	return fmt.Sprintf("(*(*%q)(%#x))", typ, addr)
+}
@@ -3,9 +3,11 @@ package debugger
 import (
 	"bytes"
 	"debug/dwarf"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"go/parser"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -62,6 +64,12 @@ type Debugger struct {
 
 	stopRecording func() error
 	recordMutex   sync.Mutex
+
+	dumpState *core.DumpState // state of the last core dump, protected by dumpMutex
+	dumpMutex sync.Mutex
+
+	stopProfile  chan struct{} // closed by Halt to end CPUProfile, protected by profileMutex
+	profileMutex sync.Mutex
 }
 
 type ExecuteKind int
@@ -90,6 +98,10 @@ type Config struct {
 	// CoreFile specifies the path to the core dump to open.
 	CoreFile string
 
+	// Inspect is true if the executable should be opened for static
+	// inspection, without running it.
+	Inspect bool
+
 	// Backend specifies the debugger backend.
 	Backend string
 
@@ -100,6 +112,18 @@ type Config struct {
 	// when resolving external debug info files.
 	DebugInfoDirectories []string
 
+	// BinaryInfoCache is the configuration of the on-disk cache of debug
+	// information.
+	BinaryInfoCache proc.BinaryInfoCacheConfig
+
+	// Sysroot is prepended to the paths of the shared libraries mapped in a
+	// core file.
+	Sysroot string
+
+	// SolibSearchPath is the list of directories to look for when resolving
+	// the shared libraries mapped in a core file.
+	SolibSearchPath []string
+
 	// CheckGoVersion is true if the debugger should check the version of Go
 	// used to compile the executable and refuse to work on incompatible
 	// versions.
@@ -147,16 +171,25 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 		}
 		d.target = p
 
+	case d.config.Inspect:
+		d.log.Infof("opening executable %s for inspection", d.processArgs[0])
+		p, err := core.OpenExecutable(d.processArgs[0], d.config.DebugInfoDirectories, d.config.BinaryInfoCache)
+		if err != nil {
+			err = go11DecodeErrorCheck(err)
+			return nil, err
+		}
+		d.target = p
+
 	case d.config.CoreFile != "":
 		var p *proc.Target
 		var err error
 		switch d.config.Backend {
 		case "rr":
 			d.log.Infof("opening trace %s", d.config.CoreFile)
-			p, err = gdbserial.Replay(d.config.CoreFile, false, false, d.config.DebugInfoDirectories)
+			p, err = gdbserial.Replay(d.config.CoreFile, false, false, d.config.DebugInfoDirectories, d.config.BinaryInfoCache)
 		default:
 			d.log.Infof("opening core file %s (executable %s)", d.config.CoreFile, d.processArgs[0])
-			p, err = core.OpenCore(d.config.CoreFile, d.processArgs[0], d.config.DebugInfoDirectories)
+			p, err = core.OpenCore(d.config.CoreFile, d.processArgs[0], d.config.DebugInfoDirectories, d.config.BinaryInfoCache, d.config.Sysroot, d.config.SolibSearchPath)
 		}
 		if err != nil {
 			err = go11DecodeErrorCheck(err)
@@ -197,6 +230,8 @@ func (d *Debugger) canRestart() bool {
 		return false
 	case d.config.CoreFile != "":
 		return false
+	case d.config.Inspect:
+		return false
 	default:
 		return true
 	}
@@ -224,9 +259,9 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 	}
 	switch d.config.Backend {
 	case "native":
-		return native.Launch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects)
+		return native.Launch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.BinaryInfoCache, d.config.TTY, d.config.Redirects)
 	case "lldb":
-		return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects))
+		return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.BinaryInfoCache, d.config.TTY, d.config.Redirects))
 	case "rr":
 		if d.target != nil {
 			// restart should not call us if the backend is 'rr'
@@ -268,9 +303,9 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 
 	case "default":
 		if runtime.GOOS == "darwin" {
-			return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects))
+			return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.BinaryInfoCache, d.config.TTY, d.config.Redirects))
 		}
-		return native.Launch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects)
+		return native.Launch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.BinaryInfoCache, d.config.TTY, d.config.Redirects)
 	default:
 		return nil, fmt.Errorf("unknown backend %q", d.config.Backend)
 	}
@@ -300,21 +335,21 @@ func (d *Debugger) recordingRun(run func() (string, error)) (*proc.Target, error
 		return nil, err
 	}
 
-	return gdbserial.Replay(tracedir, false, true, d.config.DebugInfoDirectories)
+	return gdbserial.Replay(tracedir, false, true, d.config.DebugInfoDirectories, d.config.BinaryInfoCache)
 }
 
 // Attach will attach to the process specified by 'pid'.
 func (d *Debugger) Attach(pid int, path string) (*proc.Target, error) {
 	switch d.config.Backend {
 	case "native":
-		return native.Attach(pid, d.config.DebugInfoDirectories)
+		return native.Attach(pid, d.config.DebugInfoDirectories, d.config.BinaryInfoCache)
 	case "lldb":
-		return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, d.config.DebugInfoDirectories))
+		return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, d.config.DebugInfoDirectories, d.config.BinaryInfoCache))
 	case "default":
 		if runtime.GOOS == "darwin" {
-			return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, d.config.DebugInfoDirectories))
+			return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, d.config.DebugInfoDirectories, d.config.BinaryInfoCache))
 		}
-		return native.Attach(pid, d.config.DebugInfoDirectories)
+		return native.Attach(pid, d.config.DebugInfoDirectories, d.config.BinaryInfoCache)
 	default:
 		return nil, fmt.Errorf("unknown backend %q", d.config.Backend)
 	}
@@ -524,7 +559,7 @@ func (d *Debugger) State(nowait bool) (*api.DebuggerState, error) {
 	return d.state(nil)
 }
 
-func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error) {
+func (d *Debugger) state(retLoadCfg *api.LoadConfig) (*api.DebuggerState, error) {
 	if _, err := d.target.Valid(); err != nil {
 		return nil, err
 	}
@@ -535,7 +570,7 @@ func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error
 	)
 
 	if d.target.SelectedGoroutine() != nil {
-		goroutine = api.ConvertGoroutine(d.target.SelectedGoroutine())
+		goroutine = api.ConvertTargetGoroutine(d.target, d.target.SelectedGoroutine())
 	}
 
 	exited := false
@@ -552,7 +587,7 @@ func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error
 		th := api.ConvertThread(thread)
 
 		if retLoadCfg != nil {
-			th.ReturnValues = api.ConvertVars(thread.Common().ReturnValues(*retLoadCfg))
+			th.ReturnValues = api.ConvertVars(thread.Common().ReturnValues(*api.LoadConfigToProc(retLoadCfg)), !retLoadCfg.DisableBuiltinFormatters)
 		}
 
 		state.Threads = append(state.Threads, th)
@@ -874,6 +909,13 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 		// access the process directly.
 		d.log.Debug("halting")
 
+		d.profileMutex.Lock()
+		if d.stopProfile != nil {
+			close(d.stopProfile)
+			d.stopProfile = nil
+		}
+		d.profileMutex.Unlock()
+
 		d.recordMutex.Lock()
 		if d.stopRecording == nil {
 			err = d.target.RequestManualStop()
@@ -881,6 +923,10 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 		d.recordMutex.Unlock()
 	}
 
+	if d.config.Inspect && command.Name != api.Halt {
+		return nil, core.ErrInspectOnly
+	}
+
 	withBreakpointInfo := true
 
 	d.targetMutex.Lock()
@@ -995,7 +1041,7 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 		}
 		return nil, err
 	}
-	state, stateErr := d.state(api.LoadConfigToProc(command.ReturnInfoLoadConfig))
+	state, stateErr := d.state(command.ReturnInfoLoadConfig)
 	if stateErr != nil {
 		return state, stateErr
 	}
@@ -1033,7 +1079,7 @@ func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState) error
 			if err != nil {
 				return err
 			}
-			bpi.Goroutine = api.ConvertGoroutine(g)
+			bpi.Goroutine = api.ConvertTargetGoroutine(d.target, g)
 		}
 
 		if bp.Stacktrace > 0 {
@@ -1070,17 +1116,17 @@ func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState) error
 			if err != nil {
 				bpi.Variables[i] = api.Variable{Name: bp.Variables[i], Unreadable: fmt.Sprintf("eval error: %v", err)}
 			} else {
-				bpi.Variables[i] = *api.ConvertVar(v)
+				bpi.Variables[i] = *api.ConvertVar(v, true)
 			}
 		}
 		if bp.LoadArgs != nil {
 			if vars, err := s.FunctionArguments(*api.LoadConfigToProc(bp.LoadArgs)); err == nil {
-				bpi.Arguments = api.ConvertVars(vars)
+				bpi.Arguments = api.ConvertVars(vars, !bp.LoadArgs.DisableBuiltinFormatters)
 			}
 		}
 		if bp.LoadLocals != nil {
 			if locals, err := s.LocalVariables(*api.LoadConfigToProc(bp.LoadLocals)); err == nil {
-				bpi.Locals = api.ConvertVars(locals)
+				bpi.Locals = api.ConvertVars(locals, !bp.LoadLocals.DisableBuiltinFormatters)
 			}
 		}
 	}
@@ -1107,6 +1153,35 @@ func (d *Debugger) Sources(filter string) ([]string, error) {
 	return files, nil
 }
 
+// SourcesInfo returns the list of source files matching filter, with the
+// size and checksum recorded in the debug information for each of them.
+func (d *Debugger) SourcesInfo(filter string) ([]api.SourceFile, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	regex, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter argument: %s", err.Error())
+	}
+
+	bi := d.target.BinInfo()
+	files := []api.SourceFile{}
+	for _, f := range bi.Sources {
+		if !regex.Match([]byte(f)) {
+			continue
+		}
+		file := api.SourceFile{Path: f}
+		if entry := bi.SourceFileEntry(f); entry != nil {
+			file.Size = entry.Length
+			if entry.MD5 != nil {
+				file.MD5 = hex.EncodeToString(entry.MD5)
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
 // Functions returns a list of functions in the target process.
 func (d *Debugger) Functions(filter string) ([]string, error) {
 	d.targetMutex.Lock()
@@ -1196,6 +1271,16 @@ func (d *Debugger) ThreadRegisters(threadID int, floatingPoint bool) (*op.DwarfR
 	return &dregs, nil
 }
 
+// evalScope returns the EvalScope for the given goroutine, frame and
+// deferred call. When the executable was opened for inspection the scope
+// can only be used to evaluate package variables, constants and types.
+func (d *Debugger) evalScope(goid, frame, deferredCall int) (*proc.EvalScope, error) {
+	if d.config.Inspect {
+		return proc.PackageScope(d.target), nil
+	}
+	return proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+}
+
 // ScopeRegisters returns registers for the specified scope.
 func (d *Debugger) ScopeRegisters(goid, frame, deferredCall int, floatingPoint bool) (*op.DwarfRegisters, error) {
 	d.targetMutex.Lock()
@@ -1243,7 +1328,7 @@ func (d *Debugger) EvalVariableInScope(goid, frame, deferredCall int, symbol str
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
-	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	s, err := d.evalScope(goid, frame, deferredCall)
 	if err != nil {
 		return nil, err
 	}
@@ -1270,6 +1355,91 @@ func (d *Debugger) Goroutines(start, count int) ([]*proc.G, int, error) {
 	return proc.GoroutinesInfo(d.target, start, count)
 }
 
+// FreezeGoroutine suspends goroutine gid, it will not be executed when the
+// target is resumed until it is released by ThawGoroutine.
+// Only supported on the native linux backend.
+func (d *Debugger) FreezeGoroutine(gid int) error {
+	if d.config.Inspect {
+		return core.ErrInspectOnly
+	}
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.FreezeGoroutine(gid)
+}
+
+// ThawGoroutine releases a goroutine suspended by FreezeGoroutine.
+func (d *Debugger) ThawGoroutine(gid int) error {
+	if d.config.Inspect {
+		return core.ErrInspectOnly
+	}
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.ThawGoroutine(gid)
+}
+
+// ConvertGoroutines converts gs to api.Goroutines, the conversion reads
+// the memory of the target and is done while holding its lock.
+func (d *Debugger) ConvertGoroutines(gs []*proc.G) []*api.Goroutine {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return api.ConvertTargetGoroutines(d.target, gs)
+}
+
+// GoroutineProfile returns a gzip compressed pprof profile containing the
+// stacks of all goroutines.
+func (d *Debugger) GoroutineProfile() ([]byte, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	p, err := proc.GoroutineProfile(d.target)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CPUProfile profiles the target for the specified duration by stopping
+// it rate times per second and returns a gzip compressed pprof profile.
+// A Halt command ends profiling early.
+func (d *Debugger) CPUProfile(duration time.Duration, rate int) ([]byte, *proc.CPUProfileStats, error) {
+	if d.config.Inspect {
+		return nil, nil, core.ErrInspectOnly
+	}
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	d.setRunning(true)
+	defer d.setRunning(false)
+
+	interrupt := make(chan struct{})
+	d.profileMutex.Lock()
+	d.stopProfile = interrupt
+	d.profileMutex.Unlock()
+	defer func() {
+		d.profileMutex.Lock()
+		d.stopProfile = nil
+		d.profileMutex.Unlock()
+	}()
+
+	p, stats, err := proc.CPUProfile(d.target, proc.CPUProfileConfig{Duration: duration, Rate: rate, Interrupt: interrupt})
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), stats, nil
+}
+
 // Stacktrace returns a list of Stackframes for the given goroutine. The
 // length of the returned list will be min(stack_len, depth).
 // If 'full' is true, then local vars, function args, etc will be returned as well.
@@ -1338,13 +1508,13 @@ func (d *Debugger) Ancestors(goroutineID, numAncestors, depth int) ([]api.Ancest
 // ConvertStacktrace converts a slice of proc.Stackframe into a slice of
 // api.Stackframe, loading local variables and arguments of each frame if
 // cfg is not nil.
-func (d *Debugger) ConvertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadConfig) ([]api.Stackframe, error) {
+func (d *Debugger) ConvertStacktrace(rawlocs []proc.Stackframe, cfg *api.LoadConfig) ([]api.Stackframe, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 	return d.convertStacktrace(rawlocs, cfg)
 }
 
-func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadConfig) ([]api.Stackframe, error) {
+func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *api.LoadConfig) ([]api.Stackframe, error) {
 	locations := make([]api.Stackframe, 0, len(rawlocs))
 	for i := range rawlocs {
 		frame := api.Stackframe{
@@ -1355,7 +1525,8 @@ func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadCo
 
 			Defers: d.convertDefers(rawlocs[i].Defers),
 
-			Bottom: rawlocs[i].Bottom,
+			Bottom:  rawlocs[i].Bottom,
+			Inlined: rawlocs[i].Inlined,
 		}
 		if rawlocs[i].Err != nil {
 			frame.Err = rawlocs[i].Err.Error()
@@ -1363,17 +1534,17 @@ func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadCo
 		if cfg != nil && rawlocs[i].Current.Fn != nil {
 			var err error
 			scope := proc.FrameToScope(d.target.BinInfo(), d.target.CurrentThread(), nil, rawlocs[i:]...)
-			locals, err := scope.LocalVariables(*cfg)
+			locals, err := scope.LocalVariables(*api.LoadConfigToProc(cfg))
 			if err != nil {
 				return nil, err
 			}
-			arguments, err := scope.FunctionArguments(*cfg)
+			arguments, err := scope.FunctionArguments(*api.LoadConfigToProc(cfg))
 			if err != nil {
 				return nil, err
 			}
 
-			frame.Locals = api.ConvertVars(locals)
-			frame.Arguments = api.ConvertVars(arguments)
+			frame.Locals = api.ConvertVars(locals, !cfg.DisableBuiltinFormatters)
+			frame.Arguments = api.ConvertVars(arguments, !cfg.DisableBuiltinFormatters)
 		}
 		locations = append(locations, frame)
 	}
@@ -1445,7 +1616,7 @@ func (d *Debugger) FindLocation(goid, frame, deferredCall int, locStr string, in
 		return nil, err
 	}
 
-	s, _ := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	s, _ := d.evalScope(goid, frame, deferredCall)
 
 	locs, err := loc.Find(d.target, d.processArgs, s, locStr, includeNonExecutableLines)
 	for i := range locs {
@@ -1555,7 +1726,9 @@ func (d *Debugger) ExamineMemory(address uint64, length int) ([]byte, error) {
 }
 
 func (d *Debugger) GetVersion(out *api.GetVersionOut) error {
-	if d.config.CoreFile != "" {
+	if d.config.Inspect {
+		out.Backend = "inspect"
+	} else if d.config.CoreFile != "" {
 		if d.config.Backend == "rr" {
 			out.Backend = "rr"
 		} else {
@@ -1611,6 +1784,105 @@ func (d *Debugger) StopReason() proc.StopReason {
 	return d.target.StopReason
 }
 
+// DumpStart starts writing a core dump of the target to dest. The dump is
+// written in the background, use DumpWait to wait for it to finish and
+// DumpCancel to interrupt it.
+// The target mutex is held by the goroutine writing the dump until the
+// dump finishes, DumpStart returns once it has been acquired.
+func (d *Debugger) DumpStart(dest string) error {
+	if d.config.Inspect {
+		return core.ErrInspectOnly
+	}
+	state := &core.DumpState{Dumping: true, DoneChan: make(chan struct{})}
+	started := make(chan error)
+
+	go func() {
+		d.targetMutex.Lock()
+		defer d.targetMutex.Unlock()
+
+		if _, err := d.target.Valid(); err != nil {
+			started <- err
+			return
+		}
+		fh, err := os.Create(dest)
+		if err != nil {
+			started <- err
+			return
+		}
+
+		d.dumpMutex.Lock()
+		d.dumpState = state
+		d.dumpMutex.Unlock()
+		started <- nil
+
+		err = core.Dump(d.target, fh, state)
+		if cerr := fh.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dest)
+		}
+		state.Mutex.Lock()
+		state.Dumping = false
+		state.AllDone = true
+		state.Err = err
+		close(state.DoneChan)
+		state.Mutex.Unlock()
+	}()
+
+	return <-started
+}
+
+// DumpWait waits for the dump started by DumpStart to finish, or for wait
+// to expire, and returns the state of the dump. If wait is negative it
+// waits until the dump is finished.
+func (d *Debugger) DumpWait(wait time.Duration) *api.DumpState {
+	d.dumpMutex.Lock()
+	state := d.dumpState
+	d.dumpMutex.Unlock()
+	if state == nil {
+		return &api.DumpState{}
+	}
+
+	var timeout <-chan time.Time
+	if wait >= 0 {
+		timeout = time.After(wait)
+	}
+	select {
+	case <-state.DoneChan:
+	case <-timeout:
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+	r := &api.DumpState{
+		Dumping:      state.Dumping,
+		AllDone:      state.AllDone,
+		ThreadsDone:  state.ThreadsDone,
+		ThreadsTotal: state.ThreadsTotal,
+		MemDone:      state.MemDone,
+		MemTotal:     state.MemTotal,
+	}
+	if state.Err != nil {
+		r.Err = state.Err.Error()
+	}
+	return r
+}
+
+// DumpCancel cancels the dump started by DumpStart.
+func (d *Debugger) DumpCancel() error {
+	d.dumpMutex.Lock()
+	state := d.dumpState
+	d.dumpMutex.Unlock()
+	if state == nil {
+		return errors.New("no dump in progress")
+	}
+	state.Mutex.Lock()
+	state.Canceled = true
+	state.Mutex.Unlock()
+	return nil
+}
+
 // LockTarget acquires the target mutex.
 func (d *Debugger) LockTarget() {
 	d.targetMutex.Lock()
@@ -1621,6 +1893,12 @@ func (d *Debugger) UnlockTarget() {
 	d.targetMutex.Unlock()
 }
 
+// Target returns the current target, the caller must hold the target mutex
+// (see LockTarget) while using it.
+func (d *Debugger) Target() *proc.Target {
+	return d.target
+}
+
 func go11DecodeErrorCheck(err error) error {
 	if _, isdecodeerr := err.(dwarf.DecodeError); !isdecodeerr {
 		return err
@@ -1645,3 +1923,21 @@ func (v breakpointsByLogicalID) Less(i, j int) bool {
 	}
 	return v[i].LogicalID < v[j].LogicalID
 }
+
+// FetchSource returns the contents of the source file at path, downloaded
+// from the debuginfod servers.
+func (d *Debugger) FetchSource(path string) ([]byte, error) {
+	d.targetMutex.Lock()
+	fetch, err := d.target.BinInfo().SourceFetcher()
+	d.targetMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	// The download can take a long time, it is done without holding the
+	// target mutex.
+	local, err := fetch(path)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(local)
+}
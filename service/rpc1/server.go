@@ -97,7 +97,7 @@ func (s *RPCServer) StacktraceGoroutine(args *StacktraceGoroutineArgs, locations
 	if err != nil {
 		return err
 	}
-	*locations, err = s.debugger.ConvertStacktrace(locs, loadcfg)
+	*locations, err = s.debugger.ConvertStacktrace(locs, api.LoadConfigFromProc(loadcfg))
 	return err
 }
 
@@ -176,7 +176,7 @@ func (s *RPCServer) ListPackageVars(filter string, variables *[]api.Variable) er
 	if err != nil {
 		return err
 	}
-	*variables = api.ConvertVars(vars)
+	*variables = api.ConvertVars(vars, true)
 	return nil
 }
 
@@ -190,7 +190,7 @@ func (s *RPCServer) ListThreadPackageVars(args *ThreadListArgs, variables *[]api
 	if err != nil {
 		return err
 	}
-	*variables = api.ConvertVars(vars)
+	*variables = api.ConvertVars(vars, true)
 	return nil
 }
 
@@ -214,7 +214,7 @@ func (s *RPCServer) ListLocalVars(scope api.EvalScope, variables *[]api.Variable
 	if err != nil {
 		return err
 	}
-	*variables = api.ConvertVars(vars)
+	*variables = api.ConvertVars(vars, true)
 	return nil
 }
 
@@ -223,7 +223,7 @@ func (s *RPCServer) ListFunctionArgs(scope api.EvalScope, variables *[]api.Varia
 	if err != nil {
 		return err
 	}
-	*variables = api.ConvertVars(vars)
+	*variables = api.ConvertVars(vars, true)
 	return nil
 }
 
@@ -237,7 +237,7 @@ func (s *RPCServer) EvalSymbol(args EvalSymbolArgs, variable *api.Variable) erro
 	if err != nil {
 		return err
 	}
-	*variable = *api.ConvertVar(v)
+	*variable = *api.ConvertVar(v, true)
 	return nil
 }
 
@@ -284,9 +284,7 @@ func (s *RPCServer) ListGoroutines(arg interface{}, goroutines *[]*api.Goroutine
 	if err != nil {
 		return err
 	}
-	s.debugger.LockTarget()
-	s.debugger.UnlockTarget()
-	*goroutines = api.ConvertGoroutines(gs)
+	*goroutines = s.debugger.ConvertGoroutines(gs)
 	return nil
 }
 
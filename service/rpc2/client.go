@@ -286,10 +286,16 @@ func (c *RPCClient) SetVariable(scope api.EvalScope, symbol, value string) error
 
 func (c *RPCClient) ListSources(filter string) ([]string, error) {
 	sources := new(ListSourcesOut)
-	err := c.call("ListSources", ListSourcesIn{filter}, sources)
+	err := c.call("ListSources", ListSourcesIn{Filter: filter}, sources)
 	return sources.Sources, err
 }
 
+func (c *RPCClient) ListSourcesInfo(filter string) ([]api.SourceFile, error) {
+	sources := new(ListSourcesOut)
+	err := c.call("ListSources", ListSourcesIn{Filter: filter, Info: true}, sources)
+	return sources.Info, err
+}
+
 func (c *RPCClient) ListFunctions(filter string) ([]string, error) {
 	funcs := new(ListFunctionsOut)
 	err := c.call("ListFunctions", ListFunctionsIn{filter}, funcs)
@@ -338,6 +344,29 @@ func (c *RPCClient) ListGoroutines(start, count int) ([]*api.Goroutine, int, err
 	return out.Goroutines, out.Nextg, err
 }
 
+func (c *RPCClient) GoroutineProfile() ([]byte, error) {
+	var out GoroutineProfileOut
+	err := c.call("GoroutineProfile", GoroutineProfileIn{}, &out)
+	return out.Profile, err
+}
+
+// FreezeGoroutine suspends goroutine gid.
+func (c *RPCClient) FreezeGoroutine(gid int) error {
+	return c.call("FreezeGoroutine", FreezeGoroutineIn{gid}, &FreezeGoroutineOut{})
+}
+
+// ThawGoroutine releases a goroutine suspended by FreezeGoroutine.
+func (c *RPCClient) ThawGoroutine(gid int) error {
+	return c.call("ThawGoroutine", ThawGoroutineIn{gid}, &ThawGoroutineOut{})
+}
+
+// CPUProfile profiles the target for the specified duration.
+func (c *RPCClient) CPUProfile(duration time.Duration, rate int) (*CPUProfileOut, error) {
+	var out CPUProfileOut
+	err := c.call("CPUProfile", CPUProfileIn{duration, rate}, &out)
+	return &out, err
+}
+
 func (c *RPCClient) Stacktrace(goroutineId, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error) {
 	var out StacktraceOut
 	err := c.call("Stacktrace", StacktraceIn{goroutineId, depth, false, false, opts, cfg}, &out)
@@ -462,3 +491,32 @@ func (c *RPCClient) call(method string, args, reply interface{}) error {
 func (c *RPCClient) CallAPI(method string, args, reply interface{}) error {
 	return c.call(method, args, reply)
 }
+
+// DumpStart starts a core dump to dest.
+func (c *RPCClient) DumpStart(dest string) (api.DumpState, error) {
+	out := &DumpStartOut{}
+	err := c.call("DumpStart", DumpStartIn{Destination: dest}, out)
+	return out.State, err
+}
+
+// DumpWait waits for the core dump to finish, or for the specified number
+// of milliseconds.
+func (c *RPCClient) DumpWait(wait int) (api.DumpState, error) {
+	out := &DumpWaitOut{}
+	err := c.call("DumpWait", DumpWaitIn{Wait: wait}, out)
+	return out.State, err
+}
+
+// DumpCancel cancels a core dump in progress.
+func (c *RPCClient) DumpCancel() error {
+	out := &DumpCancelOut{}
+	return c.call("DumpCancel", DumpCancelIn{}, out)
+}
+
+// FetchSource returns the contents of a source file downloaded from the
+// debuginfod servers.
+func (c *RPCClient) FetchSource(path string) ([]byte, error) {
+	out := &FetchSourceOut{}
+	err := c.call("FetchSource", FetchSourceIn{Path: path}, out)
+	return out.Contents, err
+}
@@ -196,7 +196,7 @@ func (s *RPCServer) Stacktrace(arg StacktraceIn, out *StacktraceOut) error {
 	if err != nil {
 		return err
 	}
-	out.Locations, err = s.debugger.ConvertStacktrace(rawlocs, api.LoadConfigToProc(cfg))
+	out.Locations, err = s.debugger.ConvertStacktrace(rawlocs, cfg)
 	return err
 }
 
@@ -375,7 +375,7 @@ func (s *RPCServer) ListPackageVars(arg ListPackageVarsIn, out *ListPackageVarsO
 	if err != nil {
 		return err
 	}
-	out.Variables = api.ConvertVars(vars)
+	out.Variables = api.ConvertVars(vars, !arg.Cfg.DisableBuiltinFormatters)
 	return nil
 }
 
@@ -399,6 +399,9 @@ func (s *RPCServer) ListRegisters(arg ListRegistersIn, out *ListRegistersOut) er
 		if err != nil {
 			return err
 		}
+		if state.CurrentThread == nil {
+			return errors.New("no current thread")
+		}
 		arg.ThreadID = state.CurrentThread.ID
 	}
 
@@ -434,7 +437,7 @@ func (s *RPCServer) ListLocalVars(arg ListLocalVarsIn, out *ListLocalVarsOut) er
 	if err != nil {
 		return err
 	}
-	out.Variables = api.ConvertVars(vars)
+	out.Variables = api.ConvertVars(vars, !arg.Cfg.DisableBuiltinFormatters)
 	return nil
 }
 
@@ -453,7 +456,7 @@ func (s *RPCServer) ListFunctionArgs(arg ListFunctionArgsIn, out *ListFunctionAr
 	if err != nil {
 		return err
 	}
-	out.Args = api.ConvertVars(vars)
+	out.Args = api.ConvertVars(vars, !arg.Cfg.DisableBuiltinFormatters)
 	return nil
 }
 
@@ -480,7 +483,7 @@ func (s *RPCServer) Eval(arg EvalIn, out *EvalOut) error {
 	if err != nil {
 		return err
 	}
-	out.Variable = api.ConvertVar(v)
+	out.Variable = api.ConvertVar(v, !cfg.DisableBuiltinFormatters)
 	return nil
 }
 
@@ -501,14 +504,35 @@ func (s *RPCServer) Set(arg SetIn, out *SetOut) error {
 
 type ListSourcesIn struct {
 	Filter string
+
+	// Info requests the size and checksum recorded in the debug information
+	// for each source file, returned in ListSourcesOut.Info.
+	Info bool
 }
 
 type ListSourcesOut struct {
 	Sources []string
+
+	Info []api.SourceFile `json:",omitempty"`
 }
 
 // ListSources lists all source files in the process matching filter.
+// If Info is set the size and checksum of each file, as recorded when the
+// target was built, are also returned. They can be compared with the files
+// on disk to detect sources that changed after the target was built.
 func (s *RPCServer) ListSources(arg ListSourcesIn, out *ListSourcesOut) error {
+	if arg.Info {
+		files, err := s.debugger.SourcesInfo(arg.Filter)
+		if err != nil {
+			return err
+		}
+		out.Sources = make([]string, len(files))
+		for i := range files {
+			out.Sources[i] = files[i].Path
+		}
+		out.Info = files
+		return nil
+	}
 	ss, err := s.debugger.Sources(arg.Filter)
 	if err != nil {
 		return err
@@ -574,13 +598,92 @@ func (s *RPCServer) ListGoroutines(arg ListGoroutinesIn, out *ListGoroutinesOut)
 	if err != nil {
 		return err
 	}
-	s.debugger.LockTarget()
-	defer s.debugger.UnlockTarget()
-	out.Goroutines = api.ConvertGoroutines(gs)
+	out.Goroutines = s.debugger.ConvertGoroutines(gs)
 	out.Nextg = nextg
 	return nil
 }
 
+type FreezeGoroutineIn struct {
+	ID int
+}
+
+type FreezeGoroutineOut struct {
+}
+
+// FreezeGoroutine suspends a goroutine, the rest of the target keeps
+// running when it is resumed. The goroutine is released by ThawGoroutine
+// or when the debugger detaches from the target.
+func (s *RPCServer) FreezeGoroutine(arg FreezeGoroutineIn, out *FreezeGoroutineOut) error {
+	return s.debugger.FreezeGoroutine(arg.ID)
+}
+
+type ThawGoroutineIn struct {
+	ID int
+}
+
+type ThawGoroutineOut struct {
+}
+
+// ThawGoroutine releases a goroutine suspended by FreezeGoroutine.
+func (s *RPCServer) ThawGoroutine(arg ThawGoroutineIn, out *ThawGoroutineOut) error {
+	return s.debugger.ThawGoroutine(arg.ID)
+}
+
+type GoroutineProfileIn struct {
+}
+
+type GoroutineProfileOut struct {
+	// Profile is a gzip compressed protocol buffer, in the format read by
+	// 'go tool pprof'.
+	Profile []byte
+}
+
+// GoroutineProfile returns a pprof goroutine profile containing the stacks
+// of all goroutines, each sample is labeled with the pprof labels of its
+// goroutine and with the goroutine's wait reason.
+func (s *RPCServer) GoroutineProfile(arg GoroutineProfileIn, out *GoroutineProfileOut) error {
+	var err error
+	out.Profile, err = s.debugger.GoroutineProfile()
+	return err
+}
+
+type CPUProfileIn struct {
+	// Duration is how long the target will be profiled for.
+	Duration time.Duration
+	// Rate is the number of samples collected every second.
+	Rate int
+}
+
+type CPUProfileOut struct {
+	// Profile is a gzip compressed protocol buffer, in the format read by
+	// 'go tool pprof'.
+	Profile []byte
+
+	Samples int           // number of stacks recorded
+	Stops   int           // number of times the target was stopped
+	Elapsed time.Duration // wall clock duration of the profile
+	Stopped time.Duration // time the target spent stopped by the profiler
+}
+
+// CPUProfile profiles the target by periodically stopping it and
+// recording the stacks of all threads that are executing a goroutine.
+// The target is resumed for the duration of the call, a Halt command ends
+// profiling early.
+func (s *RPCServer) CPUProfile(arg CPUProfileIn, out *CPUProfileOut) error {
+	buf, stats, err := s.debugger.CPUProfile(arg.Duration, arg.Rate)
+	if err != nil {
+		return err
+	}
+	*out = CPUProfileOut{
+		Profile: buf,
+		Samples: stats.Samples,
+		Stops:   stats.Stops,
+		Elapsed: stats.Elapsed,
+		Stopped: stats.Stopped,
+	}
+	return nil
+}
+
 type AttachedToExistingProcessIn struct {
 }
 
@@ -857,3 +960,68 @@ func (s *RPCServer) StopRecording(arg StopRecordingIn, cb service.RPCCallback) {
 	}
 	cb.Return(out, nil)
 }
+
+type DumpStartIn struct {
+	Destination string
+}
+
+type DumpStartOut struct {
+	State api.DumpState
+}
+
+// DumpStart starts a core dump to arg.Destination.
+// The dump is written in the background, use DumpWait to wait for it to
+// finish and DumpCancel to interrupt it. Every request that needs to
+// access the target will block until the dump is finished.
+func (s *RPCServer) DumpStart(arg DumpStartIn, out *DumpStartOut) error {
+	if err := s.debugger.DumpStart(arg.Destination); err != nil {
+		return err
+	}
+	out.State = *s.debugger.DumpWait(0)
+	return nil
+}
+
+type DumpWaitIn struct {
+	// Wait is the maximum number of milliseconds to wait for, if it is
+	// negative DumpWait will wait until the dump is finished.
+	Wait int
+}
+
+type DumpWaitOut struct {
+	State api.DumpState
+}
+
+// DumpWait waits for the core dump to finish, or for arg.Wait
+// milliseconds, and returns its state.
+func (s *RPCServer) DumpWait(arg DumpWaitIn, out *DumpWaitOut) error {
+	out.State = *s.debugger.DumpWait(time.Duration(arg.Wait) * time.Millisecond)
+	return nil
+}
+
+type DumpCancelIn struct {
+}
+
+type DumpCancelOut struct {
+}
+
+// DumpCancel cancels the core dump.
+func (s *RPCServer) DumpCancel(arg DumpCancelIn, out *DumpCancelOut) error {
+	return s.debugger.DumpCancel()
+}
+
+type FetchSourceIn struct {
+	Path string
+}
+
+type FetchSourceOut struct {
+	Contents []byte
+}
+
+// FetchSource returns the contents of the source file at Path, downloaded
+// by the server from the debuginfod servers listed in DEBUGINFOD_URLS,
+// using the build-id of the executable or of one of the loaded libraries.
+func (s *RPCServer) FetchSource(arg FetchSourceIn, out *FetchSourceOut) error {
+	var err error
+	out.Contents, err = s.debugger.FetchSource(arg.Path)
+	return err
+}
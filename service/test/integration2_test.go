@@ -1347,6 +1347,35 @@ func TestEvalExprName(t *testing.T) {
 	})
 }
 
+func TestClientServer_DisableBuiltinFormatters(t *testing.T) {
+	withTestClient2("stdlibtypes", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		d, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "d", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(d)")
+		if d.Pretty != "1.5s" {
+			t.Errorf("d: got %q, expected %q", d.Pretty, "1.5s")
+		}
+
+		cfg := normalLoadConfig
+		cfg.DisableBuiltinFormatters = true
+		d, err = c.EvalVariable(api.EvalScope{GoroutineID: -1}, "d", cfg)
+		assertNoError(err, t, "EvalVariable(d)")
+		if d.Pretty != "" {
+			t.Errorf("d: got %q, expected no formatting", d.Pretty)
+		}
+
+		locals, err := c.ListLocalVariables(api.EvalScope{GoroutineID: -1}, cfg)
+		assertNoError(err, t, "ListLocalVariables()")
+		for _, v := range locals {
+			if v.Pretty != "" {
+				t.Errorf("%s: got %q, expected no formatting", v.Name, v.Pretty)
+			}
+		}
+	})
+}
+
 func TestClientServer_Issue528(t *testing.T) {
 	// FindLocation with Receiver.MethodName syntax does not work
 	// on remote package names due to a bug in debug/gosym that
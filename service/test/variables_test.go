@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go/constant"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"sort"
 	"strings"
@@ -58,7 +59,7 @@ func assertVariable(t *testing.T, variable *proc.Variable, expected varTest) {
 		}
 	}
 
-	cv := api.ConvertVar(variable)
+	cv := api.ConvertVar(variable, true)
 
 	if cv.Type != expected.varType {
 		t.Fatalf("Expected %s got %s (for variable %s)\n", expected.varType, cv.Type, expected.name)
@@ -131,13 +132,13 @@ func withTestProcessArgs(name string, t *testing.T, wd string, args []string, bu
 	var tracedir string
 	switch testBackend {
 	case "native":
-		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, false, []string{}, "", [3]string{})
+		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, false, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, false, []string{}, proc.BinaryInfoCacheConfig{}, "", [3]string{})
 	case "rr":
 		protest.MustHaveRecordingAllowed(t)
 		t.Log("recording")
-		p, tracedir, err = gdbserial.RecordAndReplay(append([]string{fixture.Path}, args...), wd, true, []string{}, [3]string{})
+		p, tracedir, err = gdbserial.RecordAndReplay(append([]string{fixture.Path}, args...), wd, true, []string{}, proc.BinaryInfoCacheConfig{}, [3]string{})
 		t.Logf("replaying %q", tracedir)
 	default:
 		t.Fatalf("unknown backend %q", testBackend)
@@ -210,7 +211,7 @@ func TestVariableEvaluation(t *testing.T) {
 				assertVariable(t, variable, tc)
 			} else {
 				if err == nil {
-					t.Fatalf("Expected error %s, got no error: %s\n", tc.err.Error(), api.ConvertVar(variable).SinglelineString())
+					t.Fatalf("Expected error %s, got no error: %s\n", tc.err.Error(), api.ConvertVar(variable, true).SinglelineString())
 				}
 				if tc.err.Error() != err.Error() {
 					t.Fatalf("Unexpected error. Expected %s got %s", tc.err.Error(), err.Error())
@@ -255,6 +256,8 @@ func TestSetVariable(t *testing.T) {
 
 		{"s3", "[]int", `[]int len: 0, cap: 6, []`, "s4[2:5]", "[]int len: 3, cap: 3, [3,4,5]"},
 		{"s3", "[]int", "[]int len: 3, cap: 3, [3,4,5]", "arr1[:]", "[]int len: 4, cap: 4, [0,1,2,3]"},
+
+		{`m1["Adenauer"]`, "main.astruct", "main.astruct {A: 0, B: 0}", "s2[1]", "main.astruct {A: 3, B: 4}"},
 	}
 
 	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
@@ -337,7 +340,7 @@ func TestVariableEvaluationShort(t *testing.T) {
 				assertVariable(t, variable, tc)
 			} else {
 				if err == nil {
-					t.Fatalf("Expected error %s, got no error: %s\n", tc.err.Error(), api.ConvertVar(variable).SinglelineString())
+					t.Fatalf("Expected error %s, got no error: %s\n", tc.err.Error(), api.ConvertVar(variable, true).SinglelineString())
 				}
 				if tc.err.Error() != err.Error() {
 					t.Fatalf("Unexpected error. Expected %s got %s", tc.err.Error(), err.Error())
@@ -389,7 +392,7 @@ func TestMultilineVariableEvaluation(t *testing.T) {
 		for _, tc := range testcases {
 			variable, err := evalVariable(p, tc.name, pnormalLoadConfig)
 			assertNoError(err, t, "EvalVariable() returned an error")
-			if ms := api.ConvertVar(variable).MultilineString(""); !matchStringOrPrefix(ms, tc.value) {
+			if ms := api.ConvertVar(variable, true).MultilineString(""); !matchStringOrPrefix(ms, tc.value) {
 				t.Fatalf("Expected %s got %s (variable %s)\n", tc.value, ms, variable.Name)
 			}
 		}
@@ -542,7 +545,7 @@ func TestComplexSetting(t *testing.T) {
 			assertNoError(setVariable(p, "c128", setExpr), t, "SetVariable()")
 			variable, err := evalVariable(p, "c128", pnormalLoadConfig)
 			assertNoError(err, t, "EvalVariable()")
-			if s := api.ConvertVar(variable).SinglelineString(); s != value {
+			if s := api.ConvertVar(variable, true).SinglelineString(); s != value {
 				t.Fatalf("Wrong value of c128: \"%s\", expected \"%s\" after setting it to \"%s\"", s, value, setExpr)
 			}
 		}
@@ -745,6 +748,8 @@ func TestEvalExpression(t *testing.T) {
 		{"*(i2 + i3)", false, "", "", "", fmt.Errorf("expression \"(i2 + i3)\" (int) can not be dereferenced")},
 		{"i2.member", false, "", "", "", fmt.Errorf("i2 (type int) is not a struct")},
 		{"fmt.Println(\"hello\")", false, "", "", "", fmt.Errorf("function calls not allowed without using 'call'")},
+		{"ifacearr[0].Error()", false, "", "", "", fmt.Errorf("function calls not allowed without using 'call'")},
+		{"[]int{1, 2}", false, "", "", "", fmt.Errorf("literal can not be allocated because function calls are not allowed without using 'call'")},
 		{"*nil", false, "", "", "", fmt.Errorf("nil can not be dereferenced")},
 		{"!nil", false, "", "", "", fmt.Errorf("operator ! can not be applied to \"nil\"")},
 		{"&nil", false, "", "", "", fmt.Errorf("can not take address of \"nil\"")},
@@ -865,7 +870,7 @@ func TestEvalAddrAndCast(t *testing.T) {
 		assertNoError(p.Continue(), t, "Continue() returned an error")
 		c1addr, err := evalVariable(p, "&c1", pnormalLoadConfig)
 		assertNoError(err, t, "EvalExpression(&c1)")
-		c1addrstr := api.ConvertVar(c1addr).SinglelineString()
+		c1addrstr := api.ConvertVar(c1addr, true).SinglelineString()
 		t.Logf("&c1 → %s", c1addrstr)
 		if !strings.HasPrefix(c1addrstr, "(*main.cstruct)(0x") {
 			t.Fatalf("Invalid value of EvalExpression(&c1) \"%s\"", c1addrstr)
@@ -873,7 +878,7 @@ func TestEvalAddrAndCast(t *testing.T) {
 
 		aaddr, err := evalVariable(p, "&(c1.pb.a)", pnormalLoadConfig)
 		assertNoError(err, t, "EvalExpression(&(c1.pb.a))")
-		aaddrstr := api.ConvertVar(aaddr).SinglelineString()
+		aaddrstr := api.ConvertVar(aaddr, true).SinglelineString()
 		t.Logf("&(c1.pb.a) → %s", aaddrstr)
 		if !strings.HasPrefix(aaddrstr, "(*main.astruct)(0x") {
 			t.Fatalf("invalid value of EvalExpression(&(c1.pb.a)) \"%s\"", aaddrstr)
@@ -881,7 +886,7 @@ func TestEvalAddrAndCast(t *testing.T) {
 
 		a, err := evalVariable(p, "*"+aaddrstr, pnormalLoadConfig)
 		assertNoError(err, t, fmt.Sprintf("EvalExpression(*%s)", aaddrstr))
-		t.Logf("*%s → %s", aaddrstr, api.ConvertVar(a).SinglelineString())
+		t.Logf("*%s → %s", aaddrstr, api.ConvertVar(a, true).SinglelineString())
 		assertVariable(t, a, varTest{aaddrstr, false, "main.astruct {A: 1, B: 2}", "", "main.astruct", nil})
 	})
 }
@@ -892,7 +897,7 @@ func TestMapEvaluation(t *testing.T) {
 		assertNoError(p.Continue(), t, "Continue() returned an error")
 		m1v, err := evalVariable(p, "m1", pnormalLoadConfig)
 		assertNoError(err, t, "EvalVariable()")
-		m1 := api.ConvertVar(m1v)
+		m1 := api.ConvertVar(m1v, true)
 		t.Logf("m1 = %v", m1.MultilineString(""))
 
 		if m1.Type != "map[string]main.astruct" {
@@ -920,7 +925,7 @@ func TestMapEvaluation(t *testing.T) {
 		}
 
 		found := countMalone(m1)
-		found += countMalone(api.ConvertVar(m1sliced))
+		found += countMalone(api.ConvertVar(m1sliced, true))
 
 		if found != 1 {
 			t.Fatalf("Could not find Malone exactly 1 time: found %d", found)
@@ -934,7 +939,7 @@ func TestUnsafePointer(t *testing.T) {
 		assertNoError(p.Continue(), t, "Continue() returned an error")
 		up1v, err := evalVariable(p, "up1", pnormalLoadConfig)
 		assertNoError(err, t, "EvalVariable(up1)")
-		up1 := api.ConvertVar(up1v)
+		up1 := api.ConvertVar(up1v, true)
 		if ss := up1.SinglelineString(); !strings.HasPrefix(ss, "unsafe.Pointer(") {
 			t.Fatalf("wrong value for up1: %s", ss)
 		}
@@ -1137,7 +1142,7 @@ func TestIssue1075(t *testing.T) {
 			vars, err := scope.LocalVariables(pnormalLoadConfig)
 			assertNoError(err, t, fmt.Sprintf("LocalVariables (%d)", i))
 			for _, v := range vars {
-				api.ConvertVar(v).SinglelineString()
+				api.ConvertVar(v, true).SinglelineString()
 			}
 		}
 	})
@@ -1240,6 +1245,44 @@ func TestCallFunction(t *testing.T) {
 		{`strings.LastIndexByte(stringslice[1], 'o')`, []string{":int:2"}, nil},
 		{`d.Base.Method()`, []string{`:int:4`}, nil},
 		{`d.Method()`, []string{`:int:4`}, nil},
+
+		// Composite literals
+		{`stringsJoin([]string{"a", "b"}, "-")`, []string{`:string:"a-b"`}, nil},
+		{`stringsJoin([]string{2: "c", "d"}, ",")`, []string{`:string:",,c,d"`}, nil},
+		{`sumAStructs([]main.astruct{{X: 1}, {2}, main.astruct{X: 3}})`, []string{`:int:6`}, nil},
+		{`sumMap(map[string]int{"a": 1, "b": 2})`, []string{`:int:3`}, nil},
+		{`getAStruct(main.astruct{X: 4}.X).VRcvr(1)`, []string{`:string:"1 + 4 = 5"`}, nil},
+		{`sumAStructs([]main.astruct{{Y: 1}})`, nil, errors.New(`error evaluating "[]main.astruct{{Y: 1}}" as argument v in function main.sumAStructs: unknown field Y in struct literal of type main.astruct`)},
+		{`str = []string{"x", "y"}[1]; str`, []string{`str:string:"y"`}, nil},
+
+		// Variadic functions
+		{`variadicSum(1, 2, 3)`, []string{`:int:6`}, nil},
+		{`variadicSum(4)`, []string{`:int:4`}, nil},
+		{`variadicSum()`, []string{`:int:0`}, nil},
+		{`variadicSum(intslice...)`, []string{`:int:6`}, nil},
+		{`variadicJoin(comma, "a", "b", "c")`, []string{`:string:"a,b,c"`}, nil},
+		{`variadicJoin(comma, stringslice...)`, []string{`:string:"one,two,three"`}, nil},
+		{`variadicApply(square, 1, 2, 3)`, []string{`:int:14`}, nil},
+		{`sumAStructs()`, nil, errors.New("not enough arguments")},
+		{`call1(one, two...)`, nil, errors.New("can not use ... in call to main.call1")},
+
+		// Functions and method values as arguments
+		{`applyInt(square, 5)`, []string{`:int:25`}, nil},
+		{`applyInt(call1, 5)`, nil, errors.New("can not convert value of type func(int, int) int to func(int) int")},
+		{`applyString(fn2clos, 5)`, []string{`:string:"3 + 6 + 5 = 14"`}, nil},
+		{`applyString(pa.VRcvr, 5)`, []string{`:string:"5 + 6 = 11"`}, nil},
+		{`applyString(a.VRcvr, 7)`, []string{`:string:"7 + 3 = 10"`}, nil},
+
+		// Assignments
+		{`acopy = getAStruct(5); acopy`, []string{`acopy:main.astruct:main.astruct {X: 5}`}, nil},
+		{`anyval = 5; anyval`, []string{`anyval:interface {}:interface {}(int) 5`}, nil},
+		{`anyval = "hello"; anyval`, []string{`anyval:interface {}:interface {}(string) "hello"`}, nil},
+		{`anyval = a; anyval`, []string{`anyval:interface {}:interface {}(main.astruct) {X: 3}`}, nil},
+		{`vable = main.astruct{X: 9}; vable`, []string{`vable:main.VRcvrable:main.VRcvrable(main.astruct) {X: 9}`}, nil},
+		{`vable = one`, nil, errors.New("int does not implement main.VRcvrable")},
+		{`strintmap["one"] = 3; strintmap["one"]`, []string{`:int:3`}, nil},
+		{`strintmap["two"] = 2; strintmap["two"]`, []string{`:int:2`}, nil},
+		{`intslice = []int{4, 5}; intslice`, []string{`intslice:[]int:[]int len: 2, cap: 2, [4,5]`}, nil},
 	}
 
 	var testcases113 = []testCaseCallFunction{
@@ -1249,13 +1292,19 @@ func TestCallFunction(t *testing.T) {
 
 		{`getAStruct(3).VRcvr(1)`, []string{`:string:"1 + 3 = 4"`}, nil}, // direct call of a method with value receiver / on a value
 
-		{`getAStruct(3).PRcvr(2)`, nil, errors.New("cannot use getAStruct(3).PRcvr as argument pa in function main.(*astruct).PRcvr: stack object passed to escaping pointer: pa")}, // direct call of a method with pointer receiver / on a value
+		{`getAStruct(3).PRcvr(2)`, []string{`:string:"2 - 3 = -1"`}, nil},    // direct call of a method with pointer receiver / on a value
 		{`getAStructPtr(6).VRcvr(3)`, []string{`:string:"3 + 6 = 9"`}, nil},  // direct call of a method with value receiver / on a pointer
 		{`getAStructPtr(6).PRcvr(4)`, []string{`:string:"4 - 6 = -2"`}, nil}, // direct call of a method with pointer receiver / on a pointer
 
 		{`getVRcvrableFromAStruct(3).VRcvr(6)`, []string{`:string:"6 + 3 = 9"`}, nil},     // indirect call of method on interface / containing value with value method
 		{`getPRcvrableFromAStructPtr(6).PRcvr(7)`, []string{`:string:"7 - 6 = 1"`}, nil},  // indirect call of method on interface / containing pointer with value method
 		{`getVRcvrableFromAStructPtr(6).VRcvr(5)`, []string{`:string:"5 + 6 = 11"`}, nil}, // indirect call of method on interface / containing pointer with pointer method
+
+		// Dynamic calls through the method table of an interface
+
+		{`err.Error()`, []string{`:string:"some error"`}, nil},
+		{`rdr.Read(buf)`, []string{`n:int:5`, `err:error:error nil`}, nil},
+		{`nilerr.Error()`, nil, errors.New("nil pointer dereference")},
 	}
 
 	var testcasesBefore114After112 = []testCaseCallFunction{
@@ -1342,7 +1391,7 @@ func testCallFunction(t *testing.T, p *proc.Target, tc testCaseCallFunction) {
 	retvals := make([]*api.Variable, len(retvalsVar))
 
 	for i := range retvals {
-		retvals[i] = api.ConvertVar(retvalsVar[i])
+		retvals[i] = api.ConvertVar(retvalsVar[i], true)
 	}
 
 	if varExpr != "" {
@@ -1350,7 +1399,7 @@ func testCallFunction(t *testing.T, p *proc.Target, tc testCaseCallFunction) {
 		assertNoError(err, t, "GoroutineScope")
 		v, err := scope.EvalExpression(varExpr, pnormalLoadConfig)
 		assertNoError(err, t, fmt.Sprintf("EvalExpression(%s)", varExpr))
-		retvals = append(retvals, api.ConvertVar(v))
+		retvals = append(retvals, api.ConvertVar(v, true))
 	}
 
 	for i := range retvals {
@@ -1408,13 +1457,13 @@ func TestIssue1531(t *testing.T) {
 
 		mv, err := evalVariable(p, "m", pnormalLoadConfig)
 		assertNoError(err, t, "EvalVariable(m)")
-		cmv := api.ConvertVar(mv)
+		cmv := api.ConvertVar(mv, true)
 		t.Logf("m = %s", cmv.SinglelineString())
 		hasKeys(mv, "s", "r", "v")
 
 		mmv, err := evalVariable(p, "mm", pnormalLoadConfig)
 		assertNoError(err, t, "EvalVariable(mm)")
-		cmmv := api.ConvertVar(mmv)
+		cmmv := api.ConvertVar(mmv, true)
 		t.Logf("mm = %s", cmmv.SinglelineString())
 		hasKeys(mmv, "r", "t", "v")
 	})
@@ -1562,3 +1611,78 @@ func TestCgoEval(t *testing.T) {
 		}
 	})
 }
+
+func TestBuiltinFormatters(t *testing.T) {
+	testcases := []struct {
+		expr   string
+		pretty string
+	}{
+		{"tm", "2020-09-13T12:26:40.0000005Z UTC"},
+		{"tmfixed", "2020-09-13T14:26:40+02:00 CEST"},
+		{"d", "1.5s"},
+		{"ip4", "192.168.1.1"},
+		{"ip6", "2001:db8::1"},
+		{"bi", "-123456789012345678901234567890"},
+		{"*bi", "-123456789012345678901234567890"},
+		{"bf", "1.5"},
+		{"mu", "unlocked"},
+		{"lockedmu", "locked"},
+		{"av", "(main.astruct) {A: 1, B: 2}"},
+		{"avnil", "nil"},
+		{"rv", "(main.astruct) {A: 3, B: 4}"},
+		{"rvint", "(int) 5"},
+		{"rvptr", "(*main.astruct) *{A: 5, B: 6}"},
+		{"rvrv", "(reflect.Value) (int) 7"},
+		{"rvzero", "<invalid reflect.Value>"},
+		{"tm.wall", ""},
+	}
+
+	// the zone cached by time.Location is two levels below time.Time
+	cfg := pnormalLoadConfig
+	cfg.MaxVariableRecurse = 2
+
+	protest.AllowRecording(t)
+	withTestProcess("stdlibtypes", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue() returned an error")
+		for _, tc := range testcases {
+			variable, err := evalVariable(p, tc.expr, cfg)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", tc.expr))
+			if pretty := api.ConvertVar(variable, true).Pretty; pretty != tc.pretty {
+				t.Errorf("%s: got %q, expected %q", tc.expr, pretty, tc.pretty)
+			}
+		}
+	})
+}
+
+func TestReflectValue(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("stdlibtypes", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue() returned an error")
+
+		rv, err := evalVariable(p, "rv", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable(rv)")
+		data := rv.ReflectValue()
+		if data == nil {
+			t.Fatal("value of rv not loaded")
+		}
+		assertNoError(data.Unreadable, t, "value of rv")
+		assertVariable(t, data, varTest{value: "main.astruct {A: 3, B: 4}", varType: "main.astruct"})
+
+		rvint, err := evalVariable(p, "rvint", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable(rvint)")
+		assertVariable(t, rvint.ReflectValue(), varTest{value: "5", varType: "int"})
+
+		rvzero, err := evalVariable(p, "rvzero", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable(rvzero)")
+		if data := rvzero.ReflectValue(); data == nil || data.Kind != reflect.Invalid {
+			t.Errorf("value of rvzero: got %v, expected invalid", data)
+		}
+
+		// the value is loaded like a field of the reflect.Value
+		rv, err = evalVariable(p, "rv", pshortLoadConfig)
+		assertNoError(err, t, "EvalVariable(rv)")
+		if data := rv.ReflectValue(); data == nil || len(data.Children) != 0 {
+			t.Errorf("value of rv loaded with pshortLoadConfig: got %v, expected no fields", data)
+		}
+	})
+}